@@ -13,6 +13,7 @@ import (
 	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/auth"
 	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/database"
 	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/handlers"
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/scheduler"
 	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/scraper"
 	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/services"
 	"github.com/golang-migrate/migrate/v4"
@@ -43,15 +44,63 @@ func main() {
 			auth.NewJWTService,
 			services.NewUserService,
 			services.NewJobService,
+			services.NewExchangeRateService,
+			services.NewScrapeRunService,
+			services.NewActorRunService,
+			services.NewCursorService,
+			services.NewScrapeSourceService,
+			services.NewCircuitService,
+			services.NewQuarantineService,
+			services.NewCrewAvailabilityService,
+			services.NewPostSightingService,
 			handlers.NewAuthHandler,
 			handlers.NewJobHandler,
+			handlers.NewScrapeRunHandler,
+			handlers.NewSchedulerHandler,
+			handlers.NewApifyWebhookHandler,
+			handlers.NewCursorHandler,
+			handlers.NewScrapeSourceHandler,
+			handlers.NewExchangeRateHandler,
+			handlers.NewRulesHandler,
+			handlers.NewCircuitHandler,
+			handlers.NewQuarantineHandler,
+			handlers.NewCrewHandler,
+			scraper.NewApifyConfig,
+			scraper.NewApifyClient,
+			scraper.NewRulesConfig,
+			scraper.NewRuleEngine,
+			scraper.NewHTMLCrawlConfig,
+			scraper.NewFetcherConfig,
+			scraper.NewFetcher,
+			scraper.NewRunClaims,
 			scraper.NewYachtScraperService,
+			scraper.NewRegistryConfig,
+			scraper.NewCircuitConfig,
+			scraper.NewEmailConfig,
+			scraper.NewSMTPServer,
+			scraper.NewSpamConfig,
+			scraper.NewSpamClassifier,
+			fx.Annotate(scraper.NewRegistry, fx.ParamTags(``, `group:"sources"`)),
+			scraper.NewScraperService,
+			scheduler.NewConfig,
+			scheduler.New,
 			NewEcho,
 		),
+		// Register scraper sources
+		fx.Provide(
+			scraper.AsSource(scraper.NewFacebookSource),
+			scraper.AsSource(scraper.NewTelegramSource),
+			scraper.AsSource(scraper.NewHTMLSource),
+			scraper.AsSource(scraper.NewFeedSource),
+			scraper.AsSource(scraper.NewEmailSource),
+		),
 		// Register lifecycle hooks
 		fx.Invoke(RunMigrations),
 		fx.Invoke(SetupRoutes),
 		fx.Invoke(StartScraper),
+		fx.Invoke(ResumeActorRuns),
+		fx.Invoke(WatchRules),
+		fx.Invoke(ServeSMTP),
 	).Run()
 }
 
@@ -161,6 +210,16 @@ func SetupRoutes(
 	e *echo.Echo,
 	authHandler *handlers.AuthHandler,
 	jobHandler *handlers.JobHandler,
+	scrapeRunHandler *handlers.ScrapeRunHandler,
+	schedulerHandler *handlers.SchedulerHandler,
+	apifyWebhookHandler *handlers.ApifyWebhookHandler,
+	cursorHandler *handlers.CursorHandler,
+	scrapeSourceHandler *handlers.ScrapeSourceHandler,
+	exchangeRateHandler *handlers.ExchangeRateHandler,
+	rulesHandler *handlers.RulesHandler,
+	circuitHandler *handlers.CircuitHandler,
+	quarantineHandler *handlers.QuarantineHandler,
+	crewHandler *handlers.CrewHandler,
 	jwtService *auth.JWTService,
 	db *database.DB,
 ) {
@@ -194,17 +253,52 @@ func SetupRoutes(
 			api.GET("/jobs", jobHandler.GetJobs)
 			api.GET("/jobs/:id", jobHandler.GetJobByID)
 
+			// Webhook callbacks, authenticated by their shared secret
+			api.POST("/webhooks/apify", apifyWebhookHandler.HandleRunFinished)
+
 			// Protected routes
 			protected := api.Group("")
 			protected.Use(auth.JWTMiddleware(jwtService))
 			protected.GET("/auth/profile", authHandler.GetProfile)
 			protected.PUT("/auth/profile", authHandler.UpdateProfile)
 
+			// Employer and agency routes
+			candidates := api.Group("/candidates")
+			candidates.Use(auth.JWTMiddleware(jwtService))
+			candidates.Use(auth.RequireRole("employer", "agency", "admin"))
+			candidates.GET("", crewHandler.ListPosts)
+			candidates.GET("/:id", crewHandler.GetPost)
+
 			// Admin routes
 			admin := api.Group("/admin")
 			admin.Use(auth.JWTMiddleware(jwtService))
 			admin.Use(auth.RequireRole("admin"))
 			admin.POST("/jobs", jobHandler.CreateJob)
+			admin.PUT("/users/:id/role", authHandler.UpdateUserRole)
+			admin.GET("/scrape-runs", scrapeRunHandler.ListScrapeRuns)
+			admin.GET("/scrape-runs/:id", scrapeRunHandler.GetScrapeRun)
+			admin.GET("/scheduler", schedulerHandler.GetSchedule)
+			admin.POST("/scheduler/:name/run", schedulerHandler.TriggerTask)
+			admin.GET("/cursors", cursorHandler.ListCursors)
+			admin.POST("/cursors/reset", cursorHandler.ResetCursor)
+			admin.GET("/sources", scrapeSourceHandler.ListSources)
+			admin.POST("/sources", scrapeSourceHandler.CreateSource)
+			admin.GET("/sources/:id", scrapeSourceHandler.GetSource)
+			admin.PUT("/sources/:id", scrapeSourceHandler.UpdateSource)
+			admin.DELETE("/sources/:id", scrapeSourceHandler.DeleteSource)
+			admin.GET("/exchange-rates", exchangeRateHandler.ListRates)
+			admin.PUT("/exchange-rates", exchangeRateHandler.UpdateRates)
+			admin.GET("/rules", rulesHandler.GetRules)
+			admin.PUT("/rules", rulesHandler.UpdateRules)
+			admin.POST("/rules/validate", rulesHandler.ValidateRules)
+			admin.GET("/circuits", circuitHandler.ListCircuits)
+			admin.POST("/circuits/:source/reset", circuitHandler.ResetCircuit)
+			admin.GET("/quarantine", quarantineHandler.ListPosts)
+			admin.GET("/quarantine/:id", quarantineHandler.GetPost)
+			admin.POST("/quarantine/:id/approve", quarantineHandler.ApprovePost)
+			admin.POST("/quarantine/:id/reject", quarantineHandler.RejectPost)
+			admin.GET("/spam-model", quarantineHandler.GetModel)
+			admin.POST("/spam-model/train", quarantineHandler.TrainModel)
 
 			// Serve static frontend files at root path (must be after API routes)
 			e.Static("/", "../frontend/build")
@@ -235,18 +329,88 @@ func SetupRoutes(
 	})
 }
 
-// StartScraper starts the yacht job scraping service
-func StartScraper(lc fx.Lifecycle, scraperService *scraper.YachtScraperService) {
+// StartScraper schedules every enabled scraper source to run on its configured schedule
+func StartScraper(
+	lc fx.Lifecycle,
+	sched *scheduler.Scheduler,
+	scraperService *scraper.ScraperService,
+	registry *scraper.Registry,
+) error {
 	scraperEnabled, _ := strconv.ParseBool(os.Getenv("SCRAPER_ENABLED"))
 	if !scraperEnabled {
 		log.Println("Scraper is disabled by environment variable.")
-		return
+		return nil
+	}
+
+	for _, source := range registry.Enabled() {
+		name := source.Name()
+		err := sched.Add(name, "", func(ctx context.Context) error {
+			return scraperService.ScrapeSource(ctx, name)
+		})
+		if err != nil {
+			return err
+		}
 	}
 
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
-			go scraperService.ScrapeYachtJobs()
+			sched.Start()
 			return nil
 		},
+		OnStop: func(ctx context.Context) error {
+			return sched.Stop(ctx)
+		},
 	})
-} 
\ No newline at end of file
+	return nil
+}
+
+// ResumeActorRuns collects Apify runs that were started but never collected,
+// e.g. because the server restarted while they were running
+func ResumeActorRuns(lc fx.Lifecycle, scraperService *scraper.ScraperService) {
+	scraperEnabled, _ := strconv.ParseBool(os.Getenv("SCRAPER_ENABLED"))
+	if !scraperEnabled {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go scraperService.ResumePendingRuns(ctx)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
+
+// WatchRules reloads the extraction rules file whenever it changes
+func WatchRules(lc fx.Lifecycle, rules *scraper.RuleEngine) {
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go rules.Watch(ctx)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
+
+// ServeSMTP runs the SMTP listener receiving vacancy emails, when configured
+func ServeSMTP(lc fx.Lifecycle, server *scraper.SMTPServer) {
+	if !server.Enabled() {
+		return
+	}
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			return server.Start()
+		},
+		OnStop: func(ctx context.Context) error {
+			return server.Stop(ctx)
+		},
+	})
+}
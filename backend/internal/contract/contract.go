@@ -0,0 +1,298 @@
+// Package contract parses free-text contract terms such as "10/10 rotation",
+// "6 month seasonal contract, summer Med" or "permanent" into structured values.
+package contract
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Contract kinds
+const (
+	KindPermanent  = "permanent"
+	KindSeasonal   = "seasonal"
+	KindRotational = "rotational"
+	KindTemporary  = "temporary"
+	KindDelivery   = "delivery"
+)
+
+// Seasons
+const (
+	SeasonSummerMed       = "summer_med"
+	SeasonWinterCaribbean = "winter_caribbean"
+	SeasonSummer          = "summer"
+	SeasonWinter          = "winter"
+)
+
+// Kinds lists every contract kind
+var Kinds = []string{KindPermanent, KindSeasonal, KindRotational, KindTemporary, KindDelivery}
+
+// Seasons lists every season
+var Seasons = []string{SeasonSummerMed, SeasonWinterCaribbean, SeasonSummer, SeasonWinter}
+
+// Contract holds parsed contract terms. Zero values mean the term was not stated.
+type Contract struct {
+	Kind        string
+	RotationOn  int     // Weeks on
+	RotationOff int     // Weeks off
+	Months      float64 // Contract length
+	Season      string
+}
+
+// HasRotation reports whether a rotation pattern was found
+func (c *Contract) HasRotation() bool {
+	return c.RotationOn > 0 && c.RotationOff > 0
+}
+
+// String describes the contract, e.g. "Rotational 10/10" or "Seasonal, summer Med, 6 months"
+func (c *Contract) String() string {
+	var parts []string
+
+	if c.Kind != "" {
+		kind := strings.ToUpper(c.Kind[:1]) + c.Kind[1:]
+		if c.HasRotation() {
+			kind += fmt.Sprintf(" %d/%d", c.RotationOn, c.RotationOff)
+		}
+		parts = append(parts, kind)
+	}
+	if label, ok := seasonLabels[c.Season]; ok {
+		parts = append(parts, label)
+	}
+	if c.Months > 0 {
+		months := strconv.FormatFloat(c.Months, 'f', -1, 64)
+		if c.Months == 1 {
+			parts = append(parts, months+" month")
+		} else {
+			parts = append(parts, months+" months")
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+var seasonLabels = map[string]string{
+	SeasonSummerMed:       "summer Med",
+	SeasonWinterCaribbean: "winter Caribbean",
+	SeasonSummer:          "summer season",
+	SeasonWinter:          "winter season",
+}
+
+var (
+	// rotationOnOffRegex matches "10 weeks on 10 weeks off", "10 on / 10 off"
+	// and "2 months on 2 off"
+	rotationOnOffRegex = regexp.MustCompile(`(?i)\b(\d{1,2})\s*(weeks?|wks?|months?|mths?)?\s*on\s*[,/&-]?\s*(?:and\s+)?(\d{1,2})\s*(weeks?|wks?|months?|mths?)?\s*off\b`)
+
+	// rotationRatioRegex matches "4/4" or "4:4" ratios, which are only
+	// read as rotations when a rotation keyword or week unit is nearby
+	rotationRatioRegex = regexp.MustCompile(`(?i)\b(\d{1,2})\s*[/:]\s*(\d{1,2})\b(\s*(?:weeks?|wks?))?`)
+
+	rotationKeywordRegex = regexp.MustCompile(`(?i)\brotat(?:ion|ional|ing)?\b|\brot\b`)
+
+	// rotationValueRegex matches a bare "on/off" filter value such as "10/10"
+	rotationValueRegex = regexp.MustCompile(`^\s*(\d{1,2})\s*[/:]\s*(\d{1,2})\s*$`)
+
+	// lengthRegex matches contract lengths such as "6 months", "3-4 month" or "1 year"
+	lengthRegex = regexp.MustCompile(`(?i)\b(\d{1,2}(?:\.\d)?)(?:\s*(?:-|to)\s*(\d{1,2}))?\s*(?:-\s*)?(months?|mths?|mos?|weeks?|wks?|years?|yrs?)\b`)
+
+	// lengthExclusionRegex matches what follows figures that are not contract lengths
+	lengthExclusionRegex = regexp.MustCompile(`(?i)^\s*(?:of\s+)?(?:on\b|off\b|experience|exp\b|probation|trial|notice|ago|old|minimum experience|in the industry|sea ?time)`)
+
+	// lengthContextRegex matches what precedes figures that are not contract lengths
+	lengthContextRegex = regexp.MustCompile(`(?i)(?:experience|exp|minimum|min\.?|at least|probation|trial|after|notice|within|over|previous|past)\s*(?:of\s*)?$`)
+
+	kindRegexes = []struct {
+		kind  string
+		regex *regexp.Regexp
+	}{
+		{KindPermanent, regexp.MustCompile(`(?i)\bpermanent\b|\bperm\b|\bfull[- ]time\b|\blong[- ]term\b`)},
+		{KindRotational, regexp.MustCompile(`(?i)\brotation(?:al)?\b|\brotating\b`)},
+		{KindDelivery, regexp.MustCompile(`(?i)\bdelivery\b|\bdeliveries\b|\b(?:atlantic|pacific|ocean)\s+crossing\b|\btransatlantic\b|\bpassage\b|\brepositioning\b`)},
+		{KindSeasonal, regexp.MustCompile(`(?i)\bseasonal\b|\bseason\b`)},
+		{KindTemporary, regexp.MustCompile(`(?i)\btemporary\b|\btemp\b|\brelief\b|\bday ?work(?:er)?\b|\bfreelance\b|\bshort[- ]term\b|\bcover\b|\bfill[- ]in\b`)},
+	}
+
+	seasonRegexes = []struct {
+		season string
+		regex  *regexp.Regexp
+	}{
+		{SeasonSummerMed, regexp.MustCompile(`(?i)\bsummer\s+(?:season\s+)?(?:in\s+(?:the\s+)?)?med(?:iterranean)?\b|\bmed(?:iterranean)?\s+(?:summer\s+)?season\b|\bmed(?:iterranean)?\s+summer\b`)},
+		{SeasonWinterCaribbean, regexp.MustCompile(`(?i)\bwinter\s+(?:season\s+)?(?:in\s+(?:the\s+)?)?(?:caribbean|carib|caribs|bahamas)\b|\b(?:caribbean|carib|bahamas)\s+(?:winter\s+)?season\b|\b(?:caribbean|bahamas)\s+winter\b`)},
+		{SeasonSummer, regexp.MustCompile(`(?i)\bsummer\s+season\b|\bsummer\s+20\d\d\b|\bthis\s+summer\b|\bfor\s+the\s+summer\b`)},
+		{SeasonWinter, regexp.MustCompile(`(?i)\bwinter\s+season\b|\bwinter\s+20\d\d\b|\bthis\s+winter\b|\bfor\s+the\s+winter\b`)},
+	}
+)
+
+// rotationWindow is how far from a ratio a rotation keyword is searched
+const rotationWindow = 25
+
+// Parse returns the contract terms mentioned in text, or nil if there are none
+func Parse(text string) *Contract {
+	c := &Contract{}
+
+	c.RotationOn, c.RotationOff = parseRotation(text)
+	c.Months = parseMonths(text)
+
+	for _, s := range seasonRegexes {
+		if s.regex.MatchString(text) {
+			c.Season = s.season
+			break
+		}
+	}
+
+	switch {
+	case c.HasRotation():
+		c.Kind = KindRotational
+	default:
+		c.Kind = firstKind(text)
+		if c.Kind == "" && c.Season != "" {
+			c.Kind = KindSeasonal
+		}
+	}
+
+	if c.Kind == "" && c.Months == 0 {
+		return nil
+	}
+	return c
+}
+
+// IsKind reports whether kind is one of the Kind constants
+func IsKind(kind string) bool {
+	return contains(Kinds, kind)
+}
+
+// IsSeason reports whether season is one of the Season constants
+func IsSeason(season string) bool {
+	return contains(Seasons, season)
+}
+
+// ParseRotation parses a rotation pattern written as "on/off", e.g. "10/10"
+func ParseRotation(value string) (on, off int, ok bool) {
+	match := rotationValueRegex.FindStringSubmatch(value)
+	if match == nil {
+		return 0, 0, false
+	}
+	on, _ = strconv.Atoi(match[1])
+	off, _ = strconv.Atoi(match[2])
+	return on, off, on > 0 && off > 0
+}
+
+// parseRotation returns the weeks on and off of the first rotation in text
+func parseRotation(text string) (int, int) {
+	if match := rotationOnOffRegex.FindStringSubmatch(text); match != nil {
+		on, _ := strconv.Atoi(match[1])
+		off, _ := strconv.Atoi(match[3])
+
+		// A unit on either side applies to both, e.g. "2 on 2 months off"
+		unit := match[2]
+		if unit == "" {
+			unit = match[4]
+		}
+		if isMonths(unit) {
+			on, off = monthsToWeeks(on), monthsToWeeks(off)
+		}
+		if on > 0 && off > 0 {
+			return on, off
+		}
+	}
+
+	for _, indexes := range rotationRatioRegex.FindAllStringSubmatchIndex(text, -1) {
+		start, end := indexes[0], indexes[1]
+		on, _ := strconv.Atoi(text[indexes[2]:indexes[3]])
+		off, _ := strconv.Atoi(text[indexes[4]:indexes[5]])
+		if on == 0 || off == 0 || on > 26 || off > 26 {
+			continue
+		}
+
+		hasWeeks := indexes[6] >= 0 && indexes[6] < indexes[7]
+		nearby := window(text, start-rotationWindow, end+rotationWindow)
+		if hasWeeks || rotationKeywordRegex.MatchString(nearby) {
+			return on, off
+		}
+	}
+	return 0, 0
+}
+
+// parseMonths returns the contract length in months, using the lower bound of ranges
+func parseMonths(text string) float64 {
+	for _, indexes := range lengthRegex.FindAllStringSubmatchIndex(text, -1) {
+		start, end := indexes[0], indexes[1]
+		if lengthExclusionRegex.MatchString(text[end:]) || lengthContextRegex.MatchString(window(text, start-20, start)) {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(text[indexes[2]:indexes[3]], 64)
+		if err != nil || value == 0 {
+			continue
+		}
+
+		unit := strings.ToLower(text[indexes[6]:indexes[7]])
+		switch {
+		case isMonths(unit):
+			return value
+		case strings.HasPrefix(unit, "w"):
+			return math.Round(value*12/52*10) / 10
+		case strings.HasPrefix(unit, "y"):
+			return value * 12
+		}
+	}
+	return 0
+}
+
+// firstKind returns the contract kind mentioned earliest in text
+func firstKind(text string) string {
+	type match struct {
+		kind  string
+		index int
+	}
+
+	var matches []match
+	for _, k := range kindRegexes {
+		if loc := k.regex.FindStringIndex(text); loc != nil {
+			matches = append(matches, match{k.kind, loc[0]})
+		}
+	}
+	if len(matches) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].index < matches[j].index
+	})
+	return matches[0].kind
+}
+
+func isMonths(unit string) bool {
+	unit = strings.ToLower(unit)
+	return strings.HasPrefix(unit, "mo") || strings.HasPrefix(unit, "mth")
+}
+
+// monthsToWeeks converts a rotation given in months into weeks
+func monthsToWeeks(months int) int {
+	return int(math.Round(float64(months) * 52 / 12))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// window returns text[start:end] clamped to the text
+func window(text string, start, end int) string {
+	if start < 0 {
+		start = 0
+	}
+	if end > len(text) {
+		end = len(text)
+	}
+	if start >= end {
+		return ""
+	}
+	return text[start:end]
+}
@@ -0,0 +1,392 @@
+// Package geo normalizes free-text job locations against an embedded
+// gazetteer of yachting ports, marinas, countries and cruising regions.
+package geo
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+//go:embed gazetteer.json
+var gazetteerJSON []byte
+
+// Region is a cruising region such as the Mediterranean
+type Region struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases"`
+}
+
+// Country is a country with the region its ports belong to by default
+type Country struct {
+	Code          string   `json:"code"` // ISO 3166-1 alpha-2
+	Name          string   `json:"name"`
+	Region        string   `json:"region"`
+	Aliases       []string `json:"aliases"`
+	StrictAliases []string `json:"strict_aliases"` // Matched case-sensitively, e.g. "UK"
+}
+
+// Place is a port, marina or cruising area with coordinates
+type Place struct {
+	Name          string   `json:"name"`
+	Country       string   `json:"country"`
+	Region        string   `json:"region"` // Overrides the country region when set
+	Within        string   `json:"within"` // Name of the enclosing area, e.g. "French Riviera"
+	Lat           float64  `json:"lat"`
+	Lon           float64  `json:"lon"`
+	Aliases       []string `json:"aliases"`
+	StrictAliases []string `json:"strict_aliases"` // Matched case-sensitively, e.g. "Nice"
+}
+
+// Location is a normalized location. Coordinates are only known for places.
+type Location struct {
+	Port        string
+	Country     string // ISO 3166-1 alpha-2
+	CountryName string
+	Region      string // Region ID
+	RegionName  string
+	Lat         *float64
+	Lon         *float64
+}
+
+// String returns the display name, e.g. "Antibes, France", "France" or "Mediterranean"
+func (l *Location) String() string {
+	switch {
+	case l.Port != "" && l.CountryName != "" && l.Port != l.CountryName:
+		return l.Port + ", " + l.CountryName
+	case l.Port != "":
+		return l.Port
+	case l.CountryName != "":
+		return l.CountryName
+	}
+	return l.RegionName
+}
+
+// Match levels, more specific matches win
+const (
+	levelRegion = iota + 1
+	levelCountry
+	levelPlace
+)
+
+type matcher struct {
+	regex    *regexp.Regexp
+	level    int
+	strict   bool
+	location Location
+}
+
+// Gazetteer resolves place names into locations
+type Gazetteer struct {
+	regions  []Region
+	matchers []matcher
+	names    map[string]Location
+	children map[string][]string // Places directly within each area
+}
+
+var (
+	// foldReplacer strips the accents commonly found in place names
+	foldReplacer = strings.NewReplacer(
+		"á", "a", "à", "a", "â", "a", "ä", "a", "ã", "a", "å", "a",
+		"é", "e", "è", "e", "ê", "e", "ë", "e",
+		"í", "i", "ì", "i", "î", "i", "ï", "i",
+		"ó", "o", "ò", "o", "ô", "o", "ö", "o", "õ", "o",
+		"ú", "u", "ù", "u", "û", "u", "ü", "u",
+		"ç", "c", "ñ", "n", "’", "'",
+		"Á", "A", "É", "E", "Í", "I", "Ó", "O", "Ö", "O", "Ú", "U", "Ü", "U", "Ç", "C", "Ñ", "N",
+	)
+
+	tokenRegex = regexp.MustCompile(`[A-Za-z0-9]+`)
+
+	// exclusionRegex matches what follows names that describe people or
+	// paperwork rather than where the job is, e.g. "UK passport"
+	exclusionRegex = regexp.MustCompile(`(?i)^\W{0,2}(?:passports?|(?:b1\s*/?\s*b2\s+)?visas?|flag(?:ged)?|citizens?|citizenship|nationals?|nationality|licen[cs]es?|based company)\b`)
+
+	// sentenceStartRegex and standaloneRegex tell a capitalized word such as
+	// "Nice" opening a sentence apart from the place, which stands alone or
+	// is followed by a comma
+	sentenceStartRegex = regexp.MustCompile(`(?:^|[.!?\n]\s*)$`)
+	standaloneRegex    = regexp.MustCompile(`^\s*(?:$|[,;:)/|\n-])`)
+
+	defaultGazetteer = mustLoad(gazetteerJSON)
+)
+
+// Load builds a gazetteer from its JSON definition
+func Load(data []byte) (*Gazetteer, error) {
+	var definition struct {
+		Regions   []Region  `json:"regions"`
+		Countries []Country `json:"countries"`
+		Places    []Place   `json:"places"`
+	}
+	if err := json.Unmarshal(data, &definition); err != nil {
+		return nil, fmt.Errorf("invalid gazetteer: %w", err)
+	}
+
+	g := &Gazetteer{
+		regions:  definition.Regions,
+		names:    make(map[string]Location),
+		children: make(map[string][]string),
+	}
+
+	regions := make(map[string]Location)
+	for _, region := range definition.Regions {
+		regions[region.ID] = Location{Region: region.ID, RegionName: region.Name}
+	}
+
+	places := make(map[string]bool)
+	for _, place := range definition.Places {
+		places[place.Name] = true
+	}
+	for _, place := range definition.Places {
+		if place.Within == "" {
+			continue
+		}
+		if !places[place.Within] {
+			return nil, fmt.Errorf("place %s: unknown area %q", place.Name, place.Within)
+		}
+		g.children[place.Within] = append(g.children[place.Within], place.Name)
+	}
+
+	countries := make(map[string]Location)
+	for _, country := range definition.Countries {
+		location, ok := regions[country.Region]
+		if !ok {
+			return nil, fmt.Errorf("country %s: unknown region %q", country.Code, country.Region)
+		}
+		location.Country = country.Code
+		location.CountryName = country.Name
+		countries[country.Code] = location
+	}
+
+	// Broader locations are added last so that Lookup prefers them when a
+	// name is shared, e.g. the country and the port of Monaco
+	for _, place := range definition.Places {
+		location, ok := countries[place.Country]
+		if !ok {
+			return nil, fmt.Errorf("place %s: unknown country %q", place.Name, place.Country)
+		}
+		if place.Region != "" {
+			region, ok := regions[place.Region]
+			if !ok {
+				return nil, fmt.Errorf("place %s: unknown region %q", place.Name, place.Region)
+			}
+			location.Region, location.RegionName = region.Region, region.RegionName
+		}
+		lat, lon := place.Lat, place.Lon
+		location.Port, location.Lat, location.Lon = place.Name, &lat, &lon
+
+		g.add(levelPlace, location, append([]string{place.Name}, place.Aliases...), place.StrictAliases)
+	}
+	for _, country := range definition.Countries {
+		g.add(levelCountry, countries[country.Code], append([]string{country.Name}, country.Aliases...), country.StrictAliases)
+		g.names[strings.ToLower(country.Code)] = countries[country.Code]
+	}
+	for _, region := range definition.Regions {
+		g.add(levelRegion, regions[region.ID], append([]string{region.Name}, region.Aliases...), nil)
+		g.names[region.ID] = regions[region.ID]
+	}
+
+	return g, nil
+}
+
+func mustLoad(data []byte) *Gazetteer {
+	g, err := Load(data)
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
+// add registers the names of a location, strict names being case-sensitive.
+// A name that is also listed as strict is only matched case-sensitively.
+func (g *Gazetteer) add(level int, location Location, names, strictNames []string) {
+	for i, name := range append(names, strictNames...) {
+		tokens := tokenRegex.FindAllString(foldReplacer.Replace(name), -1)
+		if len(tokens) == 0 {
+			continue
+		}
+		g.names[normalizeName(tokens)] = location
+		if i < len(names) && containsFold(strictNames, name) {
+			continue
+		}
+
+		quoted := make([]string, len(tokens))
+		for j, token := range tokens {
+			quoted[j] = regexp.QuoteMeta(token)
+		}
+		pattern := `\b` + strings.Join(quoted, `[\s.\-']*`) + `\b`
+		if i < len(names) {
+			pattern = `(?i)` + pattern
+		}
+
+		g.matchers = append(g.matchers, matcher{
+			regex:    regexp.MustCompile(pattern),
+			level:    level,
+			strict:   i >= len(names),
+			location: location,
+		})
+	}
+}
+
+// Resolve returns the most specific location mentioned in text, preferring
+// earlier and then longer mentions, or nil if there is none
+func (g *Gazetteer) Resolve(text string) *Location {
+	text = foldReplacer.Replace(text)
+
+	var best *matcher
+	bestStart, bestLength := 0, 0
+	for i := range g.matchers {
+		m := &g.matchers[i]
+		if best != nil && m.level < best.level {
+			continue
+		}
+
+		for _, loc := range m.regex.FindAllStringIndex(text, -1) {
+			if exclusionRegex.MatchString(text[loc[1]:]) {
+				continue
+			}
+			if m.strict && sentenceStartRegex.MatchString(text[:loc[0]]) && !standaloneRegex.MatchString(text[loc[1]:]) {
+				continue
+			}
+
+			start, length := loc[0], loc[1]-loc[0]
+			better := best == nil || m.level > best.level ||
+				start < bestStart || (start == bestStart && length > bestLength)
+			if better {
+				best, bestStart, bestLength = m, start, length
+			}
+			break
+		}
+	}
+
+	if best == nil {
+		return nil
+	}
+	location := best.location
+	return &location
+}
+
+// Lookup returns the location a whole name refers to, such as "Ft Lauderdale",
+// "France", "FR", "Caribbean" or "caribbean", or nil if it is unknown. Names
+// shared by several locations resolve to the broadest one.
+func (g *Gazetteer) Lookup(name string) *Location {
+	tokens := tokenRegex.FindAllString(foldReplacer.Replace(name), -1)
+	if location, ok := g.names[normalizeName(tokens)]; ok {
+		return &location
+	}
+	if location, ok := g.names[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return &location
+	}
+	return nil
+}
+
+// Regions returns every region in the gazetteer
+func (g *Gazetteer) Regions() []Region {
+	return g.regions
+}
+
+// PortsWithin returns a place and every place within it, e.g. "Mallorca"
+// and "Palma" for "Mallorca"
+func (g *Gazetteer) PortsWithin(port string) []string {
+	ports := []string{port}
+	for _, child := range g.children[port] {
+		ports = append(ports, g.PortsWithin(child)...)
+	}
+	return ports
+}
+
+// Resolve returns the most specific location mentioned in text using the
+// embedded gazetteer
+func Resolve(text string) *Location {
+	return defaultGazetteer.Resolve(text)
+}
+
+// Lookup returns the location a whole name refers to using the embedded gazetteer
+func Lookup(name string) *Location {
+	return defaultGazetteer.Lookup(name)
+}
+
+// PortsWithin returns a place and every place within it using the embedded gazetteer
+func PortsWithin(port string) []string {
+	return defaultGazetteer.PortsWithin(port)
+}
+
+// Regions returns every region in the embedded gazetteer
+func Regions() []Region {
+	return defaultGazetteer.Regions()
+}
+
+func normalizeName(tokens []string) string {
+	return strings.ToLower(strings.Join(tokens, " "))
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// earthRadiusKm is the mean radius of the Earth
+const earthRadiusKm = 6371.0
+
+// DistanceKm returns the great-circle distance between two points
+func DistanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// Box is a latitude/longitude bounding box. MinLon is greater than MaxLon
+// when the box crosses the antimeridian.
+type Box struct {
+	MinLat, MaxLat float64
+	MinLon, MaxLon float64
+	AllLon         bool // The box spans every longitude, e.g. near a pole
+}
+
+// BoundingBox returns a box containing every point within radiusKm of a point
+func BoundingBox(lat, lon, radiusKm float64) Box {
+	dLat := radiusKm / earthRadiusKm * 180 / math.Pi
+	box := Box{
+		MinLat: math.Max(-90, lat-dLat),
+		MaxLat: math.Min(90, lat+dLat),
+	}
+
+	// Longitude degrees shrink towards the poles; past a pole every
+	// longitude is in range
+	if box.MinLat == -90 || box.MaxLat == 90 {
+		box.AllLon = true
+		return box
+	}
+	dLon := math.Asin(math.Min(1, math.Sin(radiusKm/earthRadiusKm)/math.Cos(lat*math.Pi/180))) * 180 / math.Pi
+	if dLon >= 180 {
+		box.AllLon = true
+		return box
+	}
+
+	box.MinLon = normalizeLongitude(lon - dLon)
+	box.MaxLon = normalizeLongitude(lon + dLon)
+	return box
+}
+
+// normalizeLongitude wraps a longitude into [-180, 180]
+func normalizeLongitude(lon float64) float64 {
+	for lon < -180 {
+		lon += 360
+	}
+	for lon > 180 {
+		lon -= 360
+	}
+	return lon
+}
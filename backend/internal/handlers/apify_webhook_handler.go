@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"log"
+	"net/http"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/scraper"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/fx"
+)
+
+// apifyWebhookPayload is the subset of the default Apify webhook payload the handler uses
+type apifyWebhookPayload struct {
+	EventType string `json:"eventType"`
+	EventData struct {
+		ActorRunID string `json:"actorRunId"`
+	} `json:"eventData"`
+	Resource struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+	} `json:"resource"`
+}
+
+type ApifyWebhookHandler struct {
+	ctx            context.Context // Cancelled when the app stops
+	config         scraper.ApifyConfig
+	yachtService   *scraper.YachtScraperService
+	scraperService *scraper.ScraperService
+}
+
+func NewApifyWebhookHandler(
+	lc fx.Lifecycle,
+	config scraper.ApifyConfig,
+	yachtService *scraper.YachtScraperService,
+	scraperService *scraper.ScraperService,
+) *ApifyWebhookHandler {
+	// Runs collected in the background stop with the app
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+
+	return &ApifyWebhookHandler{
+		ctx:            ctx,
+		config:         config,
+		yachtService:   yachtService,
+		scraperService: scraperService,
+	}
+}
+
+// HandleRunFinished receives Apify run completion callbacks. Runs that a fetch
+// is still waiting for are handed to it; any other tracked run is collected
+// in the background.
+func (h *ApifyWebhookHandler) HandleRunFinished(c echo.Context) error {
+	if !h.config.WebhooksEnabled() {
+		return echo.NewHTTPError(http.StatusNotFound, "webhooks are not enabled")
+	}
+
+	secret := c.Request().Header.Get(scraper.ApifyWebhookSecretHeader)
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(h.config.WebhookSecret)) != 1 {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid webhook secret")
+	}
+
+	var payload apifyWebhookPayload
+	if err := c.Bind(&payload); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	runID := payload.EventData.ActorRunID
+	if runID == "" {
+		runID = payload.Resource.ID
+	}
+	if runID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "actor run ID is required")
+	}
+
+	log.Printf("Apify webhook %s for run %s (%s)", payload.EventType, runID, payload.Resource.Status)
+
+	if h.yachtService.CompleteRun(runID) {
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"message": "run completed",
+			"run_id":  runID,
+		})
+	}
+
+	// Nothing is waiting for the run, e.g. it was started before a restart
+	go func() {
+		if err := h.scraperService.ResumeRun(h.ctx, runID); err != nil {
+			log.Printf("Error resuming actor run %s: %v", runID, err)
+		}
+	}()
+
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"message": "run collection started",
+		"run_id":  runID,
+	})
+}
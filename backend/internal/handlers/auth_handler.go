@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
 
 	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/models"
@@ -109,4 +111,30 @@ func (h *AuthHandler) UpdateProfile(c echo.Context) error {
 		"message": "profile updated successfully",
 		"user":    user,
 	})
-} 
\ No newline at end of file
+} 
+
+// UpdateUserRole changes the role of a user, e.g. to let an employer browse crew
+func (h *AuthHandler) UpdateUserRole(c echo.Context) error {
+	userID := c.Param("id")
+
+	var req models.UpdateUserRoleRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if !models.IsRole(req.Role) {
+		return echo.NewHTTPError(http.StatusBadRequest, "role must be one of user, employer, agency or admin")
+	}
+
+	if err := h.userService.UpdateUserRole(userID, req.Role); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return echo.NewHTTPError(http.StatusNotFound, "user not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to update role")
+	}
+
+	user, err := h.userService.GetUserByID(userID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to retrieve updated user")
+	}
+	return c.JSON(http.StatusOK, user)
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/scraper"
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+type CircuitHandler struct {
+	circuitService *services.CircuitService
+	config         scraper.CircuitConfig
+}
+
+func NewCircuitHandler(circuitService *services.CircuitService, config scraper.CircuitConfig) *CircuitHandler {
+	return &CircuitHandler{
+		circuitService: circuitService,
+		config:         config,
+	}
+}
+
+// ListCircuits returns the circuit breaker of every source that has run
+func (h *CircuitHandler) ListCircuits(c echo.Context) error {
+	circuits, err := h.circuitService.ListCircuits()
+	if err != nil {
+		log.Printf("Error listing source circuits: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]interface{}{
+			"message": "Failed to retrieve source circuits",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"threshold": h.config.Threshold,
+		"circuits":  circuits,
+	})
+}
+
+// ResetCircuit closes the circuit breaker of a source so it is scraped again
+func (h *CircuitHandler) ResetCircuit(c echo.Context) error {
+	source := c.Param("source")
+
+	circuit, err := h.circuitService.Reset(source)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return echo.NewHTTPError(http.StatusNotFound, "source circuit not found")
+		}
+		log.Printf("Error resetting circuit of %s: %v", source, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]interface{}{
+			"message": "Failed to reset source circuit",
+			"error":   err.Error(),
+		})
+	}
+
+	log.Printf("Circuit breaker of %s reset", source)
+	return c.JSON(http.StatusOK, circuit)
+}
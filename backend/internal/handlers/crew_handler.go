@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/models"
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+type CrewHandler struct {
+	crewService *services.CrewAvailabilityService
+}
+
+func NewCrewHandler(crewService *services.CrewAvailabilityService) *CrewHandler {
+	return &CrewHandler{
+		crewService: crewService,
+	}
+}
+
+// ListPosts handles listing the posts where crew advertise their availability
+func (h *CrewHandler) ListPosts(c echo.Context) error {
+	filter := models.CrewAvailabilityFilter{
+		Position:      c.QueryParam("position"),
+		Certification: c.QueryParam("certification"),
+		Region:        c.QueryParam("region"),
+		Country:       c.QueryParam("country"),
+		Language:      c.QueryParam("language"),
+	}
+	if availableStr := c.QueryParam("available_by"); availableStr != "" {
+		availableBy, err := time.Parse("2006-01-02", availableStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid available_by parameter, expected YYYY-MM-DD")
+		}
+		filter.AvailableBy = &availableBy
+	}
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			filter.Limit = limit
+		}
+	}
+	if offsetStr := c.QueryParam("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filter.Offset = offset
+		}
+	}
+
+	response, err := h.crewService.ListPosts(filter)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCrewFilter) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		log.Printf("Error listing crew availability posts: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]interface{}{
+			"message": "Failed to retrieve crew availability posts",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetPost handles getting a single crew availability post
+func (h *CrewHandler) GetPost(c echo.Context) error {
+	post, err := h.crewService.GetPost(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return echo.NewHTTPError(http.StatusNotFound, "crew availability post not found")
+		}
+		log.Printf("Error getting crew availability post: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]interface{}{
+			"message": "Failed to retrieve crew availability post",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, post)
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/models"
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+type CursorHandler struct {
+	cursorService *services.CursorService
+}
+
+func NewCursorHandler(cursorService *services.CursorService) *CursorHandler {
+	return &CursorHandler{
+		cursorService: cursorService,
+	}
+}
+
+// ListCursors returns the scrape cursor of every channel, optionally filtered by source
+func (h *CursorHandler) ListCursors(c echo.Context) error {
+	cursors, err := h.cursorService.ListCursors(c.QueryParam("source"))
+	if err != nil {
+		log.Printf("Error listing scrape cursors: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]interface{}{
+			"message": "Failed to retrieve scrape cursors",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"cursors": cursors,
+	})
+}
+
+// ResetCursor clears the cursor of a channel, or of every channel of a
+// source, so the next scrape backfills it
+func (h *CursorHandler) ResetCursor(c echo.Context) error {
+	var req models.ResetCursorRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if req.Source == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "source is required")
+	}
+
+	reset, seenReset, err := h.cursorService.ResetCursors(req.Source, req.Channel)
+	if err != nil {
+		log.Printf("Error resetting scrape cursors: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]interface{}{
+			"message": "Failed to reset scrape cursors",
+			"error":   err.Error(),
+		})
+	}
+	// A channel may have seen posts without a cursor, e.g. after a failed save
+	if reset == 0 && seenReset == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, "scrape cursor not found")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":    "cursor reset",
+		"reset":      reset,
+		"seen_reset": seenReset,
+	})
+}
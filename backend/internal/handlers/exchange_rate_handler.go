@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/models"
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+type ExchangeRateHandler struct {
+	rateService *services.ExchangeRateService
+}
+
+func NewExchangeRateHandler(rateService *services.ExchangeRateService) *ExchangeRateHandler {
+	return &ExchangeRateHandler{
+		rateService: rateService,
+	}
+}
+
+// ListRates returns the exchange rates used by the salary filters
+func (h *ExchangeRateHandler) ListRates(c echo.Context) error {
+	rates, err := h.rateService.ListRates()
+	if err != nil {
+		log.Printf("Error listing exchange rates: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]interface{}{
+			"message": "Failed to retrieve exchange rates",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"base":  services.BaseCurrency,
+		"rates": rates,
+	})
+}
+
+// UpdateRates creates or updates exchange rates
+func (h *ExchangeRateHandler) UpdateRates(c echo.Context) error {
+	var req models.UpdateExchangeRatesRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if len(req.Rates) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "rates are required")
+	}
+
+	rates, err := h.rateService.UpdateRates(req.Rates)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidExchangeRate) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		log.Printf("Error updating exchange rates: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]interface{}{
+			"message": "Failed to update exchange rates",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"base":  services.BaseCurrency,
+		"rates": rates,
+	})
+}
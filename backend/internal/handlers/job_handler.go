@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"database/sql"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
@@ -31,6 +32,55 @@ func (h *JobHandler) GetJobs(c echo.Context) error {
 	filter.Type = c.QueryParam("type")
 	filter.Location = c.QueryParam("location")
 	filter.Company = c.QueryParam("company")
+	filter.Currency = c.QueryParam("currency")
+	filter.Period = c.QueryParam("period")
+	filter.ContractKind = c.QueryParam("contract")
+	filter.Rotation = c.QueryParam("rotation")
+	filter.Season = c.QueryParam("season")
+	filter.Near = c.QueryParam("near")
+	filter.Region = c.QueryParam("region")
+	filter.Language = c.QueryParam("language")
+
+	// Parse salary range
+	if minStr := c.QueryParam("salary_min"); minStr != "" {
+		salaryMin, err := strconv.ParseFloat(minStr, 64)
+		if err != nil || salaryMin < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid salary_min parameter")
+		}
+		filter.SalaryMin = &salaryMin
+	}
+	if maxStr := c.QueryParam("salary_max"); maxStr != "" {
+		salaryMax, err := strconv.ParseFloat(maxStr, 64)
+		if err != nil || salaryMax < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid salary_max parameter")
+		}
+		filter.SalaryMax = &salaryMax
+	}
+
+	// Parse contract length range
+	if minStr := c.QueryParam("months_min"); minStr != "" {
+		monthsMin, err := strconv.ParseFloat(minStr, 64)
+		if err != nil || monthsMin < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid months_min parameter")
+		}
+		filter.MonthsMin = &monthsMin
+	}
+	if maxStr := c.QueryParam("months_max"); maxStr != "" {
+		monthsMax, err := strconv.ParseFloat(maxStr, 64)
+		if err != nil || monthsMax < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid months_max parameter")
+		}
+		filter.MonthsMax = &monthsMax
+	}
+
+	// Parse search radius
+	if radiusStr := c.QueryParam("radius_km"); radiusStr != "" {
+		radius, err := strconv.ParseFloat(radiusStr, 64)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid radius_km parameter")
+		}
+		filter.RadiusKm = &radius
+	}
 
 	// Parse pagination
 	if limitStr := c.QueryParam("limit"); limitStr != "" {
@@ -54,6 +104,9 @@ func (h *JobHandler) GetJobs(c echo.Context) error {
 	response, err := h.jobService.GetJobs(filter)
 	if err != nil {
 		log.Printf("Error getting jobs: %v", err)
+		if errors.Is(err, services.ErrInvalidJobFilter) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
 		// Check if it's a database connection error
 		if err.Error() == "sql: database is closed" {
 			return echo.NewHTTPError(http.StatusServiceUnavailable, "Database connection is not available")
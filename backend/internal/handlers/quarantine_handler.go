@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/models"
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/scraper"
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+type QuarantineHandler struct {
+	quarantineService *services.QuarantineService
+	scraperService    *scraper.ScraperService
+	spam              *scraper.SpamClassifier
+}
+
+func NewQuarantineHandler(
+	quarantineService *services.QuarantineService,
+	scraperService *scraper.ScraperService,
+	spam *scraper.SpamClassifier,
+) *QuarantineHandler {
+	return &QuarantineHandler{
+		quarantineService: quarantineService,
+		scraperService:    scraperService,
+		spam:              spam,
+	}
+}
+
+// TrainSpamRequest is a post to teach the spam model
+type TrainSpamRequest struct {
+	Text string `json:"text"`
+	Spam bool   `json:"spam"`
+}
+
+// ListPosts handles listing the posts held for review
+func (h *QuarantineHandler) ListPosts(c echo.Context) error {
+	filter := models.QuarantinedPostFilter{
+		Status: c.QueryParam("status"),
+		Source: c.QueryParam("source"),
+	}
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			filter.Limit = limit
+		}
+	}
+	if offsetStr := c.QueryParam("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filter.Offset = offset
+		}
+	}
+
+	response, err := h.quarantineService.ListPosts(filter)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidQuarantineFilter) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		log.Printf("Error listing quarantined posts: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]interface{}{
+			"message": "Failed to retrieve quarantined posts",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetPost handles getting a single quarantined post
+func (h *QuarantineHandler) GetPost(c echo.Context) error {
+	post, err := h.quarantineService.GetPost(c.Param("id"))
+	if err != nil {
+		return h.postError("retrieve", err)
+	}
+	return c.JSON(http.StatusOK, post)
+}
+
+// ApprovePost handles releasing a quarantined post, saving its jobs
+func (h *QuarantineHandler) ApprovePost(c echo.Context) error {
+	post, saved, err := h.scraperService.ApproveQuarantined(c.Param("id"), c.Get("user_id").(string))
+	if err != nil {
+		return h.postError("approve", err)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"post":       post,
+		"jobs_saved": saved,
+	})
+}
+
+// RejectPost handles discarding a quarantined post
+func (h *QuarantineHandler) RejectPost(c echo.Context) error {
+	post, err := h.scraperService.RejectQuarantined(c.Param("id"), c.Get("user_id").(string))
+	if err != nil {
+		return h.postError("reject", err)
+	}
+	return c.JSON(http.StatusOK, post)
+}
+
+// GetModel returns the quarantine threshold and the state of the spam model
+func (h *QuarantineHandler) GetModel(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"threshold": h.spam.Threshold(),
+		"model":     h.spam.Stats(),
+	})
+}
+
+// TrainModel handles teaching the spam model a post, without quarantining it
+func (h *QuarantineHandler) TrainModel(c echo.Context) error {
+	var req TrainSpamRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if strings.TrimSpace(req.Text) == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "text is required")
+	}
+
+	stats, err := h.spam.Train(req.Text, req.Spam)
+	if err != nil {
+		log.Printf("Error training spam model: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]interface{}{
+			"message": "Failed to save spam model",
+			"error":   err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"threshold": h.spam.Threshold(),
+		"model":     stats,
+	})
+}
+
+// postError maps quarantine service errors to HTTP errors
+func (h *QuarantineHandler) postError(action string, err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return echo.NewHTTPError(http.StatusNotFound, "quarantined post not found")
+	case errors.Is(err, services.ErrPostReviewed):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	log.Printf("Error trying to %s quarantined post: %v", action, err)
+	return echo.NewHTTPError(http.StatusInternalServerError, map[string]interface{}{
+		"message": "Failed to " + action + " quarantined post",
+		"error":   err.Error(),
+	})
+}
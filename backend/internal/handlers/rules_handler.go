@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/scraper"
+	"github.com/labstack/echo/v4"
+)
+
+// maxRulesSize limits the size of an uploaded rules file
+const maxRulesSize = 1 << 20
+
+type RulesHandler struct {
+	rules *scraper.RuleEngine
+}
+
+func NewRulesHandler(rules *scraper.RuleEngine) *RulesHandler {
+	return &RulesHandler{
+		rules: rules,
+	}
+}
+
+// GetRules returns the active extraction rules
+func (h *RulesHandler) GetRules(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status": h.rules.Status(),
+		"rules":  string(h.rules.Raw()),
+	})
+}
+
+// ValidateRules checks a YAML rules file without activating it
+func (h *RulesHandler) ValidateRules(c echo.Context) error {
+	data, err := readRules(c)
+	if err != nil {
+		return err
+	}
+
+	summary, err := h.rules.Validate(data)
+	if err != nil {
+		return rulesError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"valid":   true,
+		"summary": summary,
+	})
+}
+
+// UpdateRules validates a YAML rules file and makes it the active rule set
+func (h *RulesHandler) UpdateRules(c echo.Context) error {
+	data, err := readRules(c)
+	if err != nil {
+		return err
+	}
+
+	status, err := h.rules.Activate(data)
+	if err != nil {
+		var invalid *scraper.RulesError
+		if errors.As(err, &invalid) {
+			return rulesError(err)
+		}
+		log.Printf("Error activating extraction rules: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]interface{}{
+			"message": "Failed to activate rules",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"status": status,
+	})
+}
+
+// readRules reads the raw rules file from the request body
+func readRules(c echo.Context) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(c.Request().Body, maxRulesSize+1))
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if len(data) > maxRulesSize {
+		return nil, echo.NewHTTPError(http.StatusRequestEntityTooLarge, "rules file is too large")
+	}
+	if len(data) == 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "rules file is required")
+	}
+	return data, nil
+}
+
+// rulesError reports every problem found in an invalid rules file
+func rulesError(err error) error {
+	problems := []string{err.Error()}
+	var invalid *scraper.RulesError
+	if errors.As(err, &invalid) {
+		problems = invalid.Problems
+	}
+	return echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+		"message": "invalid rules file",
+		"errors":  problems,
+	})
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/scheduler"
+	"github.com/labstack/echo/v4"
+)
+
+type SchedulerHandler struct {
+	scheduler *scheduler.Scheduler
+}
+
+func NewSchedulerHandler(scheduler *scheduler.Scheduler) *SchedulerHandler {
+	return &SchedulerHandler{
+		scheduler: scheduler,
+	}
+}
+
+// GetSchedule returns the last and next run times of every scheduled scrape
+func (h *SchedulerHandler) GetSchedule(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"tasks": h.scheduler.Status(),
+	})
+}
+
+// TriggerTask starts a scheduled scrape immediately
+func (h *SchedulerHandler) TriggerTask(c echo.Context) error {
+	name := c.Param("name")
+
+	if err := h.scheduler.Trigger(name); err != nil {
+		switch {
+		case errors.Is(err, scheduler.ErrUnknownTask):
+			return echo.NewHTTPError(http.StatusNotFound, "scheduled task not found")
+		case errors.Is(err, scheduler.ErrAlreadyRunning):
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"message": "task started",
+		"task":    name,
+	})
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/models"
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+type ScrapeRunHandler struct {
+	runService *services.ScrapeRunService
+}
+
+func NewScrapeRunHandler(runService *services.ScrapeRunService) *ScrapeRunHandler {
+	return &ScrapeRunHandler{
+		runService: runService,
+	}
+}
+
+// ListScrapeRuns handles listing scrape runs with filtering and pagination
+func (h *ScrapeRunHandler) ListScrapeRuns(c echo.Context) error {
+	filter := models.ScrapeRunFilter{
+		Source: c.QueryParam("source"),
+		Status: c.QueryParam("status"),
+	}
+
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil {
+			filter.Limit = limit
+		}
+	}
+	if offsetStr := c.QueryParam("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil {
+			filter.Offset = offset
+		}
+	}
+
+	response, err := h.runService.ListRuns(filter)
+	if err != nil {
+		log.Printf("Error listing scrape runs: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]interface{}{
+			"message": "Failed to retrieve scrape runs",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetScrapeRun handles getting a single scrape run with its per-source stats
+func (h *ScrapeRunHandler) GetScrapeRun(c echo.Context) error {
+	runID := c.Param("id")
+	if runID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "scrape run ID is required")
+	}
+
+	run, err := h.runService.GetRun(runID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return echo.NewHTTPError(http.StatusNotFound, "scrape run not found")
+		}
+		log.Printf("Error getting scrape run %s: %v", runID, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]interface{}{
+			"message": "Failed to retrieve scrape run",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, run)
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/models"
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+type ScrapeSourceHandler struct {
+	sourceService *services.ScrapeSourceService
+}
+
+func NewScrapeSourceHandler(sourceService *services.ScrapeSourceService) *ScrapeSourceHandler {
+	return &ScrapeSourceHandler{
+		sourceService: sourceService,
+	}
+}
+
+// ListSources handles listing the configured groups and channels
+func (h *ScrapeSourceHandler) ListSources(c echo.Context) error {
+	filter := models.ScrapeSourceFilter{
+		Type: c.QueryParam("type"),
+		Tag:  c.QueryParam("tag"),
+	}
+	if enabledStr := c.QueryParam("enabled"); enabledStr != "" {
+		if enabled, err := strconv.ParseBool(enabledStr); err == nil {
+			filter.Enabled = &enabled
+		}
+	}
+
+	sources, err := h.sourceService.ListSources(filter)
+	if err != nil {
+		log.Printf("Error listing scrape sources: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, map[string]interface{}{
+			"message": "Failed to retrieve scrape sources",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"sources": sources,
+	})
+}
+
+// GetSource handles getting a single scrape source
+func (h *ScrapeSourceHandler) GetSource(c echo.Context) error {
+	source, err := h.sourceService.GetSource(c.Param("id"))
+	if err != nil {
+		return h.sourceError("retrieve", err)
+	}
+	return c.JSON(http.StatusOK, source)
+}
+
+// CreateSource handles adding a group or channel to scrape
+func (h *ScrapeSourceHandler) CreateSource(c echo.Context) error {
+	var req models.CreateScrapeSourceRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	if req.Type == "" || req.Identifier == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "type and identifier are required")
+	}
+
+	source, err := h.sourceService.CreateSource(req)
+	if err != nil {
+		return h.sourceError("create", err)
+	}
+	return c.JSON(http.StatusCreated, source)
+}
+
+// UpdateSource handles enabling, disabling or editing a scrape source
+func (h *ScrapeSourceHandler) UpdateSource(c echo.Context) error {
+	var req models.UpdateScrapeSourceRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	source, err := h.sourceService.UpdateSource(c.Param("id"), req)
+	if err != nil {
+		return h.sourceError("update", err)
+	}
+	return c.JSON(http.StatusOK, source)
+}
+
+// DeleteSource handles removing a scrape source
+func (h *ScrapeSourceHandler) DeleteSource(c echo.Context) error {
+	if err := h.sourceService.DeleteSource(c.Param("id")); err != nil {
+		return h.sourceError("delete", err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// sourceError maps scrape source service errors to HTTP errors
+func (h *ScrapeSourceHandler) sourceError(action string, err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return echo.NewHTTPError(http.StatusNotFound, "scrape source not found")
+	case errors.Is(err, services.ErrInvalidScrapeSource):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrScrapeSourceExists):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+
+	log.Printf("Error trying to %s scrape source: %v", action, err)
+	return echo.NewHTTPError(http.StatusInternalServerError, map[string]interface{}{
+		"message": "Failed to " + action + " scrape source",
+		"error":   err.Error(),
+	})
+}
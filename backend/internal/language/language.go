@@ -0,0 +1,227 @@
+// Package language detects the language of crew posts and rewrites the salary
+// and contract phrases of French, Italian and Spanish posts into the English
+// ones the salary and contract parsers understand.
+package language
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Supported languages, as ISO 639-1 codes
+const (
+	English = "en"
+	French  = "fr"
+	Italian = "it"
+	Spanish = "es"
+)
+
+// Languages lists every supported language
+var Languages = []string{English, French, Italian, Spanish}
+
+// IsLanguage reports whether code is one of the supported languages
+func IsLanguage(code string) bool {
+	for _, language := range Languages {
+		if code == language {
+			return true
+		}
+	}
+	return false
+}
+
+// markers are words frequent in one language and rare in the others. Words
+// shared by several languages, such as "la", "de" or "con", are left out.
+var markers = map[string][]string{
+	English: {
+		"the", "and", "for", "with", "we", "are", "is", "you", "our", "looking",
+		"needed", "required", "seeking", "experience", "must", "will", "of",
+		"to", "in", "on", "at", "from", "salary", "month", "start", "based",
+		"please", "send", "hiring", "wanted", "position", "available",
+	},
+	French: {
+		"le", "les", "des", "et", "pour", "avec", "nous", "vous", "recherche",
+		"recherchons", "cherche", "une", "du", "est", "sur", "poste", "expérience",
+		"à", "au", "aux", "dans", "mois", "salaire", "bateau", "hôtesse",
+		"matelot", "capitaine", "mécanicien", "cuisinier", "saison", "être",
+		"merci", "envoyer", "profil",
+	},
+	Italian: {
+		"il", "lo", "gli", "di", "e", "per", "cerchiamo", "cerca", "cercasi",
+		"una", "della", "dello", "degli", "è", "siamo", "esperienza", "nel",
+		"nella", "mesi", "mese", "stipendio", "barca", "marinaio", "comandante",
+		"cuoco", "stagione", "inviare", "disponibilità", "richiesta", "bordo",
+		"imbarco",
+	},
+	Spanish: {
+		"el", "los", "las", "y", "para", "buscamos", "busca", "se", "es",
+		"estamos", "experiencia", "meses", "mes", "salario", "sueldo", "barco",
+		"marinero", "azafata", "capitán", "cocinero", "temporada", "enviar",
+		"por", "año", "también", "incorporación", "bordo",
+	},
+}
+
+// markerLanguages maps each marker word to the languages it marks
+var markerLanguages = func() map[string][]string {
+	index := make(map[string][]string)
+	for language, words := range markers {
+		for _, word := range words {
+			index[word] = append(index[word], language)
+		}
+	}
+	return index
+}()
+
+// Detect returns the language text is written in, or an empty string when it
+// is too short or too mixed to tell
+func Detect(text string) string {
+	scores := make(map[string]int)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	}) {
+		// Elisions such as "l'expérience" or "dell'armatore"
+		if i := strings.LastIndex(word, "'"); i >= 0 {
+			word = word[i+1:]
+		}
+		for _, language := range markerLanguages[word] {
+			scores[language]++
+		}
+	}
+
+	best, bestScore, runnerUp := "", 0, 0
+	for _, language := range Languages {
+		switch score := scores[language]; {
+		case score > bestScore:
+			best, bestScore, runnerUp = language, score, bestScore
+		case score > runnerUp:
+			runnerUp = score
+		}
+	}
+	if bestScore < 2 || bestScore == runnerUp {
+		return ""
+	}
+	return best
+}
+
+// phrase is a foreign phrase and its English equivalent
+type phrase struct {
+	regex   *regexp.Regexp
+	english string
+}
+
+// newPhrases compiles pairs of case-insensitive patterns and their English
+// equivalents. Patterns match whole words, accented letters included.
+func newPhrases(pairs ...string) []phrase {
+	phrases := make([]phrase, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		phrases = append(phrases, phrase{
+			regex:   regexp.MustCompile(`(?i)(^|[^\p{L}])(?:` + pairs[i] + `)([^\p{L}]|$)`),
+			english: "${1}" + pairs[i+1] + "${2}",
+		})
+	}
+	return phrases
+}
+
+// phrases are the salary and contract phrases of each language, most
+// specific first
+var phrases = map[string][]phrase{
+	French: newPhrases(
+		`salaire|rémunération|rémunéré`, "salary",
+		`par\s+mois|/\s*mois|mensuels?|mensuelles?`, "per month",
+		`par\s+jour|/\s*jour|journaliers?`, "per day",
+		`par\s+semaine|/\s*semaine|hebdomadaires?`, "per week",
+		`par\s+an|/\s*an|annuels?|annuelles?|brut\s+annuel`, "per year",
+		`selon\s+(?:expérience|profil)`, "DOE",
+		`à\s+négocier|négociables?`, "negotiable",
+		`mois`, "months",
+		`semaines?`, "weeks",
+		`ans|années?`, "years",
+		`saison\s+(?:d'été|estivale)\s+en\s+méditerranée|saison\s+méditerranée`, "summer Med season",
+		`saison\s+(?:d'hiver|hivernale)\s+aux\s+caraïbes|saison\s+caraïbes`, "winter Caribbean season",
+		`saison\s+d'été|saison\s+estivale`, "summer season",
+		`saison\s+d'hiver|saison\s+hivernale`, "winter season",
+		`saisonniers?|saisonnières?`, "seasonal",
+		`saison`, "season",
+		`cdi|poste\s+fixe|permanent`, "permanent",
+		`cdd|temporaire`, "temporary",
+		`remplacement`, "relief",
+		`en\s+rotation|rotation`, "rotation",
+		`convoyage|traversée`, "delivery",
+		`été`, "summer",
+		`hiver`, "winter",
+		`méditerranée`, "Mediterranean",
+		`caraïbes`, "Caribbean",
+	),
+	Italian: newPhrases(
+		`stipendio|retribuzione|paga`, "salary",
+		`al\s+mese|/\s*mese|mensil[ei]`, "per month",
+		`al\s+giorno|/\s*giorno|giornalier[oia]`, "per day",
+		`a\s+settimana|/\s*settimana|settimanal[ei]`, "per week",
+		`all'anno|/\s*anno|annu[oi]|annual[ei]|lordi\s+annui`, "per year",
+		`in\s+base\s+all'esperienza|commisurat[oa]\s+all'esperienza`, "DOE",
+		`da\s+concordare|trattabil[ei]`, "negotiable",
+		`mesi|mese`, "months",
+		`settimane|settimana`, "weeks",
+		`anni|anno`, "years",
+		`stagione\s+estiva\s+(?:nel\s+)?mediterraneo`, "summer Med season",
+		`stagione\s+invernale\s+(?:ai\s+)?caraibi`, "winter Caribbean season",
+		`stagione\s+estiva`, "summer season",
+		`stagione\s+invernale`, "winter season",
+		`stagional[ei]`, "seasonal",
+		`stagione`, "season",
+		`tempo\s+indeterminato|posto\s+fisso|permanente`, "permanent",
+		`tempo\s+determinato|temporane[oa]`, "temporary",
+		`sostituzione`, "relief",
+		`a\s+rotazione|rotazione`, "rotation",
+		`trasferimento|traversata`, "delivery",
+		`estate`, "summer",
+		`inverno`, "winter",
+		`mediterraneo`, "Mediterranean",
+		`caraibi`, "Caribbean",
+	),
+	Spanish: newPhrases(
+		`salario|sueldo|remuneración`, "salary",
+		`al\s+mes|por\s+mes|/\s*mes|mensuales?`, "per month",
+		`al\s+día|por\s+día|/\s*día|diarios?`, "per day",
+		`por\s+semana|/\s*semana|semanales?`, "per week",
+		`al\s+año|por\s+año|/\s*año|anuales?|brutos\s+anuales`, "per year",
+		`según\s+experiencia|según\s+valía`, "DOE",
+		`a\s+convenir|negociables?`, "negotiable",
+		`meses|mes`, "months",
+		`semanas|semana`, "weeks",
+		`años|año`, "years",
+		`temporada\s+de\s+verano\s+en\s+el\s+mediterráneo`, "summer Med season",
+		`temporada\s+de\s+invierno\s+en\s+el\s+caribe`, "winter Caribbean season",
+		`temporada\s+de\s+verano`, "summer season",
+		`temporada\s+de\s+invierno`, "winter season",
+		`de\s+temporada`, "seasonal",
+		`temporada`, "season",
+		`indefinido|fijo|permanente`, "permanent",
+		`temporal`, "temporary",
+		`sustitución|suplencia`, "relief",
+		`rotación|rotativo`, "rotation",
+		`travesía|entrega`, "delivery",
+		`verano`, "summer",
+		`invierno`, "winter",
+		`mediterráneo`, "Mediterranean",
+		`caribe`, "Caribbean",
+	),
+}
+
+// Normalize rewrites the salary and contract phrases of a post in the given
+// language into English, e.g. "3000 € par mois, saison d'été" into
+// "3000 € per month, summer season". Text in other languages is unchanged.
+func Normalize(text, language string) string {
+	for _, p := range phrases[language] {
+		// Matches share their boundary characters, so repeat until adjacent
+		// phrases such as "mois/mois" are all rewritten
+		for i := 0; i < 3; i++ {
+			rewritten := p.regex.ReplaceAllString(text, p.english)
+			if rewritten == text {
+				break
+			}
+			text = rewritten
+		}
+	}
+	return text
+}
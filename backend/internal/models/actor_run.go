@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// ActorRun tracks an Apify actor run started by the scraper
+type ActorRun struct {
+	RunID       string     `json:"run_id" db:"run_id"`
+	Source      string     `json:"source" db:"source"`
+	ActorID     string     `json:"actor_id" db:"actor_id"`
+	Status      string     `json:"status" db:"status"`
+	StartedAt   time.Time  `json:"started_at" db:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty" db:"finished_at"`
+	CollectedAt *time.Time `json:"collected_at,omitempty" db:"collected_at"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// CrewAvailabilityPost is a scraped post where a crew member advertises their
+// availability, such as "Experienced deckhand available from May, B1/B2"
+type CrewAvailabilityPost struct {
+	ID                 string     `json:"id" db:"id"`
+	Source             string     `json:"source" db:"source"` // Scraper source that fetched the post
+	URL                string     `json:"url,omitempty" db:"url"`
+	URLFingerprint     string     `json:"-" db:"url_fingerprint"`
+	ContentFingerprint string     `json:"-" db:"content_fingerprint"`
+	GroupName          string     `json:"group_name,omitempty" db:"group_name"`
+	ChannelName        string     `json:"channel_name,omitempty" db:"channel_name"`
+	Text               string     `json:"text" db:"text"`
+	Position           string     `json:"position,omitempty" db:"position"`
+	AvailableFrom      *time.Time `json:"available_from,omitempty" db:"available_from"`
+	Availability       string     `json:"availability,omitempty" db:"availability"` // As worded in the post, e.g. "from May"
+	Certifications     []string   `json:"certifications" db:"certifications"`       // Stored as JSON array string
+	Location           string     `json:"location,omitempty" db:"location"`
+	CountryCode        string     `json:"country_code,omitempty" db:"country_code"` // ISO 3166-1 alpha-2
+	Region             string     `json:"region,omitempty" db:"region"`
+	Language           string     `json:"language,omitempty" db:"language"`
+	PostedAt           *time.Time `json:"posted_at,omitempty" db:"posted_at"`
+	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
+}
+
+type CrewAvailabilityFilter struct {
+	Position      string     `query:"position"`
+	Certification string     `query:"certification"`
+	Region        string     `query:"region"`
+	Country       string     `query:"country"`
+	Language      string     `query:"language"`
+	AvailableBy   *time.Time `query:"available_by"` // Available on or before this date
+	Limit         int        `query:"limit"`
+	Offset        int        `query:"offset"`
+}
+
+type CrewAvailabilityResponse struct {
+	Posts []CrewAvailabilityPost `json:"posts"`
+	Total int                    `json:"total"`
+	Page  int                    `json:"page"`
+	Limit int                    `json:"limit"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// ExchangeRate is the value of 1 EUR in another currency
+type ExchangeRate struct {
+	Currency  string    `json:"currency" db:"currency"`
+	Rate      float64   `json:"rate" db:"rate"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// UpdateExchangeRatesRequest sets the rates of the given currencies, keyed
+// by ISO 4217 code, e.g. {"rates": {"USD": 1.08}}
+type UpdateExchangeRatesRequest struct {
+	Rates map[string]float64 `json:"rates" validate:"required"`
+}
@@ -16,9 +16,52 @@ type Job struct {
 	SourceURL   string    `json:"source_url" db:"source_url"`
 	Source      string    `json:"source" db:"source"` // Which site it was scraped from
 	PostedAt    time.Time `json:"posted_at" db:"posted_at"`
+	ValidThrough *time.Time `json:"valid_through,omitempty" db:"valid_through"` // Application deadline, nil when not stated
+	Language    string    `json:"language,omitempty" db:"language"` // ISO 639-1 code, e.g. "fr", empty when not detected
 	ScrapedAt   time.Time `json:"scraped_at" db:"scraped_at"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+
+	// Structured salary parsed from Salary, amounts are nil when not stated
+	SalaryMin  *float64 `json:"salary_min,omitempty" db:"salary_min"`
+	SalaryMax  *float64 `json:"salary_max,omitempty" db:"salary_max"`
+	Currency   string   `json:"salary_currency,omitempty" db:"salary_currency"` // ISO 4217, e.g. "EUR"
+	Period     string   `json:"salary_period,omitempty" db:"salary_period"`     // day, week, month, year or trip
+	Negotiable bool     `json:"salary_negotiable" db:"salary_negotiable"`
+
+	// Structured contract terms parsed from Duration, zero when not stated
+	ContractKind   string  `json:"contract_kind,omitempty" db:"contract_kind"` // permanent, seasonal, rotational, temporary or delivery
+	RotationOn     int     `json:"rotation_weeks_on,omitempty" db:"rotation_weeks_on"`
+	RotationOff    int     `json:"rotation_weeks_off,omitempty" db:"rotation_weeks_off"`
+	ContractMonths float64 `json:"contract_months,omitempty" db:"contract_months"`
+	Season         string  `json:"season,omitempty" db:"season"` // summer_med, winter_caribbean, summer or winter
+
+	// Normalized location resolved from Location, empty when it is not in the gazetteer
+	Port        string   `json:"port,omitempty" db:"port"`
+	CountryCode string   `json:"country_code,omitempty" db:"country_code"` // ISO 3166-1 alpha-2
+	Region      string   `json:"region,omitempty" db:"region"`             // e.g. "mediterranean"
+	Latitude    *float64 `json:"latitude,omitempty" db:"latitude"`
+	Longitude   *float64 `json:"longitude,omitempty" db:"longitude"`
+
+	// Distance from the searched point, only set for searches near a point
+	DistanceKm *float64 `json:"distance_km,omitempty"`
+
+	// Deduplication fingerprints
+	URLFingerprint     string `json:"-" db:"url_fingerprint"`
+	ContentFingerprint string `json:"-" db:"content_fingerprint"`
+
+	// Every place the job has been seen, only loaded for single job lookups
+	Sources []JobSource `json:"sources,omitempty"`
+}
+
+// JobSource links a job to a place it was posted
+type JobSource struct {
+	ID          string    `json:"id" db:"id"`
+	JobID       string    `json:"job_id" db:"job_id"`
+	Source      string    `json:"source" db:"source"`
+	SourceURL   string    `json:"source_url" db:"source_url"`
+	FirstSeenAt time.Time `json:"first_seen_at" db:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at" db:"last_seen_at"`
 }
 
 type JobFilter struct {
@@ -27,6 +70,26 @@ type JobFilter struct {
 	Company  string `query:"company"`
 	Limit    int    `query:"limit"`
 	Offset   int    `query:"offset"`
+
+	// Salary bounds, expressed in Currency per Period (default EUR per month)
+	SalaryMin *float64 `query:"salary_min"`
+	SalaryMax *float64 `query:"salary_max"`
+	Currency  string   `query:"currency"`
+	Period    string   `query:"period"`
+
+	// Contract terms
+	ContractKind string   `query:"contract"`
+	Rotation     string   `query:"rotation"` // Weeks on/off, e.g. "10/10"
+	Season       string   `query:"season"`
+	MonthsMin    *float64 `query:"months_min"`
+	MonthsMax    *float64 `query:"months_max"`
+
+	// Geo search
+	Near     string   `query:"near"`      // Port name or "lat,lon"
+	RadiusKm *float64 `query:"radius_km"` // Default 100
+	Region   string   `query:"region"`
+
+	Language string `query:"language"` // ISO 639-1 code, e.g. "fr"
 }
 
 type JobResponse struct {
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// Quarantined post review statuses
+const (
+	QuarantineStatusPending  = "pending"
+	QuarantineStatusApproved = "approved"
+	QuarantineStatusRejected = "rejected"
+)
+
+// QuarantinedPost is a scraped post that looked like a scam or spam, held
+// for an admin to approve, which saves its jobs, or reject
+type QuarantinedPost struct {
+	ID          string     `json:"id" db:"id"`
+	Source      string     `json:"source" db:"source"` // Scraper source that fetched the post
+	Fingerprint string     `json:"-" db:"fingerprint"`
+	URL         string     `json:"url,omitempty" db:"url"`
+	GroupName   string     `json:"group_name,omitempty" db:"group_name"`
+	ChannelName string     `json:"channel_name,omitempty" db:"channel_name"`
+	PostSource  string     `json:"post_source,omitempty" db:"post_source"` // Source field of the scraped post
+	Text        string     `json:"text" db:"text"`
+	PostedAt    *time.Time `json:"posted_at,omitempty" db:"posted_at"`
+	Score       float64    `json:"score" db:"score"`
+	Reasons     []string   `json:"reasons" db:"reasons"` // Stored as JSON array string
+	Status      string     `json:"status" db:"status"`
+	ReviewedBy  string     `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewedAt  *time.Time `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+type QuarantinedPostFilter struct {
+	Status string `query:"status"`
+	Source string `query:"source"`
+	Limit  int    `query:"limit"`
+	Offset int    `query:"offset"`
+}
+
+type QuarantinedPostResponse struct {
+	Posts []QuarantinedPost `json:"posts"`
+	Total int               `json:"total"`
+	Page  int               `json:"page"`
+	Limit int               `json:"limit"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// ScrapeCursor is the high-water mark of one scraped channel, such as a
+// Facebook group or Telegram channel
+type ScrapeCursor struct {
+	Source        string     `json:"source" db:"source"`
+	Channel       string     `json:"channel" db:"channel"`
+	LastPostAt    *time.Time `json:"last_post_at,omitempty" db:"last_post_at"`
+	LastMessageID int64      `json:"last_message_id,omitempty" db:"last_message_id"`
+	// Validators of the last feed response, sent on the next conditional GET
+	ETag         string `json:"etag,omitempty" db:"etag"`
+	LastModified string `json:"last_modified,omitempty" db:"last_modified"`
+	// Bytes of an mbox file already read
+	ByteOffset int64     `json:"byte_offset,omitempty" db:"byte_offset"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// IsNewer reports whether a post lies beyond the cursor. Message IDs are
+// compared when both are known, otherwise post times.
+func (c *ScrapeCursor) IsNewer(postedAt time.Time, messageID int64) bool {
+	if messageID > 0 && c.LastMessageID > 0 {
+		return messageID > c.LastMessageID
+	}
+	if !postedAt.IsZero() && c.LastPostAt != nil {
+		return postedAt.After(*c.LastPostAt)
+	}
+	return true
+}
+
+// Advance moves the cursor forward to a post; older positions are ignored
+func (c *ScrapeCursor) Advance(postedAt time.Time, messageID int64) {
+	if !postedAt.IsZero() && (c.LastPostAt == nil || postedAt.After(*c.LastPostAt)) {
+		c.LastPostAt = &postedAt
+	}
+	if messageID > c.LastMessageID {
+		c.LastMessageID = messageID
+	}
+}
+
+// ResetCursorRequest selects the cursors to reset. An empty channel resets
+// every channel of the source.
+type ResetCursorRequest struct {
+	Source  string `json:"source" validate:"required"`
+	Channel string `json:"channel"`
+}
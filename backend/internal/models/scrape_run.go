@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// Scrape run statuses
+const (
+	ScrapeStatusRunning   = "running"
+	ScrapeStatusSucceeded = "succeeded"
+	ScrapeStatusPartial   = "partial"
+	ScrapeStatusFailed    = "failed"
+)
+
+type ScrapeRun struct {
+	ID                string            `json:"id" db:"id"`
+	Status            string            `json:"status" db:"status"`
+	StartedAt         time.Time         `json:"started_at" db:"started_at"`
+	FinishedAt        *time.Time        `json:"finished_at,omitempty" db:"finished_at"`
+	PostsFetched      int               `json:"posts_fetched" db:"posts_fetched"`
+	JobsExtracted     int               `json:"jobs_extracted" db:"jobs_extracted"`
+	JobsSaved         int               `json:"jobs_saved" db:"jobs_saved"`
+	DuplicatesSkipped int               `json:"duplicates_skipped" db:"duplicates_skipped"`
+	ErrorCount        int               `json:"error_count" db:"error_count"`
+	BlockedFetches    int               `json:"blocked_fetches" db:"blocked_fetches"`
+	PostsQuarantined  int               `json:"posts_quarantined" db:"posts_quarantined"`
+	CrewPostsSaved    int               `json:"crew_posts_saved" db:"crew_posts_saved"`
+	Sources           []ScrapeRunSource `json:"sources,omitempty"`
+}
+
+// ScrapeRunSource holds the stats of a single source within a scrape run
+type ScrapeRunSource struct {
+	ID                string     `json:"id" db:"id"`
+	RunID             string     `json:"run_id" db:"run_id"`
+	Source            string     `json:"source" db:"source"`
+	Status            string     `json:"status" db:"status"`
+	StartedAt         time.Time  `json:"started_at" db:"started_at"`
+	FinishedAt        *time.Time `json:"finished_at,omitempty" db:"finished_at"`
+	PostsFetched      int        `json:"posts_fetched" db:"posts_fetched"`
+	JobsExtracted     int        `json:"jobs_extracted" db:"jobs_extracted"`
+	JobsSaved         int        `json:"jobs_saved" db:"jobs_saved"`
+	DuplicatesSkipped int        `json:"duplicates_skipped" db:"duplicates_skipped"`
+	ErrorCount        int        `json:"error_count" db:"error_count"`
+	BlockedFetches    int        `json:"blocked_fetches" db:"blocked_fetches"`     // Disallowed by robots.txt
+	PostsQuarantined  int        `json:"posts_quarantined" db:"posts_quarantined"` // Held for review as likely spam
+	CrewPostsSaved    int        `json:"crew_posts_saved" db:"crew_posts_saved"`   // Crew availability posts
+	Errors            []string   `json:"errors" db:"errors"`                       // Stored as JSON array string
+}
+
+type ScrapeRunFilter struct {
+	Source string `query:"source"`
+	Status string `query:"status"`
+	Limit  int    `query:"limit"`
+	Offset int    `query:"offset"`
+}
+
+type ScrapeRunResponse struct {
+	Runs  []ScrapeRun `json:"runs"`
+	Total int         `json:"total"`
+	Page  int         `json:"page"`
+	Limit int         `json:"limit"`
+}
@@ -0,0 +1,108 @@
+package models
+
+import "time"
+
+// Scrape source types, matching the scraper source that uses them
+const (
+	ScrapeSourceTypeFacebook = "facebook"
+	ScrapeSourceTypeTelegram = "telegram"
+	ScrapeSourceTypeHTML     = "html"
+	ScrapeSourceTypeFeed     = "feed"
+)
+
+// ScrapeSource is a Facebook group, Telegram channel, HTML job board or
+// RSS/Atom feed scraped for jobs
+type ScrapeSource struct {
+	ID          string          `json:"id" db:"id"`
+	Type        string          `json:"type" db:"type"`
+	Identifier  string          `json:"identifier" db:"identifier"`
+	DisplayName string          `json:"display_name" db:"display_name"`
+	Enabled     bool            `json:"enabled" db:"enabled"`
+	MaxPosts    int             `json:"max_posts" db:"max_posts"`
+	Tags        []string        `json:"tags" db:"tags"`
+	Config      *HTMLSiteConfig `json:"config,omitempty" db:"config"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// HTML job board fields that can be mapped with a selector
+const (
+	HTMLFieldTitle        = "title"
+	HTMLFieldCompany      = "company"
+	HTMLFieldLocation     = "location"
+	HTMLFieldType         = "type"
+	HTMLFieldVessel       = "vessel"
+	HTMLFieldDuration     = "duration"
+	HTMLFieldSalary       = "salary"
+	HTMLFieldDescription  = "description"
+	HTMLFieldRequirements = "requirements"
+	HTMLFieldURL          = "url"
+	HTMLFieldPostedAt     = "posted_at"
+)
+
+// HTMLFields lists every field an HTML job board can map
+var HTMLFields = []string{
+	HTMLFieldTitle, HTMLFieldCompany, HTMLFieldLocation, HTMLFieldType, HTMLFieldVessel, HTMLFieldDuration,
+	HTMLFieldSalary, HTMLFieldDescription, HTMLFieldRequirements, HTMLFieldURL, HTMLFieldPostedAt,
+}
+
+// HTMLSiteConfig maps the listing page of an HTML job board to jobs
+type HTMLSiteConfig struct {
+	// ListSelector matches one element per job listing
+	ListSelector string `json:"list_selector"`
+	// Fields maps job fields to the elements holding them within a listing
+	Fields map[string]HTMLFieldSelector `json:"fields"`
+	// DateFormat is the Go time layout of posted_at, e.g. "02/01/2006"
+	DateFormat string `json:"date_format,omitempty"`
+	// BaseURL resolves relative job URLs, the page URL when empty
+	BaseURL string `json:"base_url,omitempty"`
+	// NextPageSelector matches the link to the next listing page
+	NextPageSelector string `json:"next_page_selector,omitempty"`
+	// MaxPages is how many listing pages are followed, 1 when zero
+	MaxPages int `json:"max_pages,omitempty"`
+	// Detail fills fields from each job's detail page when set
+	Detail *HTMLDetailConfig `json:"detail,omitempty"`
+}
+
+// HTMLDetailConfig maps the detail page of a job. Fields found on it replace
+// the ones read from the listing.
+type HTMLDetailConfig struct {
+	Fields map[string]HTMLFieldSelector `json:"fields"`
+}
+
+// HTMLFieldSelector extracts a field from a listing element
+type HTMLFieldSelector struct {
+	// Selector matches the element within the listing, the listing itself when empty
+	Selector string `json:"selector,omitempty"`
+	// Attr reads an attribute such as "href" instead of the element text
+	Attr string `json:"attr,omitempty"`
+	// Default is used when nothing is found, e.g. the company of a single employer board
+	Default string `json:"default,omitempty"`
+}
+
+// ScrapeSourceFilter represents filters for listing scrape sources
+type ScrapeSourceFilter struct {
+	Type    string `json:"type"`
+	Enabled *bool  `json:"enabled"`
+	Tag     string `json:"tag"`
+}
+
+// CreateScrapeSourceRequest represents a request to add a scrape source
+type CreateScrapeSourceRequest struct {
+	Type        string          `json:"type" validate:"required"`
+	Identifier  string          `json:"identifier" validate:"required"`
+	DisplayName string          `json:"display_name"`
+	Enabled     *bool           `json:"enabled"`
+	MaxPosts    int             `json:"max_posts"`
+	Tags        []string        `json:"tags"`
+	Config      *HTMLSiteConfig `json:"config"`
+}
+
+// UpdateScrapeSourceRequest represents a partial update of a scrape source
+type UpdateScrapeSourceRequest struct {
+	DisplayName *string         `json:"display_name"`
+	Enabled     *bool           `json:"enabled"`
+	MaxPosts    *int            `json:"max_posts"`
+	Tags        []string        `json:"tags"`
+	Config      *HTMLSiteConfig `json:"config"`
+}
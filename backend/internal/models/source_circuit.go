@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// SourceCircuit is the circuit breaker of a scraper source. It opens after
+// too many consecutive failed runs, and the source is skipped until it is reset.
+type SourceCircuit struct {
+	Source              string     `json:"source" db:"source"`
+	ConsecutiveFailures int        `json:"consecutive_failures" db:"consecutive_failures"`
+	Open                bool       `json:"open" db:"open"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty" db:"opened_at"`
+	LastError           string     `json:"last_error,omitempty" db:"last_error"`
+	UpdatedAt           time.Time  `json:"updated_at" db:"updated_at"`
+}
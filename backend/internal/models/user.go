@@ -6,6 +6,23 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// User roles. Employers and agencies can browse crew availability posts.
+const (
+	RoleUser     = "user"
+	RoleEmployer = "employer"
+	RoleAgency   = "agency"
+	RoleAdmin    = "admin"
+)
+
+// IsRole reports whether role is one of the user roles
+func IsRole(role string) bool {
+	switch role {
+	case RoleUser, RoleEmployer, RoleAgency, RoleAdmin:
+		return true
+	}
+	return false
+}
+
 type User struct {
 	ID        string    `json:"id" db:"id"`
 	Email     string    `json:"email" db:"email"`
@@ -90,6 +107,10 @@ type UpdateUserProfileRequest struct {
 	Certifications      *string `json:"certifications,omitempty"`
 }
 
+type UpdateUserRoleRequest struct {
+	Role string `json:"role"`
+}
+
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
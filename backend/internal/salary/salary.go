@@ -0,0 +1,299 @@
+// Package salary parses free-text salary mentions such as
+// "€4,500 - 5,000/month DOE" or "Day rate 250 USD" into structured values.
+package salary
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Pay periods
+const (
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+	PeriodYear  = "year"
+	PeriodTrip  = "trip"
+)
+
+// Periods lists every pay period
+var Periods = []string{PeriodDay, PeriodWeek, PeriodMonth, PeriodYear, PeriodTrip}
+
+// monthlyFactors convert an amount per period into a monthly amount. Day
+// rates assume 21.75 working days a month and trip fees are treated as one
+// month of pay.
+var monthlyFactors = map[string]float64{
+	PeriodDay:   21.75,
+	PeriodWeek:  52.0 / 12,
+	PeriodMonth: 1,
+	PeriodYear:  1.0 / 12,
+	PeriodTrip:  1,
+}
+
+// MonthlyFactor returns the multiplier converting an amount per period into
+// a monthly amount. Unknown periods are treated as monthly.
+func MonthlyFactor(period string) float64 {
+	if factor, ok := monthlyFactors[period]; ok {
+		return factor
+	}
+	return 1
+}
+
+// IsPeriod reports whether period is one of the Period constants
+func IsPeriod(period string) bool {
+	_, ok := monthlyFactors[period]
+	return ok
+}
+
+// Salary is a parsed salary. Max equals Min for a single figure and both are
+// zero when the post only says the salary is negotiable.
+type Salary struct {
+	Min        float64
+	Max        float64
+	Currency   string // ISO 4217 code, empty when not stated
+	Period     string // One of the Period constants, empty when unknown
+	Negotiable bool
+	Text       string // The fragment the salary was read from
+}
+
+// HasAmount reports whether the salary states a figure
+func (s *Salary) HasAmount() bool {
+	return s.Max > 0
+}
+
+const (
+	currencyPattern = `us\$|a\$|au\$|c\$|nz\$|€|\$|£|(?:eur(?:os?)?|usd|gbp|aud|cad|nzd|chf|zar|dollars?|pounds?|quid)\b`
+	amountPattern   = `\d{1,3}(?:[.,\s]\d{3})+(?:[.,]\d{1,2})?|\d+(?:[.,]\d+)?`
+)
+
+var (
+	// moneyRegex matches a figure or range with an optional currency on either side
+	moneyRegex = regexp.MustCompile(`(?i)` +
+		`(?P<cur1>` + currencyPattern + `)?\s?` +
+		`(?P<min>` + amountPattern + `)\s?(?P<k1>k\b)?\s?(?P<cur2>` + currencyPattern + `)?` +
+		`(?:\s?(?:-|–|—|to|/)\s?(?P<cur3>` + currencyPattern + `)?\s?` +
+		`(?P<max>` + amountPattern + `)\s?(?P<k2>k\b)?\s?(?P<cur4>` + currencyPattern + `)?)?`)
+
+	// unitRegex matches figures that are measurements or counts rather than pay
+	unitRegex = regexp.MustCompile(`(?i)^\s?(?:m\b|ft\b|feet|foot|meters?|metres?|years?|yrs?|days?\b|nights?|people|pax|persons?|months?\b(?:\s+(?:contract|rotation|on|off))|weeks?\s+(?:on|off)|\+|%|crew|guests?|cabins?|kts?|knots|hp|am\b|pm\b|h\b|hrs?\b|hours?)`)
+
+	keywordRegex = regexp.MustCompile(`(?i)\b(?:salary|salaries|wages?|pay|paid|rate|compensation|remuneration|earn)`)
+
+	negotiableRegex = regexp.MustCompile(`(?i)\b(?:doe|d\.o\.e|depending on (?:experience|exp)|dep(?:ending)? on exp|negotiable|neg|tbc|tbd|to be (?:discussed|confirmed|agreed))\b`)
+
+	periodRegexes = []struct {
+		period string
+		regex  *regexp.Regexp
+	}{
+		{PeriodDay, regexp.MustCompile(`(?i)(?:/|\bper\s|\ba\s|\bp/?)\s?(?:day|d\b)|\bdaily\b|\bday\s?rate`)},
+		{PeriodWeek, regexp.MustCompile(`(?i)(?:/|\bper\s|\ba\s|\bp/?)\s?(?:week|wk|w\b)|\bweekly\b`)},
+		{PeriodMonth, regexp.MustCompile(`(?i)(?:/|\bper\s|\ba\s|\bp/?)\s?(?:month|mo\b|mth|m\b)|\bmonthly\b|\bpcm\b|\bp\.m\.`)},
+		{PeriodYear, regexp.MustCompile(`(?i)(?:/|\bper\s|\ba\s|\bp/?)\s?(?:year|yr|annum|a\b)|\bannual(?:ly)?\b|\byearly\b|\bp\.a\.?`)},
+		{PeriodTrip, regexp.MustCompile(`(?i)(?:/|\bper\s|\ba\s|\bfor the\s)\s?(?:trip|delivery|crossing|passage)|\b(?:delivery|fixed) fee\b`)},
+	}
+
+	currencyCodes = map[string]string{
+		"€": "EUR", "eur": "EUR", "euro": "EUR", "euros": "EUR",
+		"$": "USD", "us$": "USD", "usd": "USD", "dollar": "USD", "dollars": "USD",
+		"£": "GBP", "gbp": "GBP", "pound": "GBP", "pounds": "GBP", "quid": "GBP",
+		"a$": "AUD", "au$": "AUD", "aud": "AUD",
+		"c$": "CAD", "cad": "CAD",
+		"nz$": "NZD", "nzd": "NZD",
+		"chf": "CHF",
+		"zar": "ZAR",
+	}
+)
+
+// periodWindow is how far around a figure period and keyword hints are searched
+const periodWindow = 30
+
+// Parse returns the first salary mentioned in text, or nil if there is none.
+// A figure needs a currency or a salary keyword nearby to count as pay. The
+// period is read from the text around the figure and otherwise inferred from
+// its size.
+func Parse(text string) *Salary {
+	for _, line := range strings.Split(text, "\n") {
+		if salary := parseLine(line); salary != nil {
+			return salary
+		}
+	}
+
+	if match := negotiableRegex.FindString(text); match != "" && keywordRegex.MatchString(text) {
+		return &Salary{Negotiable: true, Text: match}
+	}
+	return nil
+}
+
+func parseLine(line string) *Salary {
+	names := moneyRegex.SubexpNames()
+
+	for _, indexes := range moneyRegex.FindAllStringSubmatchIndex(line, -1) {
+		groups := make(map[string]string)
+		for i, name := range names {
+			if name != "" && indexes[2*i] >= 0 {
+				groups[name] = line[indexes[2*i]:indexes[2*i+1]]
+			}
+		}
+		start, end := indexes[0], indexes[1]
+
+		currency := firstCurrency(groups["cur1"], groups["cur2"], groups["cur3"], groups["cur4"])
+		before := window(line, start-periodWindow, start)
+
+		// Without a currency the figure needs a salary keyword before it or a
+		// pay period right after it, e.g. "salary 4000" or "120k per annum"
+		if currency == "" {
+			if unitRegex.MatchString(line[end:]) {
+				continue
+			}
+			if !keywordRegex.MatchString(before) && findPeriod(window(line, end, end+12), false) == "" {
+				continue
+			}
+		}
+
+		min, ok := parseAmount(groups["min"])
+		if !ok {
+			continue
+		}
+		max := min
+		if groups["max"] != "" {
+			if max, ok = parseAmount(groups["max"]); !ok {
+				continue
+			}
+		}
+
+		if groups["k2"] != "" {
+			max *= 1000
+			if groups["k1"] != "" || min < 1000 {
+				min *= 1000
+			}
+		} else if groups["k1"] != "" {
+			min *= 1000
+			if groups["max"] == "" {
+				max = min
+			}
+		}
+		if min > max {
+			min, max = max, min
+		}
+		if max < 10 {
+			continue // Ratings, counts and similar small numbers
+		}
+
+		period := findPeriod(window(line, end, end+periodWindow), false)
+		if period == "" {
+			period = findPeriod(before, true)
+		}
+		if period == "" {
+			period = inferPeriod(max)
+		}
+
+		return &Salary{
+			Min:        min,
+			Max:        max,
+			Currency:   currency,
+			Period:     period,
+			Negotiable: negotiableRegex.MatchString(line),
+			Text:       strings.TrimSpace(line[start:end]),
+		}
+	}
+	return nil
+}
+
+// parseAmount parses a figure written with either comma or dot thousand separators
+func parseAmount(value string) (float64, bool) {
+	value = strings.ReplaceAll(value, " ", "")
+
+	lastComma := strings.LastIndex(value, ",")
+	lastDot := strings.LastIndex(value, ".")
+
+	switch {
+	case lastComma >= 0 && lastDot >= 0:
+		// Both separators: the last one is the decimal point
+		if lastComma > lastDot {
+			value = strings.ReplaceAll(value, ".", "")
+			value = strings.Replace(value, ",", ".", 1)
+		} else {
+			value = strings.ReplaceAll(value, ",", "")
+		}
+	case lastComma >= 0:
+		value = normalizeSeparator(value, ",")
+	case lastDot >= 0:
+		value = normalizeSeparator(value, ".")
+	}
+
+	amount, err := strconv.ParseFloat(value, 64)
+	return amount, err == nil
+}
+
+// normalizeSeparator treats a lone separator as thousands when three digits
+// follow every occurrence, e.g. "4,500" or "4.500", and as a decimal otherwise
+func normalizeSeparator(value, separator string) string {
+	parts := strings.Split(value, separator)
+	thousands := len(parts) > 1
+	for _, part := range parts[1:] {
+		if len(part) != 3 {
+			thousands = false
+		}
+	}
+	if thousands {
+		return strings.Join(parts, "")
+	}
+	return strings.Replace(value, separator, ".", 1)
+}
+
+func firstCurrency(values ...string) string {
+	for _, value := range values {
+		if code, ok := currencyCodes[strings.ToLower(value)]; ok {
+			return code
+		}
+	}
+	return ""
+}
+
+// findPeriod returns the period mentioned first in text, or last when
+// searching backwards from a figure
+func findPeriod(text string, last bool) string {
+	period, best := "", -1
+	for _, p := range periodRegexes {
+		matches := p.regex.FindAllStringIndex(text, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		index := matches[0][0]
+		if last {
+			index = matches[len(matches)-1][0]
+		}
+		if best < 0 || (last && index > best) || (!last && index < best) {
+			period, best = p.period, index
+		}
+	}
+	return period
+}
+
+// inferPeriod guesses the period of a figure from its size: yacht day rates
+// are in the hundreds, monthly salaries in the thousands and annual ones
+// above that
+func inferPeriod(amount float64) string {
+	switch {
+	case amount < 1000:
+		return PeriodDay
+	case amount < 30000:
+		return PeriodMonth
+	}
+	return PeriodYear
+}
+
+// window returns line[start:end] clamped to the line
+func window(line string, start, end int) string {
+	if start < 0 {
+		start = 0
+	}
+	if end > len(line) {
+		end = len(line)
+	}
+	if start >= end {
+		return ""
+	}
+	return line[start:end]
+}
@@ -0,0 +1,103 @@
+package salary
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text       string
+		min, max   float64
+		currency   string
+		period     string
+		negotiable bool
+	}{
+		// EUR
+		{"€4,500 - 5,000/month DOE", 4500, 5000, "EUR", PeriodMonth, true},
+		{"4.5k EUR pm", 4500, 4500, "EUR", PeriodMonth, false},
+		{"Salary: 3500 EUR per month", 3500, 3500, "EUR", PeriodMonth, false},
+		{"Salary 3.500€ net monthly", 3500, 3500, "EUR", PeriodMonth, false},
+		{"€3,000-€3,500 pm", 3000, 3500, "EUR", PeriodMonth, false},
+		{"Rate: €300/day + flights", 300, 300, "EUR", PeriodDay, false},
+		{"€5k - €6k DOE", 5000, 6000, "EUR", PeriodMonth, true},
+		{"Pay: 4000 euros monthly", 4000, 4000, "EUR", PeriodMonth, false},
+		{"Delivery fee 5000 EUR for the trip", 5000, 5000, "EUR", PeriodTrip, false},
+		{"€1,200 per week", 1200, 1200, "EUR", PeriodWeek, false},
+		{"Salary: 4.000,50 EUR", 4000.5, 4000.5, "EUR", PeriodMonth, false},
+		{"Salary €3,500 + 13th month", 3500, 3500, "EUR", PeriodMonth, false},
+		{"Position: Chief Stew\nSalary: €5,000 - €5,500 per month\nStart: ASAP", 5000, 5500, "EUR", PeriodMonth, false},
+
+		// USD
+		{"$250/day", 250, 250, "USD", PeriodDay, false},
+		{"Day rate 250 USD", 250, 250, "USD", PeriodDay, false},
+		{"USD 8,000 - 9,000 per month", 8000, 9000, "USD", PeriodMonth, false},
+		{"Salary: $6000 - $7000/month DOE", 6000, 7000, "USD", PeriodMonth, true},
+		{"US$ 7,500/mo", 7500, 7500, "USD", PeriodMonth, false},
+		{"Chef 8-9k usd DOE", 8000, 9000, "USD", PeriodMonth, true},
+		{"$12,000 per month, 2:1 rotation", 12000, 12000, "USD", PeriodMonth, false},
+
+		// GBP
+		{"£45k p.a.", 45000, 45000, "GBP", PeriodYear, false},
+		{"£2,500 per month + tips", 2500, 2500, "GBP", PeriodMonth, false},
+		{"£180 a day", 180, 180, "GBP", PeriodDay, false},
+
+		// No currency, but a salary keyword
+		{"Salary 55-60k per annum", 55000, 60000, "", PeriodYear, false},
+		{"Salary 120k per annum", 120000, 120000, "", PeriodYear, false},
+
+		// Negotiable without a figure
+		{"salary negotiable", 0, 0, "", "", true},
+		{"Salary DOE", 0, 0, "", "", true},
+		{"Salary TBC", 0, 0, "", "", true},
+		{"Stew needed, salary to be discussed", 0, 0, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got := Parse(tt.text)
+			if got == nil {
+				t.Fatalf("Parse(%q) = nil", tt.text)
+			}
+			if got.Min != tt.min || got.Max != tt.max || got.Currency != tt.currency ||
+				got.Period != tt.period || got.Negotiable != tt.negotiable {
+				t.Errorf("Parse(%q) = %v-%v %q %q negotiable=%v, want %v-%v %q %q negotiable=%v",
+					tt.text, got.Min, got.Max, got.Currency, got.Period, got.Negotiable,
+					tt.min, tt.max, tt.currency, tt.period, tt.negotiable)
+			}
+			if got.HasAmount() != (tt.max > 0) {
+				t.Errorf("HasAmount() = %v", got.HasAmount())
+			}
+		})
+	}
+}
+
+func TestParseNoSalary(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"Yacht 45m, 6 crew, 10 guests",
+		"Looking for a deckhand on a 50m motor yacht",
+		"Rotation 10 weeks on 10 weeks off",
+		"Call +33 6 12 34 56 78",
+		"Negotiable start date, join us in Antibes",
+	} {
+		if got := Parse(text); got != nil {
+			t.Errorf("Parse(%q) = %+v, want nil", text, got)
+		}
+	}
+}
+
+func TestMonthlyFactor(t *testing.T) {
+	tests := []struct {
+		period string
+		want   float64
+	}{
+		{PeriodDay, 21.75},
+		{PeriodMonth, 1},
+		{PeriodYear, 1.0 / 12},
+		{PeriodTrip, 1},
+		{"fortnight", 1},
+	}
+	for _, tt := range tests {
+		if got := MonthlyFactor(tt.period); got != tt.want {
+			t.Errorf("MonthlyFactor(%q) = %v, want %v", tt.period, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,346 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ErrAlreadyRunning is returned when a task is triggered while it is running
+var ErrAlreadyRunning = errors.New("task is already running")
+
+// ErrUnknownTask is returned when a task name is not scheduled
+var ErrUnknownTask = errors.New("unknown task")
+
+// cronParser accepts standard 5 field expressions and descriptors such as
+// "@hourly" or "@every 6h"
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+type Config struct {
+	// DefaultSchedule applies to every task without its own schedule
+	DefaultSchedule string
+	// Schedules holds per-task overrides keyed by task name
+	Schedules map[string]string
+	// Jitter is the maximum random delay added to every scheduled run
+	Jitter time.Duration
+	// RunOnStart runs every task once as soon as the scheduler starts
+	RunOnStart bool
+}
+
+// NewConfig reads the scheduler configuration from the environment:
+//
+//	SCRAPE_SCHEDULE           default cron expression or interval (default "@every 6h")
+//	SCRAPE_SCHEDULE_<SOURCE>  override for one source, e.g. SCRAPE_SCHEDULE_TELEGRAM="0 */2 * * *"
+//	SCRAPE_JITTER             maximum random delay per run (default 5m)
+//	SCRAPE_RUN_ON_START       run every source once at boot (default true)
+func NewConfig() Config {
+	config := Config{
+		DefaultSchedule: "@every 6h",
+		Schedules:       make(map[string]string),
+		Jitter:          5 * time.Minute,
+		RunOnStart:      true,
+	}
+
+	if value := os.Getenv("SCRAPE_SCHEDULE"); value != "" {
+		config.DefaultSchedule = value
+	}
+	if value := os.Getenv("SCRAPE_JITTER"); value != "" {
+		if jitter, err := time.ParseDuration(value); err == nil {
+			config.Jitter = jitter
+		} else {
+			log.Printf("Invalid SCRAPE_JITTER %q: %v", value, err)
+		}
+	}
+	if value := os.Getenv("SCRAPE_RUN_ON_START"); value != "" {
+		if runOnStart, err := strconv.ParseBool(value); err == nil {
+			config.RunOnStart = runOnStart
+		}
+	}
+
+	for _, env := range os.Environ() {
+		key, value, _ := strings.Cut(env, "=")
+		if name, ok := strings.CutPrefix(key, "SCRAPE_SCHEDULE_"); ok && value != "" {
+			config.Schedules[name] = value
+		}
+	}
+
+	return config
+}
+
+// ScheduleFor returns the schedule configured for the named task
+func (c Config) ScheduleFor(name string) string {
+	key := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_", " ", "_").Replace(name))
+	if schedule, ok := c.Schedules[key]; ok {
+		return schedule
+	}
+	return c.DefaultSchedule
+}
+
+// TaskFunc is the work performed by a scheduled task
+type TaskFunc func(ctx context.Context) error
+
+// TaskStatus describes the state of a scheduled task
+type TaskStatus struct {
+	Name           string     `json:"name"`
+	Schedule       string     `json:"schedule"`
+	Running        bool       `json:"running"`
+	LastRunAt      *time.Time `json:"last_run_at,omitempty"`
+	LastFinishedAt *time.Time `json:"last_finished_at,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	NextRunAt      *time.Time `json:"next_run_at,omitempty"`
+	SkippedRuns    int        `json:"skipped_runs"`
+}
+
+type task struct {
+	name     string
+	spec     string
+	schedule cron.Schedule
+	run      TaskFunc
+
+	// Guarded by Scheduler.mu
+	running        bool
+	lastRunAt      time.Time
+	lastFinishedAt time.Time
+	lastError      string
+	nextRunAt      time.Time
+	skippedRuns    int
+}
+
+// Scheduler runs tasks on cron schedules without overlapping runs
+type Scheduler struct {
+	config Config
+
+	mu      sync.Mutex
+	tasks   map[string]*task
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	started bool
+}
+
+func New(config Config) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		config: config,
+		tasks:  make(map[string]*task),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Add schedules a task. The spec is a cron expression or descriptor; an
+// empty spec uses the configured schedule for the task name.
+func (s *Scheduler) Add(name, spec string, run TaskFunc) error {
+	if spec == "" {
+		spec = s.config.ScheduleFor(name)
+	}
+
+	schedule, err := cronParser.Parse(spec)
+	if err != nil {
+		return fmt.Errorf("invalid schedule %q for %s: %w", spec, name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.tasks[name]; exists {
+		return fmt.Errorf("task %q is already scheduled", name)
+	}
+
+	t := &task{
+		name:     name,
+		spec:     spec,
+		schedule: schedule,
+		run:      run,
+	}
+	s.tasks[name] = t
+
+	if s.started {
+		s.startTask(t)
+	}
+	return nil
+}
+
+// Start begins running every scheduled task
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+
+	for _, t := range s.tasks {
+		s.startTask(t)
+	}
+	log.Printf("Scheduler started with %d tasks", len(s.tasks))
+}
+
+// Stop cancels running tasks and waits for them to return
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Println("Scheduler stopped")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("scheduler did not stop in time: %w", ctx.Err())
+	}
+}
+
+// Trigger runs a task immediately in the background
+func (s *Scheduler) Trigger(name string) error {
+	s.mu.Lock()
+	t, ok := s.tasks[name]
+	if !ok {
+		s.mu.Unlock()
+		return ErrUnknownTask
+	}
+	if t.running {
+		s.mu.Unlock()
+		return ErrAlreadyRunning
+	}
+	t.running = true
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.execute(t)
+	}()
+	return nil
+}
+
+// Status returns the state of every task sorted by name
+func (s *Scheduler) Status() []TaskStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]TaskStatus, 0, len(s.tasks))
+	for _, t := range s.tasks {
+		statuses = append(statuses, TaskStatus{
+			Name:           t.name,
+			Schedule:       t.spec,
+			Running:        t.running,
+			LastRunAt:      timePtr(t.lastRunAt),
+			LastFinishedAt: timePtr(t.lastFinishedAt),
+			LastError:      t.lastError,
+			NextRunAt:      timePtr(t.nextRunAt),
+			SkippedRuns:    t.skippedRuns,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// startTask launches the loop for a task, s.mu must be held
+func (s *Scheduler) startTask(t *task) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.loop(t)
+	}()
+}
+
+// loop waits for each scheduled time of a task and runs it
+func (s *Scheduler) loop(t *task) {
+	next := time.Now()
+	if s.config.RunOnStart {
+		next = next.Add(s.jitter())
+	} else {
+		next = s.nextRun(t, next)
+	}
+
+	for {
+		s.mu.Lock()
+		t.nextRunAt = next
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.mu.Lock()
+		if t.running {
+			// A manually triggered run is still in progress
+			t.skippedRuns++
+			s.mu.Unlock()
+			log.Printf("Skipping scheduled run of %s: previous run still in progress", t.name)
+		} else {
+			t.running = true
+			s.mu.Unlock()
+			s.execute(t)
+		}
+
+		next = s.nextRun(t, time.Now())
+	}
+}
+
+// execute runs a task that has already been marked as running
+func (s *Scheduler) execute(t *task) {
+	start := time.Now()
+
+	s.mu.Lock()
+	t.lastRunAt = start
+	s.mu.Unlock()
+
+	err := t.run(s.ctx)
+
+	s.mu.Lock()
+	t.running = false
+	t.lastFinishedAt = time.Now()
+	t.lastError = ""
+	if err != nil {
+		t.lastError = err.Error()
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		log.Printf("Scheduled task %s failed after %s: %v", t.name, time.Since(start).Round(time.Second), err)
+	}
+}
+
+// nextRun returns the next scheduled time after from, including jitter
+func (s *Scheduler) nextRun(t *task, from time.Time) time.Time {
+	return t.schedule.Next(from).Add(s.jitter())
+}
+
+// jitter returns a random delay up to the configured jitter
+func (s *Scheduler) jitter() time.Duration {
+	if s.config.Jitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(s.config.Jitter)))
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
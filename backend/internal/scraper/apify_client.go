@@ -0,0 +1,358 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultApifyBaseURL is the public Apify API
+const DefaultApifyBaseURL = "https://api.apify.com/v2"
+
+// Apify run statuses
+const (
+	ApifyStatusReady     = "READY"
+	ApifyStatusRunning   = "RUNNING"
+	ApifyStatusSucceeded = "SUCCEEDED"
+	ApifyStatusFailed    = "FAILED"
+	ApifyStatusAborted   = "ABORTED"
+	ApifyStatusTimedOut  = "TIMED-OUT"
+)
+
+// apifyWebhookEvents are the run events that notify the webhook callback
+var apifyWebhookEvents = []string{
+	"ACTOR.RUN.SUCCEEDED",
+	"ACTOR.RUN.FAILED",
+	"ACTOR.RUN.ABORTED",
+	"ACTOR.RUN.TIMED_OUT",
+}
+
+// ApifyWebhookSecretHeader carries the shared secret on webhook callbacks
+const ApifyWebhookSecretHeader = "X-Apify-Webhook-Secret"
+
+type ApifyConfig struct {
+	BaseURL      string
+	Token        string
+	PageSize     int           // Dataset items fetched per request
+	PollInterval time.Duration // Delay between run status checks
+
+	// WebhookURL is the public URL of the run completion callback. Runs are
+	// polled instead when it or WebhookSecret is empty.
+	WebhookURL    string
+	WebhookSecret string
+}
+
+// WebhooksEnabled reports whether run completion is delivered by webhook
+func (c ApifyConfig) WebhooksEnabled() bool {
+	return c.WebhookURL != "" && c.WebhookSecret != ""
+}
+
+// NewApifyConfig reads the Apify configuration from APIFY_API_KEY,
+// APIFY_BASE_URL, APIFY_WEBHOOK_URL and APIFY_WEBHOOK_SECRET
+func NewApifyConfig() ApifyConfig {
+	baseURL := os.Getenv("APIFY_BASE_URL")
+	if baseURL == "" {
+		baseURL = DefaultApifyBaseURL
+	}
+
+	return ApifyConfig{
+		BaseURL:      baseURL,
+		Token:        os.Getenv("APIFY_API_KEY"),
+		PageSize:     1000,
+		PollInterval: 10 * time.Second,
+
+		WebhookURL:    os.Getenv("APIFY_WEBHOOK_URL"),
+		WebhookSecret: os.Getenv("APIFY_WEBHOOK_SECRET"),
+	}
+}
+
+// ApifyError is returned when the Apify API responds with an unexpected status code
+type ApifyError struct {
+	Op         string
+	StatusCode int
+	Body       string
+}
+
+func (e *ApifyError) Error() string {
+	if e.Body != "" {
+		return fmt.Sprintf("apify %s: HTTP %d: %s", e.Op, e.StatusCode, e.Body)
+	}
+	return fmt.Sprintf("apify %s: HTTP %d", e.Op, e.StatusCode)
+}
+
+// ApifyRunError is returned when an actor run finishes without succeeding
+type ApifyRunError struct {
+	RunID  string
+	Status string
+}
+
+func (e *ApifyRunError) Error() string {
+	return fmt.Sprintf("actor run %s: %s", e.RunID, e.Status)
+}
+
+// ApifyRun is the subset of an Apify actor run the scraper uses
+type ApifyRun struct {
+	ID               string `json:"id"`
+	ActID            string `json:"actId"`
+	Status           string `json:"status"`
+	DefaultDatasetID string `json:"defaultDatasetId"`
+}
+
+// Finished reports whether the run has reached a terminal status
+func (r *ApifyRun) Finished() bool {
+	switch r.Status {
+	case ApifyStatusSucceeded, ApifyStatusFailed, ApifyStatusAborted, ApifyStatusTimedOut:
+		return true
+	}
+	return false
+}
+
+// Err returns an ApifyRunError when the run finished without succeeding
+func (r *ApifyRun) Err() error {
+	if r.Finished() && r.Status != ApifyStatusSucceeded {
+		return &ApifyRunError{RunID: r.ID, Status: r.Status}
+	}
+	return nil
+}
+
+type apifyRunEnvelope struct {
+	Data ApifyRun `json:"data"`
+}
+
+// ApifyClient talks to the Apify actor and dataset APIs
+type ApifyClient struct {
+	baseURL      string
+	token        string
+	pageSize     int
+	pollInterval time.Duration
+	webhooks     string // Encoded ad-hoc webhook definition, empty when disabled
+	client       *http.Client
+}
+
+func NewApifyClient(config ApifyConfig) *ApifyClient {
+	if config.Token == "" {
+		log.Println("Warning: APIFY_API_KEY not set, yacht scraper will be disabled")
+	}
+	if config.PageSize <= 0 {
+		config.PageSize = 1000
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = 10 * time.Second
+	}
+
+	webhooks := ""
+	if config.WebhooksEnabled() {
+		encoded, err := encodeApifyWebhooks(config.WebhookURL, config.WebhookSecret)
+		if err != nil {
+			log.Printf("Warning: invalid Apify webhook config, falling back to polling: %v", err)
+		}
+		webhooks = encoded
+	}
+
+	return &ApifyClient{
+		baseURL:      strings.TrimRight(config.BaseURL, "/"),
+		token:        config.Token,
+		pageSize:     config.PageSize,
+		pollInterval: config.PollInterval,
+		webhooks:     webhooks,
+		client: &http.Client{
+			Transport: newRetryTransport(nil, DefaultRetryConfig),
+		},
+	}
+}
+
+// Enabled reports whether an API token is configured
+func (c *ApifyClient) Enabled() bool {
+	return c.token != ""
+}
+
+// WebhooksEnabled reports whether started runs register a completion webhook
+func (c *ApifyClient) WebhooksEnabled() bool {
+	return c.webhooks != ""
+}
+
+// StartActor starts a run of the actor with the given input. When webhooks
+// are configured the run notifies the callback URL once it finishes.
+func (c *ApifyClient) StartActor(ctx context.Context, actorID string, input interface{}) (*ApifyRun, error) {
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	if c.webhooks != "" {
+		query.Set("webhooks", c.webhooks)
+	}
+
+	path := fmt.Sprintf("/acts/%s/runs", url.PathEscape(actorID))
+	resp, err := c.do(ctx, "POST", path, query, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, newApifyError("start actor", resp)
+	}
+
+	var envelope apifyRunEnvelope
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("failed to decode actor run: %w", err)
+	}
+	return &envelope.Data, nil
+}
+
+// GetRun returns the current state of an actor run
+func (c *ApifyClient) GetRun(ctx context.Context, runID string) (*ApifyRun, error) {
+	resp, err := c.do(ctx, "GET", "/actor-runs/"+url.PathEscape(runID), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newApifyError("get run", resp)
+	}
+
+	var envelope apifyRunEnvelope
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("failed to decode actor run: %w", err)
+	}
+	return &envelope.Data, nil
+}
+
+// WaitForRun polls an actor run until it succeeds, fails or the timeout passes
+func (c *ApifyClient) WaitForRun(ctx context.Context, runID string, timeout time.Duration) (*ApifyRun, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timeout waiting for actor run %s: %w", runID, ctx.Err())
+		case <-ticker.C:
+		}
+
+		run, err := c.GetRun(ctx, runID)
+		if err != nil {
+			return nil, err
+		}
+
+		if run.Finished() {
+			return run, run.Err()
+		}
+	}
+}
+
+// GetDatasetItems returns every item in the default dataset of a run,
+// following pagination until the dataset is exhausted
+func (c *ApifyClient) GetDatasetItems(ctx context.Context, runID string) ([]json.RawMessage, error) {
+	var items []json.RawMessage
+
+	for offset := 0; ; {
+		query := url.Values{}
+		query.Set("offset", strconv.Itoa(offset))
+		query.Set("limit", strconv.Itoa(c.pageSize))
+
+		page, total, err := c.getDatasetPage(ctx, runID, query)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, page...)
+		offset += len(page)
+
+		if len(page) == 0 || len(page) < c.pageSize || (total >= 0 && offset >= total) {
+			return items, nil
+		}
+	}
+}
+
+// getDatasetPage fetches one page of dataset items and the total item count,
+// which is -1 when the API does not report it
+func (c *ApifyClient) getDatasetPage(ctx context.Context, runID string, query url.Values) ([]json.RawMessage, int, error) {
+	path := fmt.Sprintf("/actor-runs/%s/dataset/items", url.PathEscape(runID))
+	resp, err := c.do(ctx, "GET", path, query, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, newApifyError("get dataset items", resp)
+	}
+
+	var page []json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode dataset items: %w", err)
+	}
+
+	total := -1
+	if value := resp.Header.Get("X-Apify-Pagination-Total"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			total = parsed
+		}
+	}
+	return page, total, nil
+}
+
+func (c *ApifyClient) do(ctx context.Context, method, path string, query url.Values, body io.Reader) (*http.Response, error) {
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	return c.client.Do(req)
+}
+
+// encodeApifyWebhooks builds the base64 encoded ad-hoc webhook definition
+// passed when starting a run
+func encodeApifyWebhooks(requestURL, secret string) (string, error) {
+	headers, err := json.Marshal(map[string]string{ApifyWebhookSecretHeader: secret})
+	if err != nil {
+		return "", err
+	}
+
+	webhooks, err := json.Marshal([]map[string]interface{}{{
+		"eventTypes":      apifyWebhookEvents,
+		"requestUrl":      requestURL,
+		"headersTemplate": string(headers),
+	}})
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(webhooks), nil
+}
+
+// newApifyError builds an ApifyError including a truncated response body
+func newApifyError(op string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return &ApifyError{
+		Op:         op,
+		StatusCode: resp.StatusCode,
+		Body:       strings.TrimSpace(string(body)),
+	}
+}
@@ -0,0 +1,226 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/scraper/apifytest"
+)
+
+const testApifyToken = "test-token"
+
+func newTestApifyClient(t *testing.T, pageSize int) (*ApifyClient, *apifytest.Server) {
+	t.Helper()
+
+	server := apifytest.NewServer(testApifyToken)
+	t.Cleanup(server.Close)
+
+	client := NewApifyClient(ApifyConfig{
+		BaseURL:      server.URL,
+		Token:        testApifyToken,
+		PageSize:     pageSize,
+		PollInterval: 5 * time.Millisecond,
+	})
+	return client, server
+}
+
+func TestStartActor(t *testing.T) {
+	client, server := newTestApifyClient(t, 10)
+	server.SetActor("crew~posts", apifytest.Actor{})
+
+	run, err := client.StartActor(context.Background(), "crew~posts", map[string]interface{}{"maxPosts": 5})
+	if err != nil {
+		t.Fatalf("StartActor: %v", err)
+	}
+	if run.ID == "" || run.Status != ApifyStatusReady {
+		t.Fatalf("unexpected run %+v", run)
+	}
+
+	runs := server.Runs()
+	if len(runs) != 1 || runs[0].ActorID != "crew~posts" {
+		t.Fatalf("unexpected runs started: %+v", runs)
+	}
+	var input map[string]int
+	if err := json.Unmarshal(runs[0].Input, &input); err != nil || input["maxPosts"] != 5 {
+		t.Fatalf("actor input = %s, %v", runs[0].Input, err)
+	}
+}
+
+func TestStartActorErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		actor      *apifytest.Actor
+		token      string
+		wantStatus int
+	}{
+		{"payment required", &apifytest.Actor{StartStatus: http.StatusPaymentRequired}, testApifyToken, http.StatusPaymentRequired},
+		{"server error", &apifytest.Actor{StartStatus: http.StatusInternalServerError}, testApifyToken, http.StatusInternalServerError},
+		{"unknown actor", nil, testApifyToken, http.StatusNotFound},
+		{"bad token", &apifytest.Actor{}, "wrong-token", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := newTestApifyClient(t, 10)
+			client.token = tt.token
+			if tt.actor != nil {
+				server.SetActor("crew~posts", *tt.actor)
+			}
+
+			run, err := client.StartActor(context.Background(), "crew~posts", map[string]interface{}{})
+			if run != nil {
+				t.Errorf("expected no run, got %+v", run)
+			}
+			var apifyErr *ApifyError
+			if !errors.As(err, &apifyErr) {
+				t.Fatalf("expected *ApifyError, got %T: %v", err, err)
+			}
+			if apifyErr.StatusCode != tt.wantStatus || apifyErr.Op != "start actor" {
+				t.Errorf("got %+v, want status %d", apifyErr, tt.wantStatus)
+			}
+			if len(server.Runs()) != 0 {
+				t.Errorf("expected no runs to be recorded")
+			}
+		})
+	}
+}
+
+func TestWaitForRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []string
+		wantErr  bool
+	}{
+		{"succeeded", []string{ApifyStatusReady, ApifyStatusRunning, ApifyStatusSucceeded}, false},
+		{"failed", []string{ApifyStatusRunning, ApifyStatusFailed}, true},
+		{"aborted", []string{ApifyStatusRunning, ApifyStatusRunning, ApifyStatusAborted}, true},
+		{"timed out", []string{ApifyStatusTimedOut}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := newTestApifyClient(t, 10)
+			server.SetActor("crew~posts", apifytest.Actor{Statuses: tt.statuses})
+
+			started, err := client.StartActor(context.Background(), "crew~posts", map[string]interface{}{})
+			if err != nil {
+				t.Fatalf("StartActor: %v", err)
+			}
+
+			run, err := client.WaitForRun(context.Background(), started.ID, 5*time.Second)
+			want := tt.statuses[len(tt.statuses)-1]
+			if run == nil || run.Status != want {
+				t.Fatalf("run = %+v, want status %s", run, want)
+			}
+
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("WaitForRun: %v", err)
+				}
+				return
+			}
+			var runErr *ApifyRunError
+			if !errors.As(err, &runErr) {
+				t.Fatalf("expected *ApifyRunError, got %T: %v", err, err)
+			}
+			if runErr.RunID != started.ID || runErr.Status != want {
+				t.Errorf("got %+v, want run %s with status %s", runErr, started.ID, want)
+			}
+		})
+	}
+}
+
+func TestWaitForRunTimeout(t *testing.T) {
+	client, server := newTestApifyClient(t, 10)
+	server.SetActor("crew~posts", apifytest.Actor{Statuses: []string{ApifyStatusRunning}})
+
+	started, err := client.StartActor(context.Background(), "crew~posts", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("StartActor: %v", err)
+	}
+
+	_, err = client.WaitForRun(context.Background(), started.ID, 50*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestGetDatasetItems(t *testing.T) {
+	tests := []struct {
+		name      string
+		items     int
+		pageSize  int
+		omitTotal bool
+		wantPages int
+	}{
+		{"single page", 3, 10, false, 1},
+		{"partial last page", 5, 2, false, 3},
+		{"exact pages with total", 4, 2, false, 2},
+		{"partial last page without total", 5, 2, true, 3},
+		{"exact pages without total", 4, 2, true, 3},
+		{"empty dataset", 0, 2, false, 1},
+		{"empty dataset without total", 0, 2, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := newTestApifyClient(t, tt.pageSize)
+
+			items := make([]interface{}, tt.items)
+			for i := range items {
+				items[i] = map[string]int{"n": i}
+			}
+			server.SetActor("crew~posts", apifytest.Actor{Items: items, OmitTotal: tt.omitTotal})
+
+			run, err := client.StartActor(context.Background(), "crew~posts", map[string]interface{}{})
+			if err != nil {
+				t.Fatalf("StartActor: %v", err)
+			}
+
+			got, err := client.GetDatasetItems(context.Background(), run.ID)
+			if err != nil {
+				t.Fatalf("GetDatasetItems: %v", err)
+			}
+			if len(got) != tt.items {
+				t.Fatalf("got %d items, want %d", len(got), tt.items)
+			}
+			for i, raw := range got {
+				var item map[string]int
+				if err := json.Unmarshal(raw, &item); err != nil || item["n"] != i {
+					t.Fatalf("item %d = %s, want n=%d", i, raw, i)
+				}
+			}
+
+			limits := server.PageLimits()
+			if len(limits) != tt.wantPages {
+				t.Errorf("fetched %d pages, want %d", len(limits), tt.wantPages)
+			}
+			for _, limit := range limits {
+				if limit != tt.pageSize {
+					t.Errorf("requested limit %d, want %d", limit, tt.pageSize)
+				}
+			}
+		})
+	}
+}
+
+func TestGetDatasetItemsError(t *testing.T) {
+	client, server := newTestApifyClient(t, 10)
+	server.SetActor("crew~posts", apifytest.Actor{Items: []interface{}{"a"}})
+	server.FailRequests("/dataset/items", http.StatusForbidden)
+
+	run, err := client.StartActor(context.Background(), "crew~posts", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("StartActor: %v", err)
+	}
+
+	_, err = client.GetDatasetItems(context.Background(), run.ID)
+	var apifyErr *ApifyError
+	if !errors.As(err, &apifyErr) || apifyErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 *ApifyError, got %v", err)
+	}
+}
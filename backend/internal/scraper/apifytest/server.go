@@ -0,0 +1,250 @@
+// Package apifytest provides an in-memory fake of the Apify actor and dataset
+// APIs for exercising the scraper offline.
+package apifytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Actor configures how the fake server responds for one actor
+type Actor struct {
+	// StartStatus is the HTTP status returned when starting a run (default 201)
+	StartStatus int
+	// Statuses are returned by successive run status requests; the last one
+	// repeats (default ["SUCCEEDED"])
+	Statuses []string
+	// Items are the dataset items returned for every run of the actor
+	Items []interface{}
+	// OmitTotal leaves out the X-Apify-Pagination-Total header on dataset pages
+	OmitTotal bool
+}
+
+// Run records a run started on the fake server
+type Run struct {
+	ID      string
+	ActorID string
+	Input   json.RawMessage
+	Query   map[string][]string
+
+	polls int
+}
+
+// Server is a fake Apify API. Its URL can be used as the Apify base URL.
+type Server struct {
+	*httptest.Server
+
+	// Token is the bearer token every request must carry
+	Token string
+
+	mu          sync.Mutex
+	actors      map[string]Actor
+	runs        map[string]*Run
+	runOrder    []string
+	statusCodes map[string]int
+	pageLimits  []int
+}
+
+// NewServer starts a fake Apify server that accepts the given token
+func NewServer(token string) *Server {
+	s := &Server{
+		Token:       token,
+		actors:      make(map[string]Actor),
+		runs:        make(map[string]*Run),
+		statusCodes: make(map[string]int),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/acts/", s.handleStartRun)
+	mux.HandleFunc("/actor-runs/", s.handleActorRun)
+	s.Server = httptest.NewServer(s.authenticate(mux))
+	return s
+}
+
+// SetActor configures the behaviour of an actor
+func (s *Server) SetActor(actorID string, actor Actor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.actors[actorID] = actor
+}
+
+// FailRequests makes every request whose path ends with suffix return the
+// given HTTP status, e.g. FailRequests("/dataset/items", 500)
+func (s *Server) FailRequests(suffix string, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statusCodes[suffix] = status
+}
+
+// Runs returns every run started so far in start order
+func (s *Server) Runs() []Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs := make([]Run, 0, len(s.runOrder))
+	for _, id := range s.runOrder {
+		runs = append(runs, *s.runs[id])
+	}
+	return runs
+}
+
+// PageLimits returns the limit requested by every dataset page request
+func (s *Server) PageLimits() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int(nil), s.pageLimits...)
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+s.Token {
+			writeError(w, http.StatusUnauthorized, "user-or-token-not-found")
+			return
+		}
+
+		s.mu.Lock()
+		for suffix, status := range s.statusCodes {
+			if strings.HasSuffix(r.URL.Path, suffix) {
+				s.mu.Unlock()
+				writeError(w, status, "fake-failure")
+				return
+			}
+		}
+		s.mu.Unlock()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleStartRun serves POST /acts/{actorId}/runs
+func (s *Server) handleStartRun(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/acts/"), "/runs")
+	if !ok || r.Method != http.MethodPost {
+		writeError(w, http.StatusNotFound, "page-not-found")
+		return
+	}
+
+	var input json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid-input")
+		return
+	}
+
+	s.mu.Lock()
+	actor, exists := s.actors[actorID]
+	if !exists {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "record-not-found")
+		return
+	}
+	if actor.StartStatus != 0 && actor.StartStatus != http.StatusCreated {
+		s.mu.Unlock()
+		writeError(w, actor.StartStatus, "run-failed-to-start")
+		return
+	}
+
+	run := &Run{
+		ID:      fmt.Sprintf("run-%d", len(s.runOrder)+1),
+		ActorID: actorID,
+		Input:   input,
+		Query:   r.URL.Query(),
+	}
+	s.runs[run.ID] = run
+	s.runOrder = append(s.runOrder, run.ID)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, runEnvelope(run, "READY"))
+}
+
+// handleActorRun serves GET /actor-runs/{runId} and /actor-runs/{runId}/dataset/items
+func (s *Server) handleActorRun(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/actor-runs/")
+	runID, rest, _ := strings.Cut(path, "/")
+
+	s.mu.Lock()
+	run, exists := s.runs[runID]
+	if !exists {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "record-not-found")
+		return
+	}
+	actor := s.actors[run.ActorID]
+
+	switch rest {
+	case "":
+		status := "SUCCEEDED"
+		if len(actor.Statuses) > 0 {
+			index := run.polls
+			if index >= len(actor.Statuses) {
+				index = len(actor.Statuses) - 1
+			}
+			status = actor.Statuses[index]
+		}
+		run.polls++
+		s.mu.Unlock()
+
+		writeJSON(w, http.StatusOK, runEnvelope(run, status))
+
+	case "dataset/items":
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		s.pageLimits = append(s.pageLimits, limit)
+		s.mu.Unlock()
+
+		items := actor.Items
+		if offset > len(items) {
+			offset = len(items)
+		}
+		end := len(items)
+		if limit > 0 && offset+limit < end {
+			end = offset + limit
+		}
+		page := items[offset:end]
+		if page == nil {
+			page = []interface{}{}
+		}
+
+		if !actor.OmitTotal {
+			w.Header().Set("X-Apify-Pagination-Total", strconv.Itoa(len(items)))
+		}
+		w.Header().Set("X-Apify-Pagination-Offset", strconv.Itoa(offset))
+		w.Header().Set("X-Apify-Pagination-Count", strconv.Itoa(len(page)))
+		w.Header().Set("X-Apify-Pagination-Limit", strconv.Itoa(limit))
+		writeJSON(w, http.StatusOK, page)
+
+	default:
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "page-not-found")
+	}
+}
+
+func runEnvelope(run *Run, status string) map[string]interface{} {
+	return map[string]interface{}{
+		"data": map[string]interface{}{
+			"id":               run.ID,
+			"actId":            run.ActorID,
+			"status":           status,
+			"defaultDatasetId": "dataset-" + run.ID,
+		},
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, errorType string) {
+	writeJSON(w, status, map[string]interface{}{
+		"error": map[string]string{
+			"type":    errorType,
+			"message": http.StatusText(status),
+		},
+	})
+}
@@ -0,0 +1,260 @@
+package scraper
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/geo"
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/language"
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/models"
+)
+
+var (
+	// crewAvailable matches how crew advertise themselves, as in "deckhand
+	// available from May" or "looking for a new position"
+	crewAvailable = regexp.MustCompile(`(?i)\b(?:available|availability|open\s+to\s+(?:new\s+)?(?:offers|opportunities|positions)|(?:looking|searching|seeking)\s+(?:for\s+)?(?:a\s+|an\s+|my\s+next\s+|new\s+|a\s+new\s+)?(?:position|job|role|work|opportunit(?:y|ies)|season|contract|placement)|disponibles?|disponibile|disponibilit|busco\s+(?:trabajo|empleo)|cerco\s+lavoro|recherche\s+(?:un\s+)?(?:poste|emploi|embarquement))\b`)
+	// hiringPhrase matches the wording of a job post, which a crew post never uses
+	hiringPhrase = regexp.MustCompile(`(?i)\b(?:needed|wanted|hiring|recruiting|vacanc(?:y|ies)|(?:position|job|role)s?\s+(?:is\s+|are\s+)?available|we\s+(?:are|'re)\s+(?:looking|seeking|hiring|recruiting)|(?:is|are)\s+(?:looking|seeking)\s+for\s+an?\b|send\s+(?:your\s+|us\s+your\s+)?(?:cv|resume)|apply\s+(?:now|via|at|by|with)|join\s+(?:our|the)\s+(?:team|crew)|cherchons|recherchons|cerchiamo|cercasi|buscamos|se\s+busca)\b`)
+	// roleNoun matches what follows a position a crew member looks for, as in
+	// "seeking a deckhand position"
+	roleNoun = regexp.MustCompile(`(?i)^\s*(?:position|job|role|work)\b`)
+	// availabilityAnchor matches what introduces the date a crew member is
+	// available from
+	availabilityAnchor = regexp.MustCompile(`(?i)(?:\b(?:available|availability|disponibles?|disponibile|from|starting|start\s+date|dès|dal|desde)\b|\bdisponibilit(?:à|a\b)|(?:^|[^\p{L}])à\s+partir\s+d[ue]\b)[\s:,-]*(?:(?:on|the|le|el|il|from|du|de|del|the\s+start\s+of|early|mid|à\s+partir\s+d[ue])\s+)*`)
+	// currentlyAvailable matches "available now", "immediately available" and the like
+	currentlyAvailable = regexp.MustCompile(`(?i)\b(?:(?:currently|immediately)\s+available|available\s+(?:now|immediately|asap|right\s+away|today)|disponibles?\s+imm[ée]diatement|disponibile\s+subito|disponible\s+(?:de\s+)?inmediat[oa](?:mente)?)\b`)
+	dayMonth           = regexp.MustCompile(`(?i)^(\d{1,2})(?:st|nd|rd|th|er|º)?\s+(?:of\s+|de\s+)?(` + monthPattern + `)\b(?:,?\s+(\d{4}))?`)
+	monthDay           = regexp.MustCompile(`(?i)^(` + monthPattern + `)\s+(\d{1,2})(?:st|nd|rd|th)?\b(?:,?\s+(\d{4}))?`)
+	monthOnly          = regexp.MustCompile(`(?i)^(` + monthPattern + `)\b(?:\s+(\d{4}))?`)
+	numericDate        = regexp.MustCompile(`^(\d{1,2})[/.](\d{1,2})(?:[/.](\d{2}|\d{4}))?\b`)
+	// durationUnit follows numbers that are not dates, as in "3/4 years"
+	durationUnit = regexp.MustCompile(`(?i)^\s*(?:years?|yrs?|months?|weeks?|days?|seasons?)\b`)
+)
+
+// months maps English, French, Italian and Spanish month names to months
+var months = map[string]time.Month{
+	"january": time.January, "jan": time.January, "janvier": time.January, "gennaio": time.January, "enero": time.January,
+	"february": time.February, "feb": time.February, "février": time.February, "fevrier": time.February, "febbraio": time.February, "febrero": time.February,
+	"march": time.March, "mar": time.March, "mars": time.March, "marzo": time.March,
+	"april": time.April, "apr": time.April, "avril": time.April, "aprile": time.April, "abril": time.April,
+	"may": time.May, "mai": time.May, "maggio": time.May, "mayo": time.May,
+	"june": time.June, "jun": time.June, "juin": time.June, "giugno": time.June, "junio": time.June,
+	"july": time.July, "jul": time.July, "juillet": time.July, "luglio": time.July, "julio": time.July,
+	"august": time.August, "aug": time.August, "août": time.August, "aout": time.August, "agosto": time.August,
+	"september": time.September, "sep": time.September, "sept": time.September, "septembre": time.September, "settembre": time.September, "septiembre": time.September,
+	"october": time.October, "oct": time.October, "octobre": time.October, "ottobre": time.October, "octubre": time.October,
+	"november": time.November, "nov": time.November, "novembre": time.November, "noviembre": time.November,
+	"december": time.December, "dec": time.December, "décembre": time.December, "decembre": time.December, "dicembre": time.December, "diciembre": time.December,
+}
+
+// monthPattern matches every month name, longest first so "sept" wins over "sep"
+var monthPattern = func() string {
+	names := make([]string, 0, len(months))
+	for name := range months {
+		names = append(names, regexp.QuoteMeta(name))
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) > len(names[j])
+		}
+		return names[i] < names[j]
+	})
+	return strings.Join(names, "|")
+}()
+
+// certification is a certificate, licence or visa crew list in their posts
+type certification struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// certifications are listed with their usual spellings. A name that prefixes
+// a more specific one found in the same post, like "Yachtmaster" for
+// "Yachtmaster Offshore", is dropped.
+var certifications = []certification{
+	{"STCW", regexp.MustCompile(`(?i)\bstcw\b`)},
+	{"ENG1", regexp.MustCompile(`(?i)\beng\s?1\b|\bml5\b`)},
+	{"B1/B2", regexp.MustCompile(`(?i)\bb1\s*/?\s*b2\b`)},
+	{"C1/D", regexp.MustCompile(`(?i)\bc1\s*/\s*d\b`)},
+	{"Schengen visa", regexp.MustCompile(`(?i)\bschengen\b`)},
+	{"Yachtmaster", regexp.MustCompile(`(?i)\byacht\s?master\b`)},
+	{"Yachtmaster Coastal", regexp.MustCompile(`(?i)\byacht\s?master\s+coastal\b`)},
+	{"Yachtmaster Offshore", regexp.MustCompile(`(?i)\byacht\s?master\s+offshore\b`)},
+	{"Yachtmaster Ocean", regexp.MustCompile(`(?i)\byacht\s?master\s+ocean\b`)},
+	{"Powerboat Level 2", regexp.MustCompile(`(?i)\bpbl?2\b|\bpower\s?boat\s+(?:level\s+)?2\b`)},
+	{"AEC", regexp.MustCompile(`(?i)\baec\b`)},
+	{"OOW", regexp.MustCompile(`(?i)\boow\b|\bofficer\s+of\s+the\s+watch\b`)},
+	{"EOOW", regexp.MustCompile(`(?i)\beoow\b`)},
+	{"Master 200GT", regexp.MustCompile(`(?i)\bmaster\s*200\s*(?:gt)?\b`)},
+	{"Master 500GT", regexp.MustCompile(`(?i)\bmaster\s*500\s*(?:gt)?\b`)},
+	{"Master 3000GT", regexp.MustCompile(`(?i)\bmaster\s*3000\s*(?:gt)?\b`)},
+	{"Y4", regexp.MustCompile(`(?i)\by4\b`)},
+	{"Y3", regexp.MustCompile(`(?i)\by3\b`)},
+	{"Y2", regexp.MustCompile(`(?i)\by2\b`)},
+	{"Y1", regexp.MustCompile(`(?i)\by1\b`)},
+	{"GMDSS", regexp.MustCompile(`(?i)\bgmdss\b`)},
+	{"VHF/SRC", regexp.MustCompile(`(?i)\bvhf\b|\bsrc\b`)},
+	{"PDSD", regexp.MustCompile(`(?i)\bpdsd\b`)},
+	{"Food Hygiene", regexp.MustCompile(`(?i)\bfood\s+(?:safety|hygiene)\b|\bhygiene\s+level\s*2\b`)},
+	{"Ship's Cook Certificate", regexp.MustCompile(`(?i)\bship'?s\s+cook\b`)},
+	{"PADI Divemaster", regexp.MustCompile(`(?i)\bdive\s?master\b`)},
+	{"Dive Instructor", regexp.MustCompile(`(?i)\b(?:padi|dive|diving)\s+instructor\b`)},
+	{"WSET", regexp.MustCompile(`(?i)\bwset\b`)},
+}
+
+// isCrewAvailability reports whether a post is a crew member advertising
+// their availability, naming the position they look for, rather than a job
+func isCrewAvailability(extractor *Extractor, text string) bool {
+	if !crewAvailable.MatchString(text) || hiringPhrase.MatchString(text) {
+		return false
+	}
+
+	mentions := extractor.FieldMatches(FieldTitle, text)
+	if len(mentions) == 0 {
+		return false
+	}
+	// "Looking for a deckhand" is a vacancy, unlike "looking for a deckhand position"
+	for _, mention := range mentions {
+		if listIntro.MatchString(text[sentenceStart(text, mention.Start):mention.Start]) &&
+			!roleNoun.MatchString(text[mention.End:]) {
+			return false
+		}
+	}
+	return true
+}
+
+// convertCrewPost parses the position, availability, certifications and
+// location of a crew availability post
+func convertCrewPost(extractor *Extractor, post ScrapedPost, lang string) *models.CrewAvailabilityPost {
+	crewPost := &models.CrewAvailabilityPost{
+		URL:            post.URL,
+		GroupName:      post.GroupName,
+		ChannelName:    post.ChannelName,
+		Text:           post.Text,
+		Position:       extractor.Field(FieldTitle, post.Text),
+		Certifications: postCertifications(post.Text),
+		Language:       lang,
+	}
+	if !post.Timestamp.IsZero() {
+		postedAt := post.Timestamp
+		crewPost.PostedAt = &postedAt
+	}
+
+	if from, wording := availableFrom(post.Text, post.Timestamp); from != nil {
+		crewPost.AvailableFrom = from
+		crewPost.Availability = wording
+	}
+
+	if location := geo.Resolve(language.Normalize(post.Text, lang)); location != nil {
+		crewPost.Location = location.String()
+		crewPost.CountryCode = location.Country
+		crewPost.Region = location.Region
+	}
+	return crewPost
+}
+
+// availableFrom returns the date a crew member is available from and how the
+// post words it, or nil when the post does not say. Dates without a year are
+// the next such date after the post, and "available now" is the post date.
+func availableFrom(text string, posted time.Time) (*time.Time, string) {
+	if posted.IsZero() {
+		posted = time.Now()
+	}
+	today := time.Date(posted.Year(), posted.Month(), posted.Day(), 0, 0, 0, 0, time.UTC)
+
+	if match := currentlyAvailable.FindString(text); match != "" {
+		return &today, match
+	}
+
+	for _, loc := range availabilityAnchor.FindAllStringIndex(text, -1) {
+		date, length := parseDate(text[loc[1]:], today)
+		if date != nil {
+			return date, strings.TrimSpace(text[loc[0] : loc[1]+length])
+		}
+	}
+	return nil, ""
+}
+
+// parseDate parses the date text starts with, returning it and its length
+func parseDate(text string, today time.Time) (*time.Time, int) {
+	var day, year int
+	var month time.Month
+	var length int
+
+	if m := dayMonth.FindStringSubmatch(text); m != nil {
+		day, _ = strconv.Atoi(m[1])
+		month, year, length = months[strings.ToLower(m[2])], atoi(m[3]), len(m[0])
+	} else if m := monthDay.FindStringSubmatch(text); m != nil {
+		day, _ = strconv.Atoi(m[2])
+		month, year, length = months[strings.ToLower(m[1])], atoi(m[3]), len(m[0])
+	} else if m := monthOnly.FindStringSubmatch(text); m != nil {
+		day, month, year, length = 1, months[strings.ToLower(m[1])], atoi(m[2]), len(m[0])
+	} else if m := numericDate.FindStringSubmatch(text); m != nil && !durationUnit.MatchString(text[len(m[0]):]) {
+		// Crew posts use European dates, day first
+		day, _ = strconv.Atoi(m[1])
+		monthNumber, _ := strconv.Atoi(m[2])
+		month, year, length = time.Month(monthNumber), atoi(m[3]), len(m[0])
+		if year > 0 && year < 100 {
+			year += 2000
+		}
+	} else {
+		return nil, 0
+	}
+
+	if month < time.January || month > time.December || day < 1 || day > 31 {
+		return nil, 0
+	}
+	if year == 0 {
+		year = today.Year()
+		// A month long gone this year means next year, as in "from March" posted in October
+		if time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Before(today.AddDate(0, -1, 0)) {
+			year++
+		}
+	}
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	if date.Month() != month {
+		return nil, 0
+	}
+	return &date, length
+}
+
+// postCertifications returns the certificates, licences and visas a post lists
+func postCertifications(text string) []string {
+	found := []string{}
+	for _, cert := range certifications {
+		if cert.pattern.MatchString(text) {
+			found = append(found, cert.name)
+		}
+	}
+
+	// Keep the most specific names only
+	specific := []string{}
+	for _, name := range found {
+		general := false
+		for _, other := range found {
+			if other != name && strings.HasPrefix(other, name+" ") {
+				general = true
+				break
+			}
+		}
+		if !general {
+			specific = append(specific, name)
+		}
+	}
+	return specific
+}
+
+// sentenceStart returns the offset of the start of the sentence or line
+// containing offset i
+func sentenceStart(text string, i int) int {
+	return strings.LastIndexAny(text[:i], ".!?\n") + 1
+}
+
+// atoi parses an optional number, returning 0 when it is empty
+func atoi(value string) int {
+	n, _ := strconv.Atoi(value)
+	return n
+}
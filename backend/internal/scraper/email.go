@@ -0,0 +1,270 @@
+package scraper
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"path"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+// maxEmailPartSize bounds the decoded size of a single body or attachment
+const maxEmailPartSize = 20 << 20
+
+// maxEmailDepth bounds the nesting of multipart bodies and forwarded messages
+const maxEmailDepth = 10
+
+const docxMediaType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+
+// emailMessage is an email reduced to what jobs are extracted from
+type emailMessage struct {
+	Subject string
+	From    string // Display name of the sender, or the address when it has none
+	Address string // Address of the sender
+	Date    time.Time
+	Text    string // Bodies and readable attachments as plain text
+}
+
+// headerGetter is implemented by both mail.Header and textproto.MIMEHeader
+type headerGetter interface {
+	Get(key string) string
+}
+
+var headerDecoder = &mime.WordDecoder{CharsetReader: charset.NewReaderLabel}
+
+// parseEmail parses an RFC 5322 message and converts its text bodies and
+// attachments to plain text
+func parseEmail(r io.Reader) (*emailMessage, error) {
+	return parseEmailDepth(r, 0)
+}
+
+func parseEmailDepth(r io.Reader, depth int) (*emailMessage, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse email: %w", err)
+	}
+
+	email := &emailMessage{
+		Subject: decodeHeader(msg.Header.Get("Subject")),
+	}
+	if from, err := mail.ParseAddress(decodeHeader(msg.Header.Get("From"))); err == nil {
+		email.Address = strings.ToLower(from.Address)
+		email.From = from.Name
+		if email.From == "" {
+			email.From = email.Address
+		}
+	}
+	if date, err := msg.Header.Date(); err == nil {
+		email.Date = date
+	}
+
+	email.Text = strings.Join(partTexts(msg.Header, msg.Body, depth), "\n\n")
+	return email, nil
+}
+
+// partTexts returns the plain text of a MIME entity. Alternatives yield their
+// plain text version, or the HTML one when there is none; other multiparts
+// yield the text of every part.
+func partTexts(header headerGetter, body io.Reader, depth int) []string {
+	if depth > maxEmailDepth {
+		return nil
+	}
+
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+	body = transferDecoder(header.Get("Content-Transfer-Encoding"), body)
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		var texts, plain, rich []string
+		for {
+			part, err := reader.NextRawPart()
+			if err != nil {
+				break
+			}
+			partText := partTexts(part.Header, part, depth+1)
+			if mediaType != "multipart/alternative" {
+				texts = append(texts, partText...)
+				continue
+			}
+			if len(partText) == 0 {
+				continue
+			}
+
+			// Alternatives are ordered from plainest to richest
+			partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+			if (partType == "" || partType == "text/plain") && plain == nil {
+				plain = partText
+			} else {
+				rich = partText
+			}
+		}
+		if mediaType != "multipart/alternative" {
+			return texts
+		}
+		if plain != nil {
+			return plain
+		}
+		return rich
+	}
+
+	if mediaType == "message/rfc822" {
+		forwarded, err := parseEmailDepth(body, depth+1)
+		if err != nil {
+			return nil
+		}
+		return nonEmpty(forwarded.Subject, forwarded.Text)
+	}
+
+	filename := attachmentName(header, params)
+	if mediaType == "application/octet-stream" && filename != "" {
+		mediaType = mime.TypeByExtension(strings.ToLower(path.Ext(filename)))
+		mediaType, _, _ = mime.ParseMediaType(mediaType)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, maxEmailPartSize))
+	if err != nil {
+		log.Printf("Error reading email part %s: %v", mediaType, err)
+		return nil
+	}
+
+	switch mediaType {
+	case "text/plain":
+		return nonEmpty(strings.TrimSpace(decodeCharset(data, params["charset"])))
+	case "text/html":
+		return nonEmpty(htmlText(decodeCharset(data, params["charset"])))
+	case docxMediaType:
+		text, err := docxText(data)
+		if err != nil {
+			log.Printf("Error reading attachment %s: %v", filename, err)
+			return nil
+		}
+		return nonEmpty(text)
+	}
+
+	if filename != "" {
+		log.Printf("Skipping email attachment %s (%s)", filename, mediaType)
+	}
+	return nil
+}
+
+// transferDecoder decodes a base64 or quoted-printable body
+func transferDecoder(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	}
+	return body
+}
+
+// decodeCharset converts text in the given charset to UTF-8
+func decodeCharset(data []byte, label string) string {
+	if label == "" || strings.EqualFold(label, "utf-8") || strings.EqualFold(label, "us-ascii") {
+		return string(data)
+	}
+	reader, err := charset.NewReaderLabel(label, bytes.NewReader(data))
+	if err != nil {
+		return string(data)
+	}
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		return string(data)
+	}
+	return string(decoded)
+}
+
+// decodeHeader decodes the RFC 2047 encoded words of a header
+func decodeHeader(value string) string {
+	decoded, err := headerDecoder.DecodeHeader(value)
+	if err != nil {
+		return strings.TrimSpace(value)
+	}
+	return strings.TrimSpace(decoded)
+}
+
+// attachmentName returns the file name of a MIME part, if it has one
+func attachmentName(header headerGetter, params map[string]string) string {
+	if _, dispositionParams, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil {
+		if name := dispositionParams["filename"]; name != "" {
+			return decodeHeader(name)
+		}
+	}
+	return decodeHeader(params["name"])
+}
+
+// docxText returns the paragraphs of a Word document as plain text
+func docxText(data []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("invalid docx file: %w", err)
+	}
+
+	document, err := archive.Open("word/document.xml")
+	if err != nil {
+		return "", fmt.Errorf("invalid docx file: %w", err)
+	}
+	defer document.Close()
+
+	var b strings.Builder
+	decoder := xml.NewDecoder(io.LimitReader(document, maxEmailPartSize))
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("invalid docx file: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				b.WriteString("\t")
+			case "br":
+				b.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				b.WriteString("\n")
+			}
+		case xml.CharData:
+			if inText {
+				b.Write(t)
+			}
+		}
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// nonEmpty returns the values that are not blank
+func nonEmpty(values ...string) []string {
+	var result []string
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
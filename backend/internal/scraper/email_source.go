@@ -0,0 +1,498 @@
+package scraper
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/models"
+)
+
+// emailSourceName is the scraper source reading vacancy emails
+const emailSourceName = "email"
+
+// EmailConfig configures where vacancy emails are read from and the optional
+// SMTP listener that receives them
+type EmailConfig struct {
+	// Dir is a Maildir, or a directory of mbox files
+	Dir string
+	// SMTPAddr is the address the SMTP listener binds to, empty to disable it
+	SMTPAddr string
+	// Recipients are the addresses the SMTP listener accepts mail for
+	Recipients []string
+	// AllowedSenders restricts the senders accepted by the SMTP listener to
+	// these addresses or "@domain" suffixes; empty accepts any sender
+	AllowedSenders []string
+	// MaxMessageSize bounds the messages accepted by the SMTP listener
+	MaxMessageSize int64
+}
+
+// NewEmailConfig reads the email settings from SCRAPER_EMAIL_DIR,
+// SCRAPER_SMTP_ADDR, SCRAPER_SMTP_RECIPIENTS and SCRAPER_SMTP_ALLOWED_SENDERS
+func NewEmailConfig() EmailConfig {
+	config := EmailConfig{
+		Dir:            strings.TrimSpace(os.Getenv("SCRAPER_EMAIL_DIR")),
+		SMTPAddr:       strings.TrimSpace(os.Getenv("SCRAPER_SMTP_ADDR")),
+		Recipients:     splitAddresses(os.Getenv("SCRAPER_SMTP_RECIPIENTS")),
+		AllowedSenders: splitAddresses(os.Getenv("SCRAPER_SMTP_ALLOWED_SENDERS")),
+		MaxMessageSize: 25 << 20,
+	}
+
+	if config.SMTPAddr != "" && (config.Dir == "" || len(config.Recipients) == 0) {
+		log.Println("SCRAPER_SMTP_ADDR needs SCRAPER_EMAIL_DIR and SCRAPER_SMTP_RECIPIENTS, SMTP listener disabled")
+		config.SMTPAddr = ""
+	}
+	return config
+}
+
+// splitAddresses splits a comma separated list of email addresses
+func splitAddresses(value string) []string {
+	var addresses []string
+	for _, address := range strings.Split(value, ",") {
+		if address = strings.ToLower(strings.TrimSpace(address)); address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
+
+// EmailSource extracts jobs from the vacancy emails crew agencies send us,
+// read from a Maildir or mbox files
+type EmailSource struct {
+	yacht  *YachtScraperService
+	config EmailConfig
+}
+
+func NewEmailSource(yacht *YachtScraperService, config EmailConfig) *EmailSource {
+	return &EmailSource{yacht: yacht, config: config}
+}
+
+func (s *EmailSource) Name() string {
+	return emailSourceName
+}
+
+// Fetch reads the emails that arrived since the last run. Once their jobs are
+// saved, Maildir messages are moved from new to cur and mbox cursors advance.
+func (s *EmailSource) Fetch(ctx context.Context) ([]Item, error) {
+	if s.config.Dir == "" {
+		log.Println("Skipping email ingestion - no SCRAPER_EMAIL_DIR provided")
+		return nil, nil
+	}
+
+	var emails []readEmail
+	var commit func(failed map[string]bool)
+	var err error
+	if isMaildir(s.config.Dir) {
+		emails, commit, err = s.readMaildir(ctx)
+	} else {
+		emails, commit, err = s.readMboxes(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var posts []ScrapedPost
+	var origins []string
+	for _, email := range emails {
+		for _, post := range emailPosts(email.message) {
+			posts = append(posts, post)
+			origins = append(origins, email.origin)
+		}
+	}
+	items := postItems(posts)
+
+	originOf := make(map[*ScrapedPost]string, len(items))
+	for i, item := range items {
+		originOf[item.Post] = origins[i]
+	}
+	deferCommit(ctx, func(failed []Item) {
+		failedOrigins := make(map[string]bool)
+		for _, item := range failed {
+			if origin, ok := originOf[item.Post]; ok {
+				failedOrigins[origin] = true
+			}
+		}
+		commit(failedOrigins)
+	})
+
+	log.Printf("📧 Read %d vacancies from %d emails", len(posts), len(emails))
+	return items, nil
+}
+
+func (s *EmailSource) Parse(item Item) []*models.Job {
+	jobs := s.yacht.parsePost(item)
+	for _, job := range jobs {
+		job.Source = item.Post.GroupName
+	}
+	return jobs
+}
+
+// isMaildir reports whether dir is a Maildir rather than a directory of mbox files
+func isMaildir(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, "new"))
+	return err == nil && info.IsDir()
+}
+
+// readEmail is an email and the Maildir message or mbox file it was read from
+type readEmail struct {
+	message *emailMessage
+	origin  string
+}
+
+// readMaildir reads the new messages of the Maildir. The returned commit moves
+// them to cur, marked as seen, except for the origins that failed.
+func (s *EmailSource) readMaildir(ctx context.Context) ([]readEmail, func(map[string]bool), error) {
+	newDir := filepath.Join(s.config.Dir, "new")
+	entries, err := os.ReadDir(newDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read Maildir: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Join(s.config.Dir, "cur"), 0o755); err != nil {
+		return nil, nil, fmt.Errorf("failed to read Maildir: %w", err)
+	}
+
+	var emails []readEmail
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		email, err := readEmailFile(filepath.Join(newDir, entry.Name()))
+		if err != nil {
+			// Unreadable messages are moved right away, so they are not retried forever
+			log.Printf("Error reading email %s: %v", entry.Name(), err)
+			s.markRead(entry.Name())
+			continue
+		}
+		emails = append(emails, readEmail{message: email, origin: entry.Name()})
+	}
+
+	commit := func(failed map[string]bool) {
+		for _, email := range emails {
+			if !failed[email.origin] {
+				s.markRead(email.origin)
+			}
+		}
+	}
+	return emails, commit, nil
+}
+
+// markRead moves a message of the Maildir from new to cur, marked as seen
+func (s *EmailSource) markRead(name string) {
+	seen := filepath.Join(s.config.Dir, "cur", name+":2,S")
+	if err := os.Rename(filepath.Join(s.config.Dir, "new", name), seen); err != nil {
+		log.Printf("Error marking email %s as read: %v", name, err)
+	}
+}
+
+func readEmailFile(name string) (*emailMessage, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return parseEmail(file)
+}
+
+// readMboxes reads the messages of every mbox file in the directory past the
+// offset of the file's cursor. The returned commit advances the cursors of
+// the files that did not fail. Offsets are used rather than the messages'
+// dates, which senders set and may be missing or late.
+func (s *EmailSource) readMboxes(ctx context.Context) ([]readEmail, func(map[string]bool), error) {
+	entries, err := os.ReadDir(s.config.Dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read mbox directory: %w", err)
+	}
+
+	cursors, err := s.yacht.cursors.GetCursors(emailSourceName)
+	if err != nil {
+		log.Printf("Error loading email cursors, reading every message: %v", err)
+		cursors = map[string]models.ScrapeCursor{}
+	}
+
+	var emails []readEmail
+	var errs []error
+	ends := make(map[string]int64)
+	files := 0
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		files++
+
+		messages, end, err := readMbox(filepath.Join(s.config.Dir, entry.Name()), cursors[entry.Name()].ByteOffset)
+		if err != nil {
+			log.Printf("Error reading mbox %s: %v", entry.Name(), err)
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Name(), err))
+			continue
+		}
+
+		ends[entry.Name()] = end
+		for _, email := range messages {
+			emails = append(emails, readEmail{message: email, origin: entry.Name()})
+		}
+	}
+
+	if files > 0 && len(errs) == files {
+		return nil, nil, errors.Join(errs...)
+	}
+	return emails, func(failed map[string]bool) { s.advanceMboxCursors(emails, ends, failed) }, nil
+}
+
+// advanceMboxCursors moves the cursor of every mbox file to where it was read
+// up to, unless one of its emails failed
+func (s *EmailSource) advanceMboxCursors(emails []readEmail, ends map[string]int64, failed map[string]bool) {
+	// Reload the cursors, which may have changed since the fetch
+	cursors, err := s.yacht.cursors.GetCursors(emailSourceName)
+	if err != nil {
+		log.Printf("Error loading email cursors: %v", err)
+		return
+	}
+
+	advanced := make(map[string]*models.ScrapeCursor)
+	for name, end := range ends {
+		if failed[name] || cursors[name].ByteOffset == end {
+			continue
+		}
+		cursor := cursors[name]
+		cursor.Source = emailSourceName
+		cursor.Channel = name
+		cursor.ByteOffset = end
+		advanced[name] = &cursor
+	}
+	for _, email := range emails {
+		if cursor, ok := advanced[email.origin]; ok {
+			cursor.Advance(email.message.Date, 0)
+		}
+	}
+
+	for name, cursor := range advanced {
+		if err := s.yacht.cursors.SaveCursor(cursor); err != nil {
+			log.Printf("Error saving email cursor for %s: %v", name, err)
+		}
+	}
+}
+
+// readMbox parses the messages of an mbox file from a byte offset and returns
+// the offset it read up to. A file smaller than the offset was rewritten, so
+// it is read from the start. Lines quoted as ">From " are unquoted, as in the
+// mboxrd format.
+func readMbox(name string, offset int64) ([]*emailMessage, int64, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	if offset > info.Size() {
+		log.Printf("Mbox %s shrank, reading it from the start", filepath.Base(name))
+		offset = 0
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, 0, err
+	}
+
+	var emails []*emailMessage
+	var message bytes.Buffer
+	inMessage := false
+
+	flush := func() {
+		if !inMessage {
+			return
+		}
+		email, err := parseEmail(bytes.NewReader(message.Bytes()))
+		if err != nil {
+			log.Printf("Error parsing message in mbox %s: %v", filepath.Base(name), err)
+		} else {
+			emails = append(emails, email)
+		}
+		message.Reset()
+	}
+
+	reader := bufio.NewReader(file)
+	previousBlank := true
+	messageStart := offset
+	for {
+		line, err := reader.ReadString('\n')
+		if err == io.EOF && len(line) > 0 {
+			// A line without its newline belongs to a message still being
+			// written, which is left for the next read
+			if inMessage {
+				return emails, messageStart, nil
+			}
+			return emails, offset, nil
+		}
+		if len(line) > 0 {
+			switch {
+			case previousBlank && strings.HasPrefix(line, "From "):
+				flush()
+				inMessage = true
+				messageStart = offset
+			case inMessage:
+				if unquoted := strings.TrimLeft(line, ">"); len(unquoted) < len(line) && strings.HasPrefix(unquoted, "From ") {
+					line = line[1:]
+				}
+				message.WriteString(line)
+			}
+			previousBlank = strings.TrimRight(line, "\r\n") == ""
+		}
+		offset += int64(len(line))
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	flush()
+	return emails, offset, nil
+}
+
+// vacancySeparator matches lines that only separate vacancies, such as "-----"
+var vacancySeparator = regexp.MustCompile(`^\s*[-=_*~#•]{3,}\s*$`)
+
+// vacancyHeading matches lines that may start a vacancy, either numbered, as
+// in "Vacancy 2" or "Position #3", or labelled, as in "Position: Chief Stew"
+var vacancyHeading = regexp.MustCompile(`(?i)^\s*(vacancy|position|job|role|ref)(?:erence)?\s*(?:no\.?\s*)?(?:#?(\d+)\b|:)`)
+
+// emailPosts splits an email into one post per vacancy. A single vacancy is
+// prefixed with the subject, which often names the position.
+func emailPosts(email *emailMessage) []ScrapedPost {
+	vacancies := splitVacancies(email.Text)
+	if len(vacancies) == 1 && email.Subject != "" {
+		vacancies[0] = email.Subject + "\n\n" + vacancies[0]
+	}
+
+	date := email.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	posts := make([]ScrapedPost, 0, len(vacancies))
+	for _, vacancy := range vacancies {
+		posts = append(posts, ScrapedPost{
+			Text:        vacancy,
+			Timestamp:   date,
+			Source:      emailSourceName,
+			GroupName:   email.From,
+			ChannelName: email.Address,
+		})
+	}
+	return posts
+}
+
+// splitVacancies splits the text of a vacancy blast into one block per
+// vacancy, at separator lines or, without those, at vacancy headings. Text
+// with neither is a single vacancy.
+func splitVacancies(text string) []string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	var blocks [][]string
+	var block []string
+	for _, line := range lines {
+		if vacancySeparator.MatchString(line) {
+			blocks = append(blocks, block)
+			block = nil
+			continue
+		}
+		block = append(block, line)
+	}
+	blocks = append(blocks, block)
+
+	if vacancies := joinBlocks(blocks); len(vacancies) > 1 {
+		return vacancies
+	}
+
+	if headings := vacancyHeadings(lines); len(headings) > 1 {
+		// The text before the first heading, such as a greeting or the
+		// vessel, is context shared by every vacancy
+		intro := strings.TrimSpace(strings.Join(lines[:headings[0]], "\n"))
+
+		blocks = nil
+		for i, start := range headings {
+			end := len(lines)
+			if i+1 < len(headings) {
+				end = headings[i+1]
+			}
+			blocks = append(blocks, lines[start:end])
+		}
+		if vacancies := joinBlocks(blocks); len(vacancies) > 1 {
+			if intro != "" {
+				for i := range vacancies {
+					vacancies[i] = intro + "\n\n" + vacancies[i]
+				}
+			}
+			return vacancies
+		}
+	}
+
+	if text = strings.TrimSpace(text); text == "" {
+		return nil
+	}
+	return []string{text}
+}
+
+// vacancyHeadings returns the lines starting a vacancy. Numbered headings
+// start vacancies; without those, labelled headings only do when their kind
+// repeats, as the fields of a single vacancy like "Position:" and
+// "Reference:" each appear once.
+func vacancyHeadings(lines []string) []int {
+	var numbered []int
+	labelled := make(map[string][]int)
+	var kinds []string
+	for i, line := range lines {
+		match := vacancyHeading.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		if match[2] != "" {
+			numbered = append(numbered, i)
+			continue
+		}
+		kind := strings.ToLower(match[1])
+		if _, ok := labelled[kind]; !ok {
+			kinds = append(kinds, kind)
+		}
+		labelled[kind] = append(labelled[kind], i)
+	}
+
+	if len(numbered) > 1 {
+		return numbered
+	}
+	for _, kind := range kinds {
+		if len(labelled[kind]) > 1 {
+			return labelled[kind]
+		}
+	}
+	return nil
+}
+
+// joinBlocks joins the lines of each block, dropping blank blocks
+func joinBlocks(blocks [][]string) []string {
+	var vacancies []string
+	for _, block := range blocks {
+		if text := strings.TrimSpace(strings.Join(block, "\n")); text != "" {
+			vacancies = append(vacancies, text)
+		}
+	}
+	return vacancies
+}
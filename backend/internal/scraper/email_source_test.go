@@ -0,0 +1,159 @@
+package scraper
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitVacancies(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			"single vacancy with labelled fields",
+			"Position: Chief Stewardess\nReference: CS-123\nVessel: M/Y 60m\nLocation: Antibes\nSalary: €5,500 per month",
+			[]string{"Position: Chief Stewardess\nReference: CS-123\nVessel: M/Y 60m\nLocation: Antibes\nSalary: €5,500 per month"},
+		},
+		{
+			"single vacancy with job and role",
+			"Job: Deckhand\nRole: Day work\nLocation: Antibes",
+			[]string{"Job: Deckhand\nRole: Day work\nLocation: Antibes"},
+		},
+		{
+			"single vacancy with one numbered reference",
+			"Dear crew,\n\nRef 4521\nPosition: Bosun\nStart: ASAP",
+			[]string{"Dear crew,\n\nRef 4521\nPosition: Bosun\nStart: ASAP"},
+		},
+		{
+			"numbered headings",
+			"Dear crew, please see our latest vacancies.\n\nVacancy 1\nChief Stew, M/Y 60m\n\nVacancy 2\nDeckhand, S/Y 40m",
+			[]string{
+				"Dear crew, please see our latest vacancies.\n\nVacancy 1\nChief Stew, M/Y 60m",
+				"Dear crew, please see our latest vacancies.\n\nVacancy 2\nDeckhand, S/Y 40m",
+			},
+		},
+		{
+			"numbered headings with labelled fields",
+			"Position #1\nPosition: Chief Stew\nReference: CS-1\n\nPosition #2\nPosition: Deckhand\nReference: DH-2",
+			[]string{
+				"Position #1\nPosition: Chief Stew\nReference: CS-1",
+				"Position #2\nPosition: Deckhand\nReference: DH-2",
+			},
+		},
+		{
+			"repeated labelled heading",
+			"Our client is hiring:\n\nPosition: Chief Stew\nReference: CS-1\nSalary: €5,000\n\nPosition: Deckhand\nReference: DH-2\nSalary: €3,000",
+			[]string{
+				"Our client is hiring:\n\nPosition: Chief Stew\nReference: CS-1\nSalary: €5,000",
+				"Our client is hiring:\n\nPosition: Deckhand\nReference: DH-2\nSalary: €3,000",
+			},
+		},
+		{
+			"separators",
+			"Chief Stew, M/Y 60m\n-----\nDeckhand, S/Y 40m\n-----\n",
+			[]string{"Chief Stew, M/Y 60m", "Deckhand, S/Y 40m"},
+		},
+		{
+			"blank",
+			"\n  \n",
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitVacancies(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitVacancies() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmailPostsSingleVacancy(t *testing.T) {
+	email := &emailMessage{
+		Subject: "Chief Stewardess - M/Y 60m",
+		From:    "Crew Agency",
+		Address: "jobs@agency.example",
+		Text:    "Position: Chief Stewardess\nReference: CS-123\nVessel: M/Y 60m\nLocation: Antibes\n",
+	}
+
+	posts := emailPosts(email)
+	if len(posts) != 1 {
+		t.Fatalf("got %d posts, want 1", len(posts))
+	}
+	if !strings.HasPrefix(posts[0].Text, "Chief Stewardess - M/Y 60m\n\nPosition: Chief Stewardess\nReference: CS-123") {
+		t.Errorf("post text = %q", posts[0].Text)
+	}
+	if posts[0].ChannelName != email.Address || posts[0].Timestamp.IsZero() {
+		t.Errorf("unexpected post %+v", posts[0])
+	}
+}
+
+func TestReadMboxFromOffset(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "agency")
+	undated := "From jobs@agency.example Mon Oct 12 10:00:00 2026\nSubject: Deckhand\n\nDeckhand wanted\n\n"
+	dated := "From jobs@agency.example Mon Oct 12 11:00:00 2026\nSubject: Bosun\nDate: Mon, 12 Oct 2026 11:00:00 +0000\n\nBosun wanted\n\n"
+	// Delivered late, dated before the messages already read
+	late := "From jobs@agency.example Tue Oct 13 09:00:00 2026\nSubject: Chef\nDate: Mon, 5 Oct 2026 09:00:00 +0000\n\nChef wanted\n\n"
+
+	if err := os.WriteFile(name, []byte(undated+dated), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	read := func(offset int64) ([]string, int64) {
+		t.Helper()
+		emails, end, err := readMbox(name, offset)
+		if err != nil {
+			t.Fatalf("readMbox: %v", err)
+		}
+		var subjects []string
+		for _, email := range emails {
+			subjects = append(subjects, email.Subject)
+		}
+		return subjects, end
+	}
+
+	subjects, end := read(0)
+	if !reflect.DeepEqual(subjects, []string{"Deckhand", "Bosun"}) || end != int64(len(undated+dated)) {
+		t.Fatalf("first read = %q up to %d", subjects, end)
+	}
+	if subjects, again := read(end); len(subjects) != 0 || again != end {
+		t.Fatalf("read without new mail = %q up to %d, want nothing up to %d", subjects, again, end)
+	}
+
+	// A message still being written is left for the next read
+	appendFile(t, name, late+"From jobs@agency.example Tue Oct 13 10:00:00 2026\nSubject: Eng")
+	subjects, next := read(end)
+	if !reflect.DeepEqual(subjects, []string{"Chef"}) || next != end+int64(len(late)) {
+		t.Fatalf("read after delivery = %q up to %d", subjects, next)
+	}
+
+	appendFile(t, name, "ineer\n\nEngineer wanted\n\n")
+	if subjects, _ := read(next); !reflect.DeepEqual(subjects, []string{"Engineer"}) {
+		t.Fatalf("read after the message was written = %q", subjects)
+	}
+
+	// A rewritten mbox smaller than the offset is read from the start
+	if err := os.WriteFile(name, []byte(dated), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if subjects, _ := read(next); !reflect.DeepEqual(subjects, []string{"Bosun"}) {
+		t.Fatalf("read after rewrite = %q", subjects)
+	}
+}
+
+func appendFile(t *testing.T, name, text string) {
+	t.Helper()
+	file, err := os.OpenFile(name, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(text); err != nil {
+		t.Fatal(err)
+	}
+}
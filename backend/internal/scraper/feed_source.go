@@ -0,0 +1,364 @@
+package scraper
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/models"
+	"golang.org/x/net/html/charset"
+)
+
+// maxFeedSize bounds the feed documents read
+const maxFeedSize = 10 << 20
+
+// feedDateLayouts are the date formats of RSS pubDate and Atom timestamps
+var feedDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC822Z,
+	time.RFC822,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// FeedSource polls the RSS and Atom feeds configured in scrape_sources, such
+// as agency vacancy feeds, and extracts jobs from their items like posts
+type FeedSource struct {
+	yacht   *YachtScraperService
+	fetcher *Fetcher
+}
+
+func NewFeedSource(yacht *YachtScraperService, fetcher *Fetcher) *FeedSource {
+	return &FeedSource{yacht: yacht, fetcher: fetcher}
+}
+
+func (s *FeedSource) Name() string {
+	return models.ScrapeSourceTypeFeed
+}
+
+// Fetch polls every enabled feed, skipping the ones unchanged since the last
+// poll. A feed that fails is skipped, the fetch only fails when every feed does.
+func (s *FeedSource) Fetch(ctx context.Context) ([]Item, error) {
+	feeds, err := s.yacht.sources.ListEnabled(models.ScrapeSourceTypeFeed)
+	if err != nil {
+		return nil, err
+	}
+	if len(feeds) == 0 {
+		log.Println("Skipping feed scraping - no enabled feeds")
+		return nil, nil
+	}
+
+	cursors, err := s.yacht.cursors.GetCursors(models.ScrapeSourceTypeFeed)
+	if err != nil {
+		log.Printf("Error loading feed cursors, fetching every feed in full: %v", err)
+		cursors = map[string]models.ScrapeCursor{}
+	}
+
+	var posts []ScrapedPost
+	var validators []feedValidators
+	var errs []error
+	for _, feed := range feeds {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		feedPosts, cache, err := s.poll(ctx, feed, cursors[feedChannel(feed.Identifier)])
+		if err != nil {
+			log.Printf("Error polling feed %s: %v", feed.Identifier, err)
+			errs = append(errs, fmt.Errorf("%s: %w", feed.Identifier, err))
+			continue
+		}
+		posts = append(posts, feedPosts...)
+		if cache != nil {
+			validators = append(validators, *cache)
+		}
+	}
+
+	if len(errs) == len(feeds) {
+		return nil, errors.Join(errs...)
+	}
+
+	posts, commitPosts := s.yacht.filterNewPosts(models.ScrapeSourceTypeFeed, posts)
+	deferCommit(ctx, func(failed []Item) {
+		commitPosts(failed)
+		s.saveValidators(validators, failed)
+	})
+	for i := range posts {
+		if posts[i].Timestamp.IsZero() {
+			posts[i].Timestamp = time.Now()
+		}
+	}
+	return postItems(posts), nil
+}
+
+func (s *FeedSource) Parse(item Item) []*models.Job {
+	jobs := s.yacht.parsePost(item)
+	for _, job := range jobs {
+		job.Source = item.Post.GroupName
+	}
+	return jobs
+}
+
+// feedValidators are the ETag and Last-Modified of a feed response
+type feedValidators struct {
+	feedURL      string
+	etag         string
+	lastModified string
+}
+
+// poll fetches a feed with a conditional GET and returns its newest items as
+// posts with the validators of the response, or nothing when the feed did not
+// change
+func (s *FeedSource) poll(ctx context.Context, feed models.ScrapeSource, cursor models.ScrapeCursor) ([]ScrapedPost, *feedValidators, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", feed.Identifier, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, text/xml;q=0.8")
+	if cursor.ETag != "" {
+		req.Header.Set("If-None-Match", cursor.ETag)
+	}
+	if cursor.LastModified != "" {
+		req.Header.Set("If-Modified-Since", cursor.LastModified)
+	}
+
+	resp, err := s.fetcher.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		log.Printf("📭 Feed %s not modified", feed.Identifier)
+		return nil, nil, nil
+	case http.StatusOK:
+	default:
+		return nil, nil, fmt.Errorf("bad status code %d", resp.StatusCode)
+	}
+
+	title, posts, err := parseFeed(io.LimitReader(resp.Body, maxFeedSize))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Feeds added without a display name are named after the feed URL
+	name := feed.DisplayName
+	if (name == "" || name == feed.Identifier) && title != "" {
+		name = title
+	}
+	for i := range posts {
+		posts[i].Source = models.ScrapeSourceTypeFeed
+		posts[i].GroupName = name
+		posts[i].ChannelName = feed.Identifier
+	}
+
+	// Feeds list their newest items first by convention, but not always
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].Timestamp.After(posts[j].Timestamp)
+	})
+	if len(posts) > feed.MaxPosts {
+		posts = posts[:feed.MaxPosts]
+	}
+
+	log.Printf("Fetched %d items from feed %s", len(posts), feed.Identifier)
+	return posts, &feedValidators{
+		feedURL:      feed.Identifier,
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+	}, nil
+}
+
+// saveValidators stores the ETag and Last-Modified of the polled feeds on
+// their cursors once their items were saved. A feed with an item that failed
+// keeps its old validators, so the next poll downloads it again.
+func (s *FeedSource) saveValidators(validators []feedValidators, failed []Item) {
+	if len(validators) == 0 {
+		return
+	}
+
+	failedFeeds := make(map[string]bool)
+	for _, item := range failed {
+		if item.Post != nil {
+			failedFeeds[item.Post.ChannelName] = true
+		}
+	}
+
+	cursors, err := s.yacht.cursors.GetCursors(models.ScrapeSourceTypeFeed)
+	if err != nil {
+		log.Printf("Error loading feed cursors: %v", err)
+		return
+	}
+
+	for _, v := range validators {
+		if failedFeeds[v.feedURL] {
+			continue
+		}
+
+		channel := feedChannel(v.feedURL)
+		cursor := cursors[channel]
+		cursor.Source = models.ScrapeSourceTypeFeed
+		cursor.Channel = channel
+		cursor.ETag = v.etag
+		cursor.LastModified = v.lastModified
+		if err := s.yacht.cursors.SaveCursor(&cursor); err != nil {
+			log.Printf("Error saving feed cursor for %s: %v", v.feedURL, err)
+		}
+	}
+}
+
+// feedChannel returns the cursor channel of a feed
+func feedChannel(feedURL string) string {
+	channel, _ := postChannel(ScrapedPost{ChannelName: feedURL})
+	return channel
+}
+
+// rssFeed is an RSS 2.0 or RSS 1.0 (RDF) document
+type rssFeed struct {
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Items []rssItem `xml:"item"` // RSS 1.0 items are siblings of the channel
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	Description string `xml:"description"`
+	Content     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PubDate     string `xml:"pubDate"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+}
+
+// atomFeed is an Atom document
+type atomFeed struct {
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	Title string `xml:"title"`
+	ID    string `xml:"id"`
+	Links []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+	Summary   string `xml:"summary"`
+	Content   string `xml:"content"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+}
+
+// parseFeed parses an RSS or Atom document into its title and items as posts
+func parseFeed(r io.Reader) (string, []ScrapedPost, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = charset.NewReaderLabel
+	decoder.Strict = false
+
+	// The root element tells the formats apart
+	var root xml.StartElement
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to parse feed: %w", err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			root = start
+			break
+		}
+	}
+
+	switch strings.ToLower(root.Name.Local) {
+	case "rss", "rdf":
+		var feed rssFeed
+		if err := decoder.DecodeElement(&feed, &root); err != nil {
+			return "", nil, fmt.Errorf("failed to parse RSS feed: %w", err)
+		}
+		items := append(feed.Channel.Items, feed.Items...)
+		posts := make([]ScrapedPost, 0, len(items))
+		for _, item := range items {
+			body := item.Content
+			if body == "" {
+				body = item.Description
+			}
+			link := strings.TrimSpace(item.Link)
+			if link == "" && strings.HasPrefix(item.GUID, "http") {
+				link = strings.TrimSpace(item.GUID)
+			}
+			posts = append(posts, feedPost(item.Title, body, link, item.PubDate, item.Date))
+		}
+		return strings.TrimSpace(feed.Channel.Title), posts, nil
+
+	case "feed":
+		var feed atomFeed
+		if err := decoder.DecodeElement(&feed, &root); err != nil {
+			return "", nil, fmt.Errorf("failed to parse Atom feed: %w", err)
+		}
+		posts := make([]ScrapedPost, 0, len(feed.Entries))
+		for _, entry := range feed.Entries {
+			body := entry.Content
+			if body == "" {
+				body = entry.Summary
+			}
+			var link string
+			for _, l := range entry.Links {
+				if l.Rel == "" || l.Rel == "alternate" {
+					link = strings.TrimSpace(l.Href)
+					break
+				}
+			}
+			if link == "" && strings.HasPrefix(entry.ID, "http") {
+				link = strings.TrimSpace(entry.ID)
+			}
+			posts = append(posts, feedPost(entry.Title, body, link, entry.Published, entry.Updated))
+		}
+		return strings.TrimSpace(feed.Title), posts, nil
+	}
+	return "", nil, fmt.Errorf("unsupported feed format <%s>", root.Name.Local)
+}
+
+// feedPost converts a feed item to a post. Its text starts with the title so
+// the extraction rules see it first, followed by the item body as plain text.
+func feedPost(title, body, link string, dates ...string) ScrapedPost {
+	text := strings.TrimSpace(title)
+	if body = htmlText(body); body != "" {
+		text += "\n\n" + body
+	}
+
+	post := ScrapedPost{Text: text, URL: link}
+	for _, date := range dates {
+		if postedAt, ok := parseFeedDate(date); ok {
+			post.Timestamp = postedAt
+			break
+		}
+	}
+	return post
+}
+
+// parseFeedDate parses an RSS or Atom date
+func parseFeedDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range feedDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,313 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// ErrBlockedByRobots is returned for URLs the host's robots.txt disallows
+var ErrBlockedByRobots = errors.New("blocked by robots.txt")
+
+// DefaultUserAgent identifies the scraper to the sites it fetches
+const DefaultUserAgent = "CarverJobsBot/1.0"
+
+// maxRedirects is the number of redirects followed for a request
+const maxRedirects = 10
+
+// How long robots.txt files are cached
+const (
+	robotsTTL      = 24 * time.Hour
+	robotsRetryTTL = 10 * time.Minute // After robots.txt could not be fetched
+)
+
+// FetcherConfig configures how politely the scraper fetches pages
+type FetcherConfig struct {
+	UserAgent string
+	// HostRate is the number of requests per second sent to a host
+	HostRate float64
+	// HostConcurrency is the number of requests in flight to a host
+	HostConcurrency int
+	// Timeout bounds each attempt of a request, failed attempts are retried
+	Timeout time.Duration
+}
+
+// NewFetcherConfig reads the fetcher settings from SCRAPER_USER_AGENT,
+// SCRAPER_HOST_RATE and SCRAPER_HOST_CONCURRENCY
+func NewFetcherConfig() FetcherConfig {
+	config := FetcherConfig{
+		UserAgent:       DefaultUserAgent,
+		HostRate:        0.5,
+		HostConcurrency: 2,
+		Timeout:         30 * time.Second,
+	}
+
+	if value := strings.TrimSpace(os.Getenv("SCRAPER_USER_AGENT")); value != "" {
+		config.UserAgent = value
+	}
+	if value := os.Getenv("SCRAPER_HOST_RATE"); value != "" {
+		if hostRate, err := strconv.ParseFloat(value, 64); err == nil && hostRate > 0 {
+			config.HostRate = hostRate
+		} else {
+			log.Printf("Invalid SCRAPER_HOST_RATE %q", value)
+		}
+	}
+	if value := os.Getenv("SCRAPER_HOST_CONCURRENCY"); value != "" {
+		if concurrency, err := strconv.Atoi(value); err == nil && concurrency > 0 {
+			config.HostConcurrency = concurrency
+		} else {
+			log.Printf("Invalid SCRAPER_HOST_CONCURRENCY %q", value)
+		}
+	}
+	return config
+}
+
+// Fetcher is the HTTP client shared by the scraper sources that fetch pages
+// from third-party sites. It honors robots.txt and limits the rate and
+// concurrency of the requests sent to each host.
+type Fetcher struct {
+	config FetcherConfig
+	agent  string // Product token matched against robots.txt user agents
+	client *http.Client
+
+	mu    sync.Mutex
+	hosts map[string]*fetchHost
+}
+
+// fetchHost holds the limits and robots.txt rules of a single host
+type fetchHost struct {
+	limiter *rate.Limiter
+	slots   chan struct{}
+
+	robotsMu      sync.Mutex
+	robots        *robotsRules
+	robotsExpires time.Time
+}
+
+func NewFetcher(config FetcherConfig) *Fetcher {
+	agent, _, _ := strings.Cut(config.UserAgent, "/")
+	retry := DefaultRetryConfig
+	retry.AttemptTimeout = config.Timeout
+
+	f := &Fetcher{
+		config: config,
+		agent:  strings.TrimSpace(agent),
+		hosts:  make(map[string]*fetchHost),
+	}
+	// Every attempt waits for the rate limit of its host, so retries and
+	// redirects are paced like first attempts
+	f.client = &http.Client{
+		Transport: &retryTransport{
+			next:   http.DefaultTransport,
+			config: retry,
+			wait:   f.waitForHost,
+		},
+		CheckRedirect: f.checkRedirect,
+	}
+	return f
+}
+
+// Do sends a request once the host's robots.txt allows it and its rate and
+// concurrency limits leave room. The host slot is held until the response
+// body is closed.
+func (f *Fetcher) Do(req *http.Request) (*http.Response, error) {
+	host := f.host(req.URL)
+
+	robots := f.robots(req.Context(), host, req.URL)
+	if !robots.allowed(req.URL.RequestURI()) {
+		countBlockedFetch(req.Context())
+		log.Printf("🤖 robots.txt disallows %s", req.URL)
+		return nil, fmt.Errorf("%w: %s", ErrBlockedByRobots, req.URL)
+	}
+
+	return f.send(host, req)
+}
+
+// checkRedirect only follows a redirect that the robots.txt of its target
+// allows. The hop then waits for the rate limit of the target host in the
+// transport, like any other attempt.
+func (f *Fetcher) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+
+	host := f.host(req.URL)
+	var robots *robotsRules
+	if hostKey(req.URL) == hostKey(via[0].URL) {
+		// The request holds a slot of its host, which fetching robots.txt
+		// would wait for, and Do just checked the host's rules
+		robots = host.cachedRobots()
+	}
+	if robots == nil {
+		robots = f.robots(req.Context(), host, req.URL)
+	}
+
+	if !robots.allowed(req.URL.RequestURI()) {
+		countBlockedFetch(req.Context())
+		log.Printf("🤖 robots.txt disallows %s, redirected from %s", req.URL, via[len(via)-1].URL)
+		return fmt.Errorf("%w: %s", ErrBlockedByRobots, req.URL)
+	}
+	return nil
+}
+
+// Get fetches a URL
+func (f *Fetcher) Get(ctx context.Context, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	return f.Do(req)
+}
+
+// hostKey identifies the host a URL points to
+func hostKey(u *url.URL) string {
+	return strings.ToLower(u.Scheme + "://" + u.Host)
+}
+
+// host returns the state of the host a URL points to
+func (f *Fetcher) host(u *url.URL) *fetchHost {
+	key := hostKey(u)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	host, ok := f.hosts[key]
+	if !ok {
+		host = &fetchHost{
+			limiter: rate.NewLimiter(rate.Limit(f.config.HostRate), 1),
+			slots:   make(chan struct{}, f.config.HostConcurrency),
+		}
+		f.hosts[key] = host
+	}
+	return host
+}
+
+// send waits for a slot of the host, then sends the request. The host slot
+// is held across retries, each attempt waits for the rate limit.
+func (f *Fetcher) send(host *fetchHost, req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	select {
+	case host.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	release := func() { <-host.slots }
+
+	req.Header.Set("User-Agent", f.config.UserAgent)
+	resp, err := f.client.Do(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// waitForHost waits until the rate limit of the request's host allows
+// another request
+func (f *Fetcher) waitForHost(req *http.Request) error {
+	return f.host(req.URL).limiter.Wait(req.Context())
+}
+
+// robots returns the cached robots.txt rules of a host, fetching them when
+// they expired
+func (f *Fetcher) robots(ctx context.Context, host *fetchHost, u *url.URL) *robotsRules {
+	host.robotsMu.Lock()
+	defer host.robotsMu.Unlock()
+
+	if host.robots != nil && time.Now().Before(host.robotsExpires) {
+		return host.robots
+	}
+
+	robots, ttl := f.fetchRobots(ctx, host, u)
+	host.robots = robots
+	host.robotsExpires = time.Now().Add(ttl)
+
+	// Honor a crawl delay slower than our own rate
+	if robots.crawlDelay > 0 {
+		if limit := rate.Every(robots.crawlDelay); limit < host.limiter.Limit() {
+			host.limiter.SetLimit(limit)
+		}
+	}
+	return robots
+}
+
+// cachedRobots returns the robots.txt rules of a host last fetched, or nil
+func (h *fetchHost) cachedRobots() *robotsRules {
+	h.robotsMu.Lock()
+	defer h.robotsMu.Unlock()
+	return h.robots
+}
+
+// fetchRobots fetches and parses a host's robots.txt. A missing file allows
+// everything, while a server error disallows everything until it is retried.
+func (f *Fetcher) fetchRobots(ctx context.Context, host *fetchHost, u *url.URL) (*robotsRules, time.Duration) {
+	robotsURL := u.Scheme + "://" + u.Host + "/robots.txt"
+
+	req, err := http.NewRequestWithContext(ctx, "GET", robotsURL, nil)
+	if err != nil {
+		return disallowAll, robotsRetryTTL
+	}
+
+	resp, err := f.send(host, req)
+	if err != nil {
+		log.Printf("Error fetching %s: %v", robotsURL, err)
+		return disallowAll, robotsRetryTTL
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return parseRobots(resp.Body, f.agent), robotsTTL
+	case resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests:
+		return allowAll, robotsTTL
+	default:
+		log.Printf("Error fetching %s: status code %d", robotsURL, resp.StatusCode)
+		return disallowAll, robotsRetryTTL
+	}
+}
+
+// releaseBody frees the host slot of a response once its body is closed
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// fetchStats counts the fetches of a source that robots.txt blocked
+type fetchStats struct {
+	blocked atomic.Int64
+}
+
+type fetchStatsKey struct{}
+
+// withFetchStats returns a context whose fetches are counted in stats
+func withFetchStats(ctx context.Context, stats *fetchStats) context.Context {
+	return context.WithValue(ctx, fetchStatsKey{}, stats)
+}
+
+func countBlockedFetch(ctx context.Context) {
+	if stats, ok := ctx.Value(fetchStatsKey{}).(*fetchStats); ok {
+		stats.blocked.Add(1)
+	}
+}
@@ -0,0 +1,499 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/models"
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/services"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/google/uuid"
+	"golang.org/x/net/html"
+)
+
+// defaultURLSelector finds the job URL of boards that do not map one
+var defaultURLSelector = models.HTMLFieldSelector{Selector: "a", Attr: "href"}
+
+// postedAtLayouts are tried when a board sets no date format
+var postedAtLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"2 January 2006",
+	"2 Jan 2006",
+	"January 2, 2006",
+	"Jan 2, 2006",
+}
+
+// HTMLCrawlConfig bounds how much of the HTML job boards a scrape crawls
+type HTMLCrawlConfig struct {
+	// Budget is the number of pages fetched per run across every board
+	Budget int
+}
+
+// NewHTMLCrawlConfig reads the crawl budget per run from SCRAPER_HTML_CRAWL_BUDGET
+func NewHTMLCrawlConfig() HTMLCrawlConfig {
+	config := HTMLCrawlConfig{
+		Budget: 200,
+	}
+
+	if value := os.Getenv("SCRAPER_HTML_CRAWL_BUDGET"); value != "" {
+		if budget, err := strconv.Atoi(value); err == nil && budget > 0 {
+			config.Budget = budget
+		} else {
+			log.Printf("Invalid SCRAPER_HTML_CRAWL_BUDGET %q", value)
+		}
+	}
+	return config
+}
+
+// HTMLSource scrapes job listings from the HTML job boards configured in
+// scrape_sources, each mapped to jobs by its own selectors
+type HTMLSource struct {
+	sources *services.ScrapeSourceService
+	rules   *RuleEngine
+	crawl   HTMLCrawlConfig
+	fetcher *Fetcher
+}
+
+// NewHTMLSource creates the source scraping every enabled HTML job board
+func NewHTMLSource(
+	sources *services.ScrapeSourceService,
+	rules *RuleEngine,
+	crawl HTMLCrawlConfig,
+	fetcher *Fetcher,
+) *HTMLSource {
+	return &HTMLSource{
+		sources: sources,
+		rules:   rules,
+		crawl:   crawl,
+		fetcher: fetcher,
+	}
+}
+
+func (s *HTMLSource) Name() string {
+	return models.ScrapeSourceTypeHTML
+}
+
+// Fetch crawls the listing pages, and detail pages when configured, of every
+// enabled board. A board that fails is skipped, the fetch only fails when
+// every board does.
+func (s *HTMLSource) Fetch(ctx context.Context) ([]Item, error) {
+	boards, err := s.sources.ListEnabled(models.ScrapeSourceTypeHTML)
+	if err != nil {
+		return nil, err
+	}
+	if len(boards) == 0 {
+		log.Println("Skipping HTML scraping - no enabled job boards")
+		return nil, nil
+	}
+
+	crawl := &crawler{source: s, budget: s.crawl.Budget}
+
+	var items []Item
+	var errs []error
+	for _, board := range boards {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		boardItems, err := crawl.board(ctx, board)
+		if err != nil {
+			log.Printf("Error scraping %s: %v", board.DisplayName, err)
+			errs = append(errs, fmt.Errorf("%s: %w", board.DisplayName, err))
+			continue
+		}
+		items = append(items, boardItems...)
+	}
+
+	if len(errs) == len(boards) {
+		return nil, errors.Join(errs...)
+	}
+	log.Printf("Crawled %d HTML pages", s.crawl.Budget-crawl.budget)
+	return items, nil
+}
+
+// crawler tracks the pages left in the crawl budget of a run
+type crawler struct {
+	source *HTMLSource
+	budget int
+}
+
+// board crawls a board's listing pages, following next page links, then the
+// detail pages of the listings found
+func (c *crawler) board(ctx context.Context, board models.ScrapeSource) ([]Item, error) {
+	config := board.Config
+	if config == nil {
+		return nil, fmt.Errorf("no selector config")
+	}
+
+	maxPages := config.MaxPages
+	if maxPages == 0 {
+		maxPages = 1
+	}
+
+	var items []Item
+	visited := make(map[string]bool)
+	pageURL := board.Identifier
+
+	for page := 1; page <= maxPages && pageURL != "" && len(items) < board.MaxPosts; page++ {
+		visited[pageURL] = true
+
+		doc, base, err := c.page(ctx, pageURL, config.BaseURL)
+		if err != nil {
+			if page == 1 {
+				return nil, err
+			}
+			log.Printf("Error fetching page %d of %s: %v", page, board.DisplayName, err)
+			break
+		}
+		if doc == nil {
+			log.Printf("Crawl budget used up on page %d of %s", page, board.DisplayName)
+			break
+		}
+
+		var listings []*ScrapedJob
+		doc.Find(config.ListSelector).Each(func(i int, sel *goquery.Selection) {
+			job := extractListing(sel, config, base)
+			listings = append(listings, &job)
+		})
+		// JobPosting data embedded in the page is more exact than the selectors
+		listings = mergeJobPostings(listings, findJobPostings(doc.Selection, base))
+
+		for _, job := range listings {
+			if len(items) >= board.MaxPosts {
+				break
+			}
+			job.Source = board.DisplayName
+
+			// Only add if we have minimum required data
+			if job.Title != "" && job.Company != "" {
+				items = append(items, Item{Listing: job})
+			}
+		}
+
+		pageURL = ""
+		if config.NextPageSelector != "" {
+			if next := nextPage(doc, config.NextPageSelector, base); next != "" && !visited[next] {
+				pageURL = next
+			}
+		}
+	}
+
+	if config.Detail != nil {
+		c.details(ctx, board, items)
+	}
+
+	log.Printf("Found %d listings on %s", len(items), board.DisplayName)
+	return items, nil
+}
+
+// details fills the listings from their detail pages while the budget lasts.
+// JobPosting data on a detail page wins over its selectors.
+func (c *crawler) details(ctx context.Context, board models.ScrapeSource, items []Item) {
+	for _, item := range items {
+		job := item.Listing
+		if job.URL == "" {
+			continue
+		}
+
+		doc, base, err := c.page(ctx, job.URL, "")
+		if err != nil {
+			log.Printf("Error fetching detail page %s: %v", job.URL, err)
+			continue
+		}
+		if doc == nil {
+			log.Printf("Crawl budget used up before the detail pages of %s", board.DisplayName)
+			return
+		}
+
+		for name, selector := range board.Config.Detail.Fields {
+			if value := selectField(doc.Selection, selector); value != "" {
+				setListingField(job, name, value, board.Config.DateFormat)
+			}
+		}
+		if posting, ok := pageJobPosting(findJobPostings(doc.Selection, base), job.URL); ok {
+			applyJobPosting(job, posting)
+		}
+	}
+}
+
+// page fetches and parses a page, returning the URL its relative links
+// resolve against. It returns a nil document once the budget is used up.
+func (c *crawler) page(ctx context.Context, pageURL, baseURL string) (*goquery.Document, *url.URL, error) {
+	if c.budget <= 0 {
+		return nil, nil, nil
+	}
+	if baseURL == "" {
+		baseURL = pageURL
+	}
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+
+	c.budget--
+
+	doc, err := c.source.fetchDocument(ctx, pageURL)
+	if err != nil {
+		// Pages robots.txt blocks were never requested
+		if errors.Is(err, ErrBlockedByRobots) {
+			c.budget++
+		}
+		return nil, nil, err
+	}
+	return doc, base, nil
+}
+
+// fetchDocument fetches and parses an HTML page
+func (s *HTMLSource) fetchDocument(ctx context.Context, pageURL string) (*goquery.Document, error) {
+	resp, err := s.fetcher.Get(ctx, pageURL)
+	if err != nil {
+		if errors.Is(err, ErrBlockedByRobots) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to fetch %s: %w", pageURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bad status code %d for %s", resp.StatusCode, pageURL)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	return doc, nil
+}
+
+// nextPage returns the absolute URL of the next listing page, if any
+func nextPage(doc *goquery.Document, selector string, base *url.URL) string {
+	link, ok := doc.Find(selector).First().Attr("href")
+	if !ok || strings.TrimSpace(link) == "" {
+		return ""
+	}
+	next, err := base.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return ""
+	}
+	next.Fragment = ""
+	return next.String()
+}
+
+// extractListing maps a listing element to a scraped job using the board's selectors
+func extractListing(sel *goquery.Selection, config *models.HTMLSiteConfig, base *url.URL) ScrapedJob {
+	job := ScrapedJob{
+		PostedAt: time.Now(), // Default to now if can't parse
+	}
+	for name, selector := range config.Fields {
+		if name == models.HTMLFieldURL {
+			continue
+		}
+		setListingField(&job, name, selectField(sel, selector), config.DateFormat)
+	}
+
+	urlSelector, ok := config.Fields[models.HTMLFieldURL]
+	if !ok {
+		urlSelector = defaultURLSelector
+	}
+	if link := selectField(sel, urlSelector); link != "" {
+		if resolved, err := base.Parse(link); err == nil {
+			job.URL = resolved.String()
+		}
+	}
+	return job
+}
+
+// setListingField sets a field of a scraped job by its config name
+func setListingField(job *ScrapedJob, name, value, dateFormat string) {
+	switch name {
+	case models.HTMLFieldTitle:
+		job.Title = value
+	case models.HTMLFieldCompany:
+		job.Company = value
+	case models.HTMLFieldLocation:
+		job.Location = value
+	case models.HTMLFieldType:
+		job.Type = value
+	case models.HTMLFieldVessel:
+		job.Vessel = value
+	case models.HTMLFieldDuration:
+		job.Duration = value
+	case models.HTMLFieldSalary:
+		job.Salary = value
+	case models.HTMLFieldDescription:
+		job.Description = value
+	case models.HTMLFieldRequirements:
+		job.Requirements = value
+	case models.HTMLFieldPostedAt:
+		if postedAt, ok := parsePostedAt(value, dateFormat); ok {
+			job.PostedAt = postedAt
+		}
+	}
+}
+
+// selectField returns the text or attribute of the element a field selector
+// matches within a listing, or the selector default when it is empty
+func selectField(sel *goquery.Selection, selector models.HTMLFieldSelector) string {
+	target := sel
+	if selector.Selector != "" {
+		target = sel.Find(selector.Selector).First()
+	}
+
+	var value string
+	if selector.Attr != "" {
+		value, _ = target.Attr(selector.Attr)
+	} else {
+		value = elementText(target)
+	}
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return selector.Default
+	}
+	return value
+}
+
+// blockElements start a new line in extracted text
+var blockElements = map[string]bool{
+	"address": true, "article": true, "blockquote": true, "br": true, "dd": true, "div": true,
+	"dl": true, "dt": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "footer": true, "li": true, "ol": true, "p": true, "section": true, "table": true,
+	"td": true, "th": true, "tr": true, "ul": true,
+}
+
+// elementText returns the text of the first element of sel, keeping the
+// line breaks of block elements such as list items and paragraphs
+func elementText(sel *goquery.Selection) string {
+	if sel.Length() == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		switch node.Type {
+		case html.TextNode:
+			b.WriteString(node.Data)
+			return
+		case html.ElementNode:
+			if node.Data == "script" || node.Data == "style" {
+				return
+			}
+		}
+
+		block := node.Type == html.ElementNode && blockElements[node.Data]
+		if block {
+			b.WriteString("\n")
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+		if block {
+			b.WriteString("\n")
+		}
+	}
+	walk(sel.Get(0))
+
+	var lines []string
+	for _, line := range strings.Split(b.String(), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parsePostedAt parses a listing date with the board's date format, falling
+// back to common layouts
+func parsePostedAt(value, layout string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	layouts := postedAtLayouts
+	if layout != "" {
+		layouts = []string{layout}
+	}
+	for _, layout := range layouts {
+		if postedAt, err := time.Parse(layout, value); err == nil {
+			return postedAt, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Parse converts a scraped listing to the job model
+func (s *HTMLSource) Parse(item Item) []*models.Job {
+	if item.Listing == nil {
+		return nil
+	}
+	scrapedJob := item.Listing
+
+	job := &models.Job{
+		ID:           uuid.New().String(),
+		Title:        scrapedJob.Title,
+		Company:      scrapedJob.Company,
+		Location:     scrapedJob.Location,
+		Type:         scrapedJob.Type,
+		Vessel:       scrapedJob.Vessel,
+		Duration:     scrapedJob.Duration,
+		Salary:       scrapedJob.Salary,
+		Description:  scrapedJob.Description,
+		Requirements: scrapedJob.Requirements,
+		SourceURL:    scrapedJob.URL,
+		Source:       scrapedJob.Source,
+		PostedAt:     scrapedJob.PostedAt,
+		ScrapedAt:    time.Now(),
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+
+	if !scrapedJob.ValidThrough.IsZero() {
+		validThrough := scrapedJob.ValidThrough
+		job.ValidThrough = &validThrough
+	}
+
+	if scrapedJob.ParsedSalary != nil {
+		services.ApplySalary(job, scrapedJob.ParsedSalary)
+	} else {
+		salaryText := scrapedJob.Salary
+		if salaryText == "" {
+			salaryText = scrapedJob.Description
+		}
+		setJobSalary(job, salaryText)
+	}
+
+	durationText := scrapedJob.Duration
+	if durationText == "" {
+		durationText = scrapedJob.Description
+	}
+	setJobDuration(job, durationText)
+
+	locationText := scrapedJob.Location
+	if locationText == "" {
+		locationText = scrapedJob.Description
+	}
+	setJobLocation(job, locationText)
+
+	// Extract marine-specific details the board does not map
+	listing := s.rules.Extractor(listingRules)
+	text := scrapedJob.Title + " " + scrapedJob.Description
+	if job.Type == "" {
+		job.Type = listing.Field(FieldType, text)
+	}
+	if job.Vessel == "" {
+		job.Vessel = listing.Field(FieldVessel, text)
+	}
+
+	return []*models.Job{job}
+}
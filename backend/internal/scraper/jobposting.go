@@ -0,0 +1,407 @@
+package scraper
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/salary"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// jobPosting holds the fields read from a schema.org JobPosting embedded in a
+// page. Fields the posting does not state are empty.
+type jobPosting struct {
+	Title        string
+	Company      string
+	Location     string
+	Description  string
+	URL          string
+	PostedAt     time.Time
+	ValidThrough time.Time
+	Salary       *salary.Salary
+}
+
+// salaryUnits maps the unitText of a schema.org salary to salary periods
+var salaryUnits = map[string]string{
+	"DAY":   salary.PeriodDay,
+	"WEEK":  salary.PeriodWeek,
+	"MONTH": salary.PeriodMonth,
+	"YEAR":  salary.PeriodYear,
+}
+
+// findJobPostings returns the JobPostings a page embeds as JSON-LD or
+// microdata, skipping the ones without a title
+func findJobPostings(doc *goquery.Selection, base *url.URL) []jobPosting {
+	var entries []map[string]interface{}
+
+	doc.Find(`script[type="application/ld+json"]`).Each(func(_ int, script *goquery.Selection) {
+		var data interface{}
+		// Malformed blocks are common and ignored like any other page noise
+		if err := json.Unmarshal([]byte(script.Text()), &data); err != nil {
+			return
+		}
+		entries = append(entries, collectJobPostings(data)...)
+	})
+
+	doc.Find("[itemscope][itemtype]").Each(func(_ int, item *goquery.Selection) {
+		if isJobPostingType(item.AttrOr("itemtype", "")) {
+			entries = append(entries, microdataItem(item))
+		}
+	})
+
+	var postings []jobPosting
+	for _, entry := range entries {
+		if posting := newJobPosting(entry, base); posting.Title != "" {
+			postings = append(postings, posting)
+		}
+	}
+	return postings
+}
+
+// collectJobPostings walks decoded JSON-LD, including @graph and ItemList
+// wrappers, and returns the JobPosting objects in it
+func collectJobPostings(data interface{}) []map[string]interface{} {
+	switch value := data.(type) {
+	case []interface{}:
+		var postings []map[string]interface{}
+		for _, element := range value {
+			postings = append(postings, collectJobPostings(element)...)
+		}
+		return postings
+	case map[string]interface{}:
+		for _, typ := range ldList(value["@type"]) {
+			if name, ok := typ.(string); ok && isJobPostingType(name) {
+				return []map[string]interface{}{value}
+			}
+		}
+		var postings []map[string]interface{}
+		for _, child := range value {
+			postings = append(postings, collectJobPostings(child)...)
+		}
+		return postings
+	}
+	return nil
+}
+
+// isJobPostingType matches JobPosting types with or without a schema.org prefix
+func isJobPostingType(typ string) bool {
+	typ = strings.TrimSpace(typ)
+	if i := strings.LastIndexAny(typ, "/:"); i >= 0 {
+		typ = typ[i+1:]
+	}
+	return typ == "JobPosting"
+}
+
+// microdataItem reads the properties of a microdata item into the same shape
+// as JSON-LD, reading nested items recursively
+func microdataItem(item *goquery.Selection) map[string]interface{} {
+	entry := map[string]interface{}{
+		"@type": item.AttrOr("itemtype", ""),
+	}
+
+	item.Find("[itemprop]").Each(func(_ int, prop *goquery.Selection) {
+		// Properties of nested items belong to those items
+		if owner := prop.ParentsFiltered("[itemscope]").First(); owner.Length() == 0 || owner.Get(0) != item.Get(0) {
+			return
+		}
+
+		var value interface{}
+		if _, ok := prop.Attr("itemscope"); ok {
+			value = microdataItem(prop)
+		} else {
+			value = microdataValue(prop)
+		}
+		for _, name := range strings.Fields(prop.AttrOr("itemprop", "")) {
+			if _, ok := entry[name]; !ok {
+				entry[name] = value
+			}
+		}
+	})
+	return entry
+}
+
+// microdataValue returns the value of a microdata property element
+func microdataValue(prop *goquery.Selection) string {
+	if content, ok := prop.Attr("content"); ok {
+		return content
+	}
+	switch goquery.NodeName(prop) {
+	case "a", "link", "area":
+		return prop.AttrOr("href", "")
+	case "img", "audio", "video", "source":
+		return prop.AttrOr("src", "")
+	case "time":
+		if datetime, ok := prop.Attr("datetime"); ok {
+			return datetime
+		}
+	case "data", "meter":
+		return prop.AttrOr("value", "")
+	}
+	return elementText(prop)
+}
+
+// newJobPosting maps a JobPosting object to its job fields
+func newJobPosting(entry map[string]interface{}, base *url.URL) jobPosting {
+	posting := jobPosting{
+		Title:       ldString(entry["title"]),
+		Company:     ldString(entry["hiringOrganization"]),
+		Location:    jobPostingLocation(entry["jobLocation"]),
+		Description: htmlText(ldString(entry["description"])),
+		Salary:      jobPostingSalary(entry["baseSalary"]),
+	}
+	if posting.Title == "" {
+		posting.Title = ldString(entry["name"])
+	}
+
+	if postedAt, ok := parsePostedAt(ldString(entry["datePosted"]), ""); ok {
+		posting.PostedAt = postedAt
+	}
+	if validThrough, ok := parsePostedAt(ldString(entry["validThrough"]), ""); ok {
+		posting.ValidThrough = validThrough
+	}
+
+	if link := ldString(entry["url"]); link != "" && base != nil {
+		if resolved, err := base.Parse(link); err == nil {
+			posting.URL = resolved.String()
+		}
+	}
+	return posting
+}
+
+// jobPostingLocation joins the addresses of the places a job is located at
+func jobPostingLocation(value interface{}) string {
+	var locations []string
+	for _, place := range ldList(value) {
+		placeMap, ok := place.(map[string]interface{})
+		if !ok {
+			if text := ldString(place); text != "" {
+				locations = append(locations, text)
+			}
+			continue
+		}
+
+		address, ok := placeMap["address"].(map[string]interface{})
+		if !ok {
+			if text := ldString(placeMap["address"]); text != "" {
+				locations = append(locations, text)
+			} else if name := ldString(placeMap["name"]); name != "" {
+				locations = append(locations, name)
+			}
+			continue
+		}
+
+		var parts []string
+		for _, key := range []string{"addressLocality", "addressRegion", "addressCountry"} {
+			part := ldString(address[key])
+			if part != "" && (len(parts) == 0 || parts[len(parts)-1] != part) {
+				parts = append(parts, part)
+			}
+		}
+		if len(parts) > 0 {
+			locations = append(locations, strings.Join(parts, ", "))
+		}
+	}
+	return strings.Join(locations, "; ")
+}
+
+// jobPostingSalary reads a baseSalary MonetaryAmount, falling back to parsing
+// it as text when it is not structured
+func jobPostingSalary(value interface{}) *salary.Salary {
+	var amount map[string]interface{}
+	for _, element := range ldList(value) {
+		if m, ok := element.(map[string]interface{}); ok {
+			amount = m
+			break
+		}
+	}
+	if amount == nil {
+		if text := ldString(value); text != "" {
+			return salary.Parse(text)
+		}
+		return nil
+	}
+
+	var minValue, maxValue float64
+	var hasMin, hasMax bool
+	unit := ldString(amount["unitText"])
+
+	if quantity, ok := amount["value"].(map[string]interface{}); ok {
+		minValue, hasMin = ldNumber(quantity["minValue"])
+		maxValue, hasMax = ldNumber(quantity["maxValue"])
+		if single, ok := ldNumber(quantity["value"]); ok {
+			minValue, maxValue, hasMin, hasMax = single, single, true, true
+		}
+		if text := ldString(quantity["unitText"]); text != "" {
+			unit = text
+		}
+	} else if single, ok := ldNumber(amount["value"]); ok {
+		minValue, maxValue, hasMin, hasMax = single, single, true, true
+	}
+
+	switch {
+	case !hasMin && !hasMax:
+		return nil
+	case !hasMin:
+		minValue = maxValue
+	case !hasMax:
+		maxValue = minValue
+	}
+	if maxValue < minValue {
+		minValue, maxValue = maxValue, minValue
+	}
+
+	parsed := &salary.Salary{
+		Min:      minValue,
+		Max:      maxValue,
+		Currency: strings.ToUpper(ldString(amount["currency"])),
+		Period:   salaryUnits[strings.ToUpper(unit)],
+	}
+
+	text := strconv.FormatFloat(minValue, 'f', -1, 64)
+	if maxValue != minValue {
+		text += "-" + strconv.FormatFloat(maxValue, 'f', -1, 64)
+	}
+	if parsed.Currency != "" {
+		text = parsed.Currency + " " + text
+	}
+	if parsed.Period != "" {
+		text += " per " + parsed.Period
+	}
+	parsed.Text = text
+	return parsed
+}
+
+// ldList returns the elements of a JSON-LD value that may be a single value or an array
+func ldList(value interface{}) []interface{} {
+	if list, ok := value.([]interface{}); ok {
+		return list
+	}
+	if value == nil {
+		return nil
+	}
+	return []interface{}{value}
+}
+
+// ldString returns a JSON-LD value as text. Objects such as organizations and
+// countries are represented by their name.
+func ldString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]interface{}:
+		if name := ldString(v["name"]); name != "" {
+			return name
+		}
+		return ldString(v["@value"])
+	case []interface{}:
+		for _, element := range v {
+			if text := ldString(element); text != "" {
+				return text
+			}
+		}
+	}
+	return ""
+}
+
+// ldNumber returns a JSON-LD value as a number, accepting numeric strings
+func ldNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		cleaned := strings.NewReplacer(",", "", " ", "").Replace(v)
+		if number, err := strconv.ParseFloat(cleaned, 64); err == nil {
+			return number, true
+		}
+	}
+	return 0, false
+}
+
+// htmlText returns the text of an HTML fragment, such as a JobPosting description
+func htmlText(fragment string) string {
+	if fragment == "" {
+		return ""
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(fragment))
+	if err != nil {
+		return fragment
+	}
+	return elementText(doc.Find("body"))
+}
+
+// applyJobPosting replaces the fields of a scraped job with the ones a
+// JobPosting states. The job keeps its URL when it has one.
+func applyJobPosting(job *ScrapedJob, posting jobPosting) {
+	if posting.Title != "" {
+		job.Title = posting.Title
+	}
+	if posting.Company != "" {
+		job.Company = posting.Company
+	}
+	if posting.Location != "" {
+		job.Location = posting.Location
+	}
+	if posting.Description != "" {
+		job.Description = posting.Description
+	}
+	if posting.URL != "" && job.URL == "" {
+		job.URL = posting.URL
+	}
+	if !posting.PostedAt.IsZero() {
+		job.PostedAt = posting.PostedAt
+	}
+	if !posting.ValidThrough.IsZero() {
+		job.ValidThrough = posting.ValidThrough
+	}
+	if posting.Salary != nil {
+		job.Salary = posting.Salary.Text
+		job.ParsedSalary = posting.Salary
+	}
+}
+
+// mergeJobPostings applies the JobPostings of a listing page to the listings
+// linking to the same URL, and adds the postings no listing links to
+func mergeJobPostings(listings []*ScrapedJob, postings []jobPosting) []*ScrapedJob {
+	byURL := make(map[string]*ScrapedJob)
+	for _, job := range listings {
+		if job.URL != "" {
+			byURL[postingKey(job.URL)] = job
+		}
+	}
+
+	for _, posting := range postings {
+		if job, ok := byURL[postingKey(posting.URL)]; ok && posting.URL != "" {
+			applyJobPosting(job, posting)
+			continue
+		}
+		job := &ScrapedJob{PostedAt: time.Now()}
+		applyJobPosting(job, posting)
+		listings = append(listings, job)
+	}
+	return listings
+}
+
+// pageJobPosting returns the JobPosting of a detail page: the one linking to
+// the page, or the only one on it
+func pageJobPosting(postings []jobPosting, pageURL string) (jobPosting, bool) {
+	for _, posting := range postings {
+		if posting.URL != "" && postingKey(posting.URL) == postingKey(pageURL) {
+			return posting, true
+		}
+	}
+	if len(postings) == 1 {
+		return postings[0], true
+	}
+	return jobPosting{}, false
+}
+
+// postingKey normalizes a job URL for matching listings to postings
+func postingKey(rawURL string) string {
+	rawURL, _, _ = strings.Cut(rawURL, "#")
+	return strings.TrimSuffix(strings.ToLower(rawURL), "/")
+}
@@ -0,0 +1,204 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryConfig configures how failed scraper HTTP calls are retried
+type RetryConfig struct {
+	// MaxAttempts includes the first attempt
+	MaxAttempts int
+	// AttemptTimeout bounds each attempt, including reading the response body
+	AttemptTimeout time.Duration
+	BaseDelay      time.Duration
+	MaxDelay       time.Duration
+	// MaxRetryAfter is the longest Retry-After that is waited for. Responses
+	// asking for a longer wait are returned as they are.
+	MaxRetryAfter time.Duration
+}
+
+// DefaultRetryConfig retries a call up to three times over about half a minute
+var DefaultRetryConfig = RetryConfig{
+	MaxAttempts:    4,
+	AttemptTimeout: 30 * time.Second,
+	BaseDelay:      time.Second,
+	MaxDelay:       30 * time.Second,
+	MaxRetryAfter:  2 * time.Minute,
+}
+
+// retryTransport retries requests that timed out or got a 429 or 5xx
+// response, backing off exponentially with jitter and honoring Retry-After.
+// Requests that are not idempotent are only retried when they were rejected
+// with a 429, since they may have been processed otherwise.
+type retryTransport struct {
+	next   http.RoundTripper
+	config RetryConfig
+	// wait, when set, is called before every attempt, e.g. to honor a rate limit
+	wait func(req *http.Request) error
+}
+
+// newRetryTransport wraps next, or the default transport when nil
+func newRetryTransport(next http.RoundTripper, config RetryConfig) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &retryTransport{next: next, config: config}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	for attempt := 1; ; attempt++ {
+		// Waiting is not part of the attempt, so it does not eat into its timeout
+		if t.wait != nil {
+			if err := t.wait(req); err != nil {
+				return nil, err
+			}
+		}
+
+		attemptCtx, cancel := context.WithTimeout(ctx, t.config.AttemptTimeout)
+		attemptReq := req.Clone(attemptCtx)
+		if attempt > 1 && req.Body != nil {
+			if req.GetBody == nil {
+				cancel()
+				return nil, errors.New("cannot retry request with a body that cannot be rewound")
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			attemptReq.Body = body
+		}
+
+		resp, err := t.next.RoundTrip(attemptReq)
+		if attempt >= t.config.MaxAttempts || !t.shouldRetry(req, resp, err) {
+			if resp == nil {
+				cancel()
+				return nil, err
+			}
+			// The attempt deadline also covers reading the body
+			resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+			return resp, err
+		}
+
+		delay := t.backoff(attempt)
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				if retryAfter > t.config.MaxRetryAfter {
+					resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+					return resp, nil
+				}
+				delay = retryAfter
+			}
+			// Drain the body so the connection can be reused
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+			resp.Body.Close()
+		}
+		cancel()
+
+		reason := "error"
+		if resp != nil {
+			reason = "status " + strconv.Itoa(resp.StatusCode)
+		} else if err != nil {
+			reason = err.Error()
+		}
+		log.Printf("🔁 Retrying %s %s in %s (attempt %d/%d): %s",
+			req.Method, req.URL.Redacted(), delay.Round(time.Millisecond), attempt+1, t.config.MaxAttempts, reason)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// shouldRetry reports whether a failed attempt is worth retrying
+func (t *retryTransport) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		return isIdempotent(req.Method) && isTransientError(err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return true
+	case resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented:
+		return isIdempotent(req.Method)
+	}
+	return false
+}
+
+// backoff returns the delay before the next attempt, using full jitter
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	ceiling := t.config.BaseDelay << (attempt - 1)
+	if ceiling <= 0 || ceiling > t.config.MaxDelay {
+		ceiling = t.config.MaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(ceiling)) + 1)
+}
+
+// cancelBody ends the attempt context of a response once its body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// isTransientError reports whether a transport error may go away on retry,
+// such as a timeout or a dropped connection
+func isTransientError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as a date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
@@ -0,0 +1,697 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/language"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed default_rules.yaml
+var defaultRulesYAML []byte
+
+// Rule extractors used by the sources
+const (
+	postRules    = "post"
+	listingRules = "listing"
+)
+
+// Fields a rule set can extract
+const (
+	FieldTitle   = "title"
+	FieldCompany = "company"
+	FieldType    = "type"
+	FieldVessel  = "vessel"
+)
+
+var ruleFields = map[string]bool{FieldTitle: true, FieldCompany: true, FieldType: true, FieldVessel: true}
+
+// requiredExtractors must be present in every rule set
+var requiredExtractors = []string{postRules, listingRules}
+
+// RulesError lists every problem found in a rules file
+type RulesError struct {
+	Problems []string
+}
+
+func (e *RulesError) Error() string {
+	return "invalid rules: " + strings.Join(e.Problems, "; ")
+}
+
+// rulesFile is the YAML layout of a rules file
+type rulesFile struct {
+	Version    int                      `yaml:"version"`
+	Extractors map[string]extractorSpec `yaml:"extractors"`
+}
+
+type extractorSpec struct {
+	Filter *filterSpec          `yaml:"filter"`
+	Fields map[string]fieldSpec `yaml:"fields"`
+}
+
+type filterSpec struct {
+	Require []ruleSpec `yaml:"require"`
+	Exclude []string   `yaml:"exclude"`
+}
+
+type fieldSpec struct {
+	Default string     `yaml:"default"`
+	Rules   []ruleSpec `yaml:"rules"`
+}
+
+type ruleSpec struct {
+	Name     string   `yaml:"name"`
+	Output   string   `yaml:"output"`
+	Priority int      `yaml:"priority"`
+	Keywords []string `yaml:"keywords"`
+	Patterns []string `yaml:"patterns"`
+	Exclude  []string `yaml:"exclude"`
+}
+
+// RuleSet is a compiled rules file
+type RuleSet struct {
+	Version    int
+	extractors map[string]*Extractor
+}
+
+// Extractor extracts job fields from one kind of scraped text
+type Extractor struct {
+	require []*rule
+	exclude []*regexp.Regexp
+	fields  map[string]*field
+}
+
+type field struct {
+	fallback string
+	rules    []*rule
+}
+
+type rule struct {
+	name     string
+	output   string
+	priority int
+	matchers []*regexp.Regexp
+	exclude  []*regexp.Regexp
+}
+
+// ParseRules parses and compiles a rules file, reporting every problem found
+func ParseRules(data []byte) (*RuleSet, error) {
+	var file rulesFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, &RulesError{Problems: []string{err.Error()}}
+	}
+
+	var problems []string
+	addProblem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if file.Version != 1 {
+		addProblem("version: unsupported version %d", file.Version)
+	}
+	for _, name := range requiredExtractors {
+		if _, ok := file.Extractors[name]; !ok {
+			addProblem("extractors.%s: missing", name)
+		}
+	}
+
+	set := &RuleSet{
+		Version:    file.Version,
+		extractors: make(map[string]*Extractor),
+	}
+	for _, name := range sortedKeys(file.Extractors) {
+		spec := file.Extractors[name]
+		path := "extractors." + name
+		extractor := &Extractor{fields: make(map[string]*field)}
+
+		if spec.Filter != nil {
+			for i, spec := range spec.Filter.Require {
+				r, err := compileRule(spec)
+				if err != nil {
+					addProblem("%s.filter.require[%d]: %v", path, i, err)
+					continue
+				}
+				extractor.require = append(extractor.require, r)
+			}
+			exclude, err := compileKeywords(spec.Filter.Exclude)
+			if err != nil {
+				addProblem("%s.filter.exclude: %v", path, err)
+			}
+			extractor.exclude = exclude
+		}
+
+		for _, fieldName := range sortedKeys(spec.Fields) {
+			fieldSpec := spec.Fields[fieldName]
+			fieldPath := path + ".fields." + fieldName
+			if !ruleFields[fieldName] {
+				addProblem("%s: unknown field", fieldPath)
+				continue
+			}
+
+			f := &field{fallback: fieldSpec.Default}
+			for i, spec := range fieldSpec.Rules {
+				if strings.TrimSpace(spec.Output) == "" {
+					addProblem("%s.rules[%d]: output is required", fieldPath, i)
+					continue
+				}
+				r, err := compileRule(spec)
+				if err != nil {
+					addProblem("%s.rules[%d]: %v", fieldPath, i, err)
+					continue
+				}
+				f.rules = append(f.rules, r)
+			}
+
+			// Higher priorities first, file order otherwise
+			sort.SliceStable(f.rules, func(i, j int) bool {
+				return f.rules[i].priority > f.rules[j].priority
+			})
+			extractor.fields[fieldName] = f
+		}
+
+		set.extractors[name] = extractor
+	}
+
+	// Language extractors such as "post.fr" add to the rules of their base
+	for _, name := range sortedKeys(set.extractors) {
+		base, lang, ok := strings.Cut(name, ".")
+		if !ok {
+			continue
+		}
+		if !language.IsLanguage(lang) {
+			addProblem("extractors.%s: unsupported language %q", name, lang)
+			continue
+		}
+		if _, ok := set.extractors[base]; !ok {
+			addProblem("extractors.%s: unknown base extractor %q", name, base)
+			continue
+		}
+		set.extractors[name] = mergeExtractors(set.extractors[base], set.extractors[name])
+	}
+
+	if len(problems) > 0 {
+		return nil, &RulesError{Problems: problems}
+	}
+	return set, nil
+}
+
+// mergeExtractors combines a base extractor with the one of a language. The
+// language rules of a field are tried before the base ones of the same
+// priority, and its filter groups widen the base groups of the same name.
+func mergeExtractors(base, lang *Extractor) *Extractor {
+	merged := &Extractor{
+		exclude: append(append([]*regexp.Regexp{}, base.exclude...), lang.exclude...),
+		fields:  make(map[string]*field),
+	}
+
+	groups := make(map[string]*rule)
+	for _, r := range lang.require {
+		if r.name != "" {
+			groups[r.name] = r
+		}
+	}
+	for _, r := range base.require {
+		if group, ok := groups[r.name]; ok && r.name != "" {
+			widened := *r
+			widened.matchers = append(append([]*regexp.Regexp{}, group.matchers...), r.matchers...)
+			widened.exclude = append(append([]*regexp.Regexp{}, r.exclude...), group.exclude...)
+			merged.require = append(merged.require, &widened)
+			delete(groups, r.name)
+			continue
+		}
+		merged.require = append(merged.require, r)
+	}
+	for _, r := range lang.require {
+		if _, ok := groups[r.name]; ok || r.name == "" {
+			merged.require = append(merged.require, r)
+		}
+	}
+
+	for name, f := range base.fields {
+		merged.fields[name] = f
+	}
+	for name, f := range lang.fields {
+		combined := &field{fallback: f.fallback, rules: append([]*rule{}, f.rules...)}
+		if baseField, ok := base.fields[name]; ok {
+			if combined.fallback == "" {
+				combined.fallback = baseField.fallback
+			}
+			combined.rules = append(combined.rules, baseField.rules...)
+		}
+		sort.SliceStable(combined.rules, func(i, j int) bool {
+			return combined.rules[i].priority > combined.rules[j].priority
+		})
+		merged.fields[name] = combined
+	}
+	return merged
+}
+
+func compileRule(spec ruleSpec) (*rule, error) {
+	if len(spec.Keywords) == 0 && len(spec.Patterns) == 0 {
+		return nil, fmt.Errorf("needs keywords or patterns")
+	}
+
+	matchers, err := compileKeywords(spec.Keywords)
+	if err != nil {
+		return nil, err
+	}
+	for _, pattern := range spec.Patterns {
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+		matchers = append(matchers, regex)
+	}
+
+	exclude, err := compileKeywords(spec.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rule{
+		name:     spec.Name,
+		output:   strings.TrimSpace(spec.Output),
+		priority: spec.Priority,
+		matchers: matchers,
+		exclude:  exclude,
+	}, nil
+}
+
+// compileKeywords compiles keywords into case-insensitive whole word matchers.
+// Keywords starting or ending with an accented letter, which \b does not
+// treat as a word character, are bounded by any character but a letter.
+func compileKeywords(keywords []string) ([]*regexp.Regexp, error) {
+	regexes := make([]*regexp.Regexp, 0, len(keywords))
+	for _, keyword := range keywords {
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" {
+			return nil, fmt.Errorf("empty keyword")
+		}
+
+		pattern := strings.Join(strings.Fields(regexp.QuoteMeta(keyword)), `\s+`)
+		first, _ := utf8.DecodeRuneInString(keyword)
+		last, _ := utf8.DecodeLastRuneInString(keyword)
+		switch {
+		case first >= utf8.RuneSelf && isWordChar(first):
+			pattern = `(?:^|[^\p{L}\p{N}_])` + pattern
+		case isWordChar(first):
+			pattern = `\b` + pattern
+		}
+		switch {
+		case last >= utf8.RuneSelf && isWordChar(last):
+			pattern += `(?:[^\p{L}\p{N}_]|$)`
+		case isWordChar(last):
+			pattern += `\b`
+		}
+
+		regex, err := regexp.Compile(`(?i)` + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid keyword %q: %v", keyword, err)
+		}
+		regexes = append(regexes, regex)
+	}
+	return regexes, nil
+}
+
+func isWordChar(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Extractor returns the named extractor, or nil if the rule set has none
+func (s *RuleSet) Extractor(name string) *Extractor {
+	return s.extractors[name]
+}
+
+// Extractors returns the names of every extractor in the rule set
+func (s *RuleSet) Extractors() []string {
+	return sortedKeys(s.extractors)
+}
+
+// Accepts reports whether text passes the extractor filter
+func (e *Extractor) Accepts(text string) bool {
+	if e == nil {
+		return false
+	}
+	for _, r := range e.require {
+		if _, ok := r.match(text); !ok {
+			return false
+		}
+	}
+	return !anyMatch(e.exclude, text)
+}
+
+// Field returns the value of a field extracted from text
+func (e *Extractor) Field(name, text string) string {
+	if e == nil {
+		return ""
+	}
+	f, ok := e.fields[name]
+	if !ok {
+		return ""
+	}
+	for _, r := range f.rules {
+		if output, ok := r.match(text); ok {
+			return output
+		}
+	}
+	return f.fallback
+}
+
+// FieldMatch is a field value found in text, at text[Start:End]
+type FieldMatch struct {
+	Value string
+	Start int
+	End   int
+}
+
+// FieldMatches returns every occurrence of a field's values in text, in text
+// order. Where occurrences overlap the rule tried first wins, so "chief stew"
+// is not also read as "stew".
+func (e *Extractor) FieldMatches(name, text string) []FieldMatch {
+	if e == nil {
+		return nil
+	}
+	f, ok := e.fields[name]
+	if !ok {
+		return nil
+	}
+
+	var matches []FieldMatch
+	for _, r := range f.rules {
+		if anyMatch(r.exclude, text) {
+			continue
+		}
+		for _, regex := range r.matchers {
+			for _, submatches := range regex.FindAllStringSubmatchIndex(text, -1) {
+				match := FieldMatch{Value: r.output, Start: submatches[0], End: submatches[1]}
+				if strings.Contains(r.output, "$") {
+					match.Value = strings.TrimSpace(string(regex.ExpandString(nil, r.output, text, submatches)))
+				}
+				if !overlapsAny(matches, match) {
+					matches = append(matches, match)
+				}
+			}
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Start < matches[j].Start })
+	return matches
+}
+
+func overlapsAny(matches []FieldMatch, match FieldMatch) bool {
+	for _, m := range matches {
+		if match.Start < m.End && m.Start < match.End {
+			return true
+		}
+	}
+	return false
+}
+
+// match returns the rule output when the rule matches text
+func (r *rule) match(text string) (string, bool) {
+	if anyMatch(r.exclude, text) {
+		return "", false
+	}
+	for _, regex := range r.matchers {
+		submatches := regex.FindStringSubmatchIndex(text)
+		if submatches == nil {
+			continue
+		}
+		if !strings.Contains(r.output, "$") {
+			return r.output, true
+		}
+		return strings.TrimSpace(string(regex.ExpandString(nil, r.output, text, submatches))), true
+	}
+	return "", false
+}
+
+func anyMatch(regexes []*regexp.Regexp, text string) bool {
+	for _, regex := range regexes {
+		if regex.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// RulesConfig configures where extraction rules are loaded from
+type RulesConfig struct {
+	// Path of the rules file, the embedded defaults are used when empty
+	Path string
+	// ReloadInterval is how often the file is checked for changes
+	ReloadInterval time.Duration
+}
+
+// NewRulesConfig reads the rules file path from SCRAPER_RULES_FILE
+func NewRulesConfig() RulesConfig {
+	return RulesConfig{
+		Path:           os.Getenv("SCRAPER_RULES_FILE"),
+		ReloadInterval: 30 * time.Second,
+	}
+}
+
+// RulesSummary describes a rule set
+type RulesSummary struct {
+	Version    int      `json:"version"`
+	Checksum   string   `json:"checksum"`
+	Extractors []string `json:"extractors"`
+}
+
+// RulesStatus describes the active rule set
+type RulesStatus struct {
+	RulesSummary
+	Source   string    `json:"source"` // File path, "embedded" or "api"
+	LoadedAt time.Time `json:"loaded_at"`
+}
+
+// RuleEngine holds the active rule set and reloads it when the rules file changes
+type RuleEngine struct {
+	config RulesConfig
+
+	mu       sync.RWMutex
+	rules    *RuleSet
+	raw      []byte
+	source   string
+	modTime  time.Time
+	loadedAt time.Time
+}
+
+// NewRuleEngine loads the configured rules file, falling back to the
+// embedded defaults when it is missing or invalid
+func NewRuleEngine(config RulesConfig) (*RuleEngine, error) {
+	if config.ReloadInterval <= 0 {
+		config.ReloadInterval = 30 * time.Second
+	}
+	e := &RuleEngine{config: config}
+
+	defaults, err := ParseRules(defaultRulesYAML)
+	if err != nil {
+		return nil, fmt.Errorf("embedded rules: %w", err)
+	}
+	e.set(defaults, defaultRulesYAML, "embedded", time.Time{})
+
+	if config.Path != "" {
+		if _, err := e.reload(); err != nil {
+			log.Printf("Warning: using embedded extraction rules: %v", err)
+		}
+	}
+	return e, nil
+}
+
+// Extractor returns the named extractor of the active rule set
+func (e *RuleEngine) Extractor(name string) *Extractor {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.rules.Extractor(name)
+}
+
+// LanguageExtractor returns the named extractor for text in the given
+// language, such as "post.fr", or the named one when there is none
+func (e *RuleEngine) LanguageExtractor(name, lang string) *Extractor {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if extractor := e.rules.Extractor(name + "." + lang); extractor != nil && lang != "" {
+		return extractor
+	}
+	return e.rules.Extractor(name)
+}
+
+// Status describes the active rule set
+func (e *RuleEngine) Status() RulesStatus {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return RulesStatus{
+		RulesSummary: summarize(e.rules, e.raw),
+		Source:       e.source,
+		LoadedAt:     e.loadedAt,
+	}
+}
+
+// Raw returns the YAML of the active rule set
+func (e *RuleEngine) Raw() []byte {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.raw
+}
+
+// Validate checks a rules file without activating it
+func (e *RuleEngine) Validate(data []byte) (RulesSummary, error) {
+	rules, err := ParseRules(data)
+	if err != nil {
+		return RulesSummary{}, err
+	}
+	return summarize(rules, data), nil
+}
+
+// Activate validates a rules file and makes it the active rule set. It is
+// written to the rules file when one is configured so it survives restarts.
+func (e *RuleEngine) Activate(data []byte) (RulesStatus, error) {
+	rules, err := ParseRules(data)
+	if err != nil {
+		return RulesStatus{}, err
+	}
+
+	source, modTime := "api", time.Time{}
+	if e.config.Path != "" {
+		if err := writeFileAtomic(e.config.Path, data); err != nil {
+			return RulesStatus{}, fmt.Errorf("failed to write rules file: %w", err)
+		}
+		source = e.config.Path
+		if info, err := os.Stat(e.config.Path); err == nil {
+			modTime = info.ModTime()
+		}
+	}
+
+	e.set(rules, data, source, modTime)
+	log.Printf("📏 Activated extraction rules %s", rulesChecksum(data))
+	return e.Status(), nil
+}
+
+// Watch reloads the rules file whenever it changes until ctx is cancelled.
+// An invalid file is reported and the previous rules stay active.
+func (e *RuleEngine) Watch(ctx context.Context) {
+	if e.config.Path == "" {
+		return
+	}
+
+	ticker := time.NewTicker(e.config.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		reloaded, err := e.reload()
+		if err != nil {
+			log.Printf("⚠️ Keeping previous extraction rules: %v", err)
+			continue
+		}
+		if reloaded {
+			log.Printf("📏 Reloaded extraction rules from %s", e.config.Path)
+		}
+	}
+}
+
+// reload loads the rules file if it changed since it was last loaded
+func (e *RuleEngine) reload() (bool, error) {
+	info, err := os.Stat(e.config.Path)
+	if err != nil {
+		return false, err
+	}
+
+	e.mu.RLock()
+	unchanged := info.ModTime().Equal(e.modTime)
+	e.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(e.config.Path)
+	if err != nil {
+		return false, err
+	}
+	rules, err := ParseRules(data)
+	if err != nil {
+		// Remember the broken file so it is only reported once
+		e.mu.Lock()
+		e.modTime = info.ModTime()
+		e.mu.Unlock()
+		return false, fmt.Errorf("%s: %w", e.config.Path, err)
+	}
+
+	e.set(rules, data, e.config.Path, info.ModTime())
+	return true, nil
+}
+
+func (e *RuleEngine) set(rules *RuleSet, raw []byte, source string, modTime time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rules = rules
+	e.raw = raw
+	e.source = source
+	e.modTime = modTime
+	e.loadedAt = time.Now()
+}
+
+func summarize(rules *RuleSet, raw []byte) RulesSummary {
+	return RulesSummary{
+		Version:    rules.Version,
+		Checksum:   rulesChecksum(raw),
+		Extractors: rules.Extractors(),
+	}
+}
+
+func rulesChecksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// writeFileAtomic replaces a file so readers never see it half written
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".rules-*.yaml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -1,21 +1,60 @@
 package scraper
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
-	"net/http"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/contract"
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/geo"
 	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/models"
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/salary"
 	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/services"
-	"github.com/PuerkitoBio/goquery"
-	"github.com/google/uuid"
 )
 
+// ErrCircuitOpen is returned when scraping a source whose circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitConfig configures the circuit breaker that stops scraping a source
+// that keeps failing
+type CircuitConfig struct {
+	// Threshold is the number of consecutive failed runs that open the
+	// circuit of a source; 0 disables the breaker
+	Threshold int
+}
+
+// NewCircuitConfig reads the failure threshold from SCRAPER_CIRCUIT_THRESHOLD
+func NewCircuitConfig() CircuitConfig {
+	config := CircuitConfig{
+		Threshold: 5,
+	}
+
+	if value := os.Getenv("SCRAPER_CIRCUIT_THRESHOLD"); value != "" {
+		if threshold, err := strconv.Atoi(value); err == nil && threshold >= 0 {
+			config.Threshold = threshold
+		} else {
+			log.Printf("Invalid SCRAPER_CIRCUIT_THRESHOLD %q", value)
+		}
+	}
+	return config
+}
+
 type ScraperService struct {
 	jobService *services.JobService
-	client     *http.Client
+	runService *services.ScrapeRunService
+	actorRuns  *services.ActorRunService
+	circuits   *services.CircuitService
+	circuit    CircuitConfig
+	registry   *Registry
+	spam       *SpamClassifier
+	quarantine *services.QuarantineService
+	crew       *services.CrewAvailabilityService
+	claims     *RunClaims
 }
 
 type ScrapedJob struct {
@@ -29,215 +68,430 @@ type ScrapedJob struct {
 	Description  string
 	Requirements string
 	URL          string
+	Source       string // Display name of the job board
 	PostedAt     time.Time
+	ValidThrough time.Time      // Zero when the board does not state it
+	ParsedSalary *salary.Salary // Structured salary, e.g. from JobPosting data
 }
 
-func NewScraperService(jobService *services.JobService) *ScraperService {
+func NewScraperService(
+	jobService *services.JobService,
+	runService *services.ScrapeRunService,
+	actorRuns *services.ActorRunService,
+	circuits *services.CircuitService,
+	circuit CircuitConfig,
+	registry *Registry,
+	spam *SpamClassifier,
+	quarantine *services.QuarantineService,
+	crew *services.CrewAvailabilityService,
+	claims *RunClaims,
+) *ScraperService {
 	return &ScraperService{
 		jobService: jobService,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		runService: runService,
+		actorRuns:  actorRuns,
+		circuits:   circuits,
+		circuit:    circuit,
+		registry:   registry,
+		spam:       spam,
+		quarantine: quarantine,
+		crew:       crew,
+		claims:     claims,
 	}
 }
 
-// ScrapeJobs orchestrates scraping from multiple sources
-func (s *ScraperService) ScrapeJobs() error {
+// ScrapeJobs orchestrates scraping from every enabled source
+func (s *ScraperService) ScrapeJobs(ctx context.Context) error {
 	log.Println("Starting job scraping...")
 
-	// Example marine job sites (you can replace with actual sites)
-	sources := []struct {
-		name string
-		url  string
-	}{
-		{"Maritime Jobs", "https://example-maritime-jobs.com"},
-		{"Seaman Jobs", "https://example-seaman-jobs.com"},
-		{"Offshore Jobs", "https://example-offshore-jobs.com"},
+	sources := s.closedSources(s.registry.Enabled())
+	if len(sources) == 0 {
+		log.Println("No scraper sources to run")
+		return nil
+	}
+	return s.run(ctx, sources)
+}
+
+// ScrapeSource scrapes a single registered source by name
+func (s *ScraperService) ScrapeSource(ctx context.Context, name string) error {
+	source, ok := s.registry.Get(name)
+	if !ok {
+		return fmt.Errorf("unknown scraper source %q", name)
 	}
+	if len(s.closedSources([]Source{source})) == 0 {
+		return fmt.Errorf("%w for %s", ErrCircuitOpen, name)
+	}
+	return s.run(ctx, []Source{source})
+}
 
+// closedSources returns the sources whose circuit breaker is closed. A source
+// is kept when its circuit cannot be read, so a database hiccup does not stop
+// scraping.
+func (s *ScraperService) closedSources(sources []Source) []Source {
+	var closed []Source
 	for _, source := range sources {
-		log.Printf("Scraping from %s...", source.name)
-		
-		jobs, err := s.scrapeSource(source.name, source.url)
+		open, err := s.circuits.IsOpen(source.Name())
 		if err != nil {
-			log.Printf("Error scraping %s: %v", source.name, err)
+			log.Printf("Error reading circuit of %s: %v", source.Name(), err)
+		}
+		if open {
+			log.Printf("⛔ Skipping %s, its circuit breaker is open", source.Name())
 			continue
 		}
+		closed = append(closed, source)
+	}
+	return closed
+}
 
-		for _, job := range jobs {
-			if err := s.saveJob(job, source.name); err != nil {
-				log.Printf("Error saving job: %v", err)
-			}
+// recordOutcome updates the circuit breaker of a source after it ran
+func (s *ScraperService) recordOutcome(stats *models.ScrapeRunSource) {
+	failed := stats.Status == models.ScrapeStatusFailed
+	opened, err := s.circuits.RecordRun(stats.Source, failed, strings.Join(stats.Errors, "; "), s.circuit.Threshold)
+	if err != nil {
+		log.Printf("Error recording circuit of %s: %v", stats.Source, err)
+		return
+	}
+	if opened {
+		log.Printf("⛔ Circuit breaker of %s opened after %d failed runs, skipping it until it is reset",
+			stats.Source, s.circuit.Threshold)
+	}
+}
+
+// run scrapes the given sources and records the outcome as a scrape run
+func (s *ScraperService) run(ctx context.Context, sources []Source) error {
+	run, err := s.runService.StartRun()
+	if err != nil {
+		return err
+	}
+
+	for _, source := range sources {
+		if ctx.Err() != nil {
+			break
+		}
+
+		stats := s.scrapeSource(ctx, run.ID, source, source.Fetch)
+		if err := s.runService.SaveRunSource(stats); err != nil {
+			log.Printf("Error recording scrape stats for %s: %v", source.Name(), err)
+		}
+		// A run cut short by shutdown says nothing about the source
+		if ctx.Err() == nil {
+			s.recordOutcome(stats)
 		}
+		run.Sources = append(run.Sources, *stats)
+	}
 
-		log.Printf("Scraped %d jobs from %s", len(jobs), source.name)
-		
-		// Be respectful - add delay between requests
-		time.Sleep(2 * time.Second)
+	if err := s.runService.FinishRun(run); err != nil {
+		return err
 	}
 
-	log.Println("Job scraping completed")
-	return nil
+	log.Printf("📊 Scrape run %s %s: %d posts, jobs saved: %d/%d, %d errors",
+		run.ID, run.Status, run.PostsFetched, run.JobsSaved, run.JobsExtracted, run.ErrorCount)
+	return ctx.Err()
 }
 
-// scrapeSource scrapes jobs from a specific source
-func (s *ScraperService) scrapeSource(sourceName, url string) ([]ScrapedJob, error) {
-	resp, err := s.client.Get(url)
+// ResumeRun collects a remote run that no in-flight fetch is waiting for,
+// such as one started before the server restarted, and saves its jobs
+func (s *ScraperService) ResumeRun(ctx context.Context, runID string) error {
+	record, err := s.actorRuns.GetRun(runID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+		return fmt.Errorf("unknown actor run %s: %w", runID, err)
+	}
+	if record.CollectedAt != nil {
+		return nil
+	}
+
+	source, ok := s.registry.Get(record.Source)
+	if !ok {
+		return fmt.Errorf("unknown scraper source %q for actor run %s", record.Source, runID)
+	}
+	resumable, ok := source.(ResumableSource)
+	if !ok {
+		return fmt.Errorf("scraper source %q cannot resume runs", record.Source)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("bad status code %d for %s", resp.StatusCode, url)
+	// A fetch or another resume is already collecting the run
+	if s.claims.Claimed(runID) {
+		return nil
 	}
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	log.Printf("Resuming %s actor run %s", record.Source, runID)
+
+	run, err := s.runService.StartRun()
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+		return err
 	}
 
-	var jobs []ScrapedJob
+	stats := s.scrapeSource(ctx, run.ID, source, func(ctx context.Context) ([]Item, error) {
+		return resumable.Resume(ctx, runID)
+	})
+	if err := s.runService.SaveRunSource(stats); err != nil {
+		log.Printf("Error recording scrape stats for %s: %v", source.Name(), err)
+	}
+	run.Sources = append(run.Sources, *stats)
 
-	// Generic scraping logic - customize based on actual site structure
-	doc.Find(".job-listing, .job-item, .job-card").Each(func(i int, sel *goquery.Selection) {
-		job := ScrapedJob{
-			Title:        strings.TrimSpace(sel.Find(".job-title, h2, h3").First().Text()),
-			Company:      strings.TrimSpace(sel.Find(".company, .employer").First().Text()),
-			Location:     strings.TrimSpace(sel.Find(".location, .job-location").First().Text()),
-			Description:  strings.TrimSpace(sel.Find(".description, .job-description").First().Text()),
-			PostedAt:     time.Now(), // Default to now if can't parse
-		}
+	return s.runService.FinishRun(run)
+}
+
+// ResumePendingRuns resumes every recent remote run that was never collected
+func (s *ScraperService) ResumePendingRuns(ctx context.Context) {
+	pending, err := s.actorRuns.ListPending()
+	if err != nil {
+		log.Printf("Error listing pending actor runs: %v", err)
+		return
+	}
 
-		// Extract job URL
-		if link, exists := sel.Find("a").First().Attr("href"); exists {
-			if strings.HasPrefix(link, "/") {
-				job.URL = url + link
-			} else {
-				job.URL = link
+	for _, record := range pending {
+		go func(runID string) {
+			if err := s.ResumeRun(ctx, runID); err != nil {
+				log.Printf("Error resuming actor run %s: %v", runID, err)
 			}
+		}(record.RunID)
+	}
+}
+
+// scrapeSource fetches a source, parses its items and saves the resulting jobs
+func (s *ScraperService) scrapeSource(
+	ctx context.Context,
+	runID string,
+	source Source,
+	fetch func(ctx context.Context) ([]Item, error),
+) *models.ScrapeRunSource {
+	log.Printf("Scraping from %s...", source.Name())
+
+	stats := &models.ScrapeRunSource{
+		RunID:     runID,
+		Source:    source.Name(),
+		Status:    models.ScrapeStatusSucceeded,
+		StartedAt: time.Now(),
+	}
+	defer func() {
+		now := time.Now()
+		stats.FinishedAt = &now
+	}()
+
+	fetched := &fetchStats{}
+	commits := &fetchCommits{}
+	items, err := fetch(withFetchCommits(withFetchStats(ctx, fetched), commits))
+	stats.BlockedFetches = int(fetched.blocked.Load())
+	if err != nil {
+		log.Printf("Error scraping %s: %v", source.Name(), err)
+		stats.Status = models.ScrapeStatusFailed
+		stats.ErrorCount++
+		stats.Errors = append(stats.Errors, err.Error())
+		return stats
+	}
+	stats.PostsFetched = len(items)
+
+	var failed []Item
+	for _, item := range items {
+		if !s.saveItem(source, item, stats) {
+			failed = append(failed, item)
 		}
+	}
+
+	// Progress is recorded after saving, so items that failed or were never
+	// reached because the process died are fetched again on the next run
+	if len(failed) > 0 {
+		log.Printf("⏪ %d items from %s failed to save and will be fetched again", len(failed), source.Name())
+	}
+	commits.commit(failed)
 
-		// Extract marine-specific details
-		job.Type = s.extractJobType(job.Title, job.Description)
-		job.Vessel = s.extractVesselType(job.Title, job.Description)
-		job.Duration = s.extractDuration(job.Description)
-		job.Salary = s.extractSalary(job.Description)
+	log.Printf("Scraped %d items from %s, jobs saved: %d/%d, duplicates skipped: %d, posts quarantined: %d, crew posts saved: %d",
+		stats.PostsFetched, source.Name(), stats.JobsSaved, stats.JobsExtracted, stats.DuplicatesSkipped,
+		stats.PostsQuarantined, stats.CrewPostsSaved)
+	return stats
+}
 
-		// Only add if we have minimum required data
-		if job.Title != "" && job.Company != "" {
-			jobs = append(jobs, job)
+// saveItem parses an item and saves its jobs, or quarantines it. It reports
+// whether the item was saved without errors.
+func (s *ScraperService) saveItem(source Source, item Item, stats *models.ScrapeRunSource) bool {
+	jobs := source.Parse(item)
+	if crewSource, ok := source.(CrewSource); ok && len(jobs) == 0 {
+		return s.saveCrewPost(crewSource, item, stats)
+	}
+	if len(jobs) > 0 && item.Post != nil {
+		// A post that cannot be quarantined is skipped rather than published
+		quarantined, err := s.screenPost(source.Name(), item.Post)
+		if err != nil {
+			log.Printf("Error quarantining post: %v", err)
+			stats.ErrorCount++
+			stats.Errors = append(stats.Errors, err.Error())
+			return false
 		}
-	})
+		if quarantined {
+			stats.PostsQuarantined++
+			return true
+		}
+	}
 
-	return jobs, nil
-}
-
-// saveJob converts scraped job to model and saves to database
-func (s *ScraperService) saveJob(scrapedJob ScrapedJob, source string) error {
-	job := &models.Job{
-		ID:           uuid.New().String(),
-		Title:        scrapedJob.Title,
-		Company:      scrapedJob.Company,
-		Location:     scrapedJob.Location,
-		Type:         scrapedJob.Type,
-		Vessel:       scrapedJob.Vessel,
-		Duration:     scrapedJob.Duration,
-		Salary:       scrapedJob.Salary,
-		Description:  scrapedJob.Description,
-		Requirements: scrapedJob.Requirements,
-		SourceURL:    scrapedJob.URL,
-		Source:       source,
-		PostedAt:     scrapedJob.PostedAt,
-		ScrapedAt:    time.Now(),
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
-	}
-
-	return s.jobService.CreateJob(job)
-}
-
-// Helper functions to extract marine-specific information
-func (s *ScraperService) extractJobType(title, description string) string {
-	text := strings.ToLower(title + " " + description)
-	
-	types := map[string]string{
-		"deck":      "deck",
-		"officer":   "deck",
-		"captain":   "deck",
-		"mate":      "deck",
-		"engineer":  "engine",
-		"engine":    "engine",
-		"motorman":  "engine",
-		"cook":      "catering",
-		"chef":      "catering",
-		"steward":   "catering",
-		"catering":  "catering",
-		"fitter":    "engine",
-		"welder":    "technical",
-		"ab":        "deck",
-		"oiler":     "engine",
-	}
-
-	for keyword, jobType := range types {
-		if strings.Contains(text, keyword) {
-			return jobType
+	saved := true
+	for _, job := range jobs {
+		stats.JobsExtracted++
+		created, err := s.jobService.UpsertJob(job)
+		switch {
+		case err != nil:
+			log.Printf("Error saving job: %v", err)
+			stats.ErrorCount++
+			stats.Errors = append(stats.Errors, err.Error())
+			saved = false
+		case created:
+			stats.JobsSaved++
+		default:
+			stats.DuplicatesSkipped++
 		}
 	}
-	return "general"
+	return saved
 }
 
-func (s *ScraperService) extractVesselType(title, description string) string {
-	text := strings.ToLower(title + " " + description)
-	
-	vessels := []string{
-		"tanker", "container", "bulk", "cargo", "cruise", "ferry",
-		"offshore", "supply", "tug", "barge", "yacht", "fishing",
+// saveCrewPost saves an item that yielded no jobs when it is a crew member
+// advertising their availability. It reports whether it did not fail.
+func (s *ScraperService) saveCrewPost(source CrewSource, item Item, stats *models.ScrapeRunSource) bool {
+	post := source.ParseCrew(item)
+	if post == nil {
+		return true
 	}
+	post.Source = source.Name()
 
-	for _, vessel := range vessels {
-		if strings.Contains(text, vessel) {
-			return vessel
-		}
+	created, err := s.crew.SavePost(post)
+	switch {
+	case err != nil:
+		log.Printf("Error saving crew availability post: %v", err)
+		stats.ErrorCount++
+		stats.Errors = append(stats.Errors, err.Error())
+		return false
+	case created:
+		stats.CrewPostsSaved++
 	}
-	return ""
+	return true
 }
 
-func (s *ScraperService) extractDuration(description string) string {
-	text := strings.ToLower(description)
-	
-	durations := []string{
-		"4 months", "6 months", "8 months", "permanent", "rotation",
-		"4/4", "6/6", "8/4", "2/2", "3/3",
+// screenPost classifies a post that yielded jobs and quarantines it when it
+// looks like a scam or spam. It reports whether the post is held for review,
+// which it no longer is once an admin approved it.
+func (s *ScraperService) screenPost(source string, post *ScrapedPost) (bool, error) {
+	verdict := s.spam.Classify(post)
+	if !verdict.Spam {
+		return false, nil
 	}
 
-	for _, duration := range durations {
-		if strings.Contains(text, duration) {
-			return duration
-		}
+	var postedAt *time.Time
+	if !post.Timestamp.IsZero() {
+		postedAt = &post.Timestamp
+	}
+	quarantined, created, err := s.quarantine.Quarantine(&models.QuarantinedPost{
+		Source:      source,
+		URL:         post.URL,
+		GroupName:   post.GroupName,
+		ChannelName: post.ChannelName,
+		PostSource:  post.Source,
+		Text:        post.Text,
+		PostedAt:    postedAt,
+		Score:       verdict.Score,
+		Reasons:     verdict.Reasons,
+	})
+	if err != nil {
+		return true, err
 	}
-	return ""
+	if created {
+		log.Printf("🚫 Quarantined post %s from %s, score %.2f: %s",
+			quarantined.ID, source, verdict.Score, strings.Join(verdict.Reasons, ", "))
+	}
+	return quarantined.Status != models.QuarantineStatusApproved, nil
 }
 
-func (s *ScraperService) extractSalary(description string) string {
-	text := description
-	
-	// Look for common salary patterns
-	salaryPatterns := []string{
-		"USD", "$", "EUR", "€", "GBP", "£", "/month", "/day",
-		"salary", "wage", "pay",
+// ApproveQuarantined releases a quarantined post: its jobs are saved and the
+// spam model learns it is legitimate. It returns the number of jobs saved.
+func (s *ScraperService) ApproveQuarantined(id, reviewer string) (*models.QuarantinedPost, int, error) {
+	post, err := s.quarantine.GetPost(id)
+	if err != nil {
+		return nil, 0, err
+	}
+	source, ok := s.registry.Get(post.Source)
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown scraper source %q for quarantined post %s", post.Source, id)
+	}
+
+	// Approving first keeps the post from being approved twice at once; it is
+	// reopened when its jobs cannot be saved, so it can be approved again
+	post, err = s.quarantine.Review(id, models.QuarantineStatusApproved, reviewer)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	for _, pattern := range salaryPatterns {
-		if strings.Contains(strings.ToLower(text), strings.ToLower(pattern)) {
-			// Extract the sentence containing salary information
-			sentences := strings.Split(text, ".")
-			for _, sentence := range sentences {
-				if strings.Contains(strings.ToLower(sentence), strings.ToLower(pattern)) {
-					return strings.TrimSpace(sentence)
-				}
+	scraped := &ScrapedPost{
+		Text:        post.Text,
+		URL:         post.URL,
+		Source:      post.PostSource,
+		GroupName:   post.GroupName,
+		ChannelName: post.ChannelName,
+	}
+	if post.PostedAt != nil {
+		scraped.Timestamp = *post.PostedAt
+	}
+
+	saved := 0
+	for _, job := range source.Parse(Item{Post: scraped}) {
+		created, err := s.jobService.UpsertJob(job)
+		if err != nil {
+			if reopenErr := s.quarantine.Reopen(id); reopenErr != nil {
+				log.Printf("Error reopening quarantined post %s: %v", id, reopenErr)
 			}
+			return nil, saved, fmt.Errorf("failed to save job of quarantined post %s: %w", id, err)
+		}
+		if created {
+			saved++
 		}
 	}
-	return ""
-} 
\ No newline at end of file
+
+	if _, err := s.spam.Train(post.Text, false); err != nil {
+		log.Printf("Error training spam model: %v", err)
+	}
+	log.Printf("Approved quarantined post %s, jobs saved: %d", id, saved)
+	return post, saved, nil
+}
+
+// RejectQuarantined discards a quarantined post, and the spam model learns
+// it is spam
+func (s *ScraperService) RejectQuarantined(id, reviewer string) (*models.QuarantinedPost, error) {
+	post, err := s.quarantine.Review(id, models.QuarantineStatusRejected, reviewer)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.spam.Train(post.Text, true); err != nil {
+		log.Printf("Error training spam model: %v", err)
+	}
+	log.Printf("Rejected quarantined post %s", id)
+	return post, nil
+}
+
+// setJobSalary sets the salary of a job from the first salary mentioned in text
+func setJobSalary(job *models.Job, text string) {
+	parsed := salary.Parse(text)
+	if parsed == nil {
+		return
+	}
+	job.Salary = parsed.Text
+	services.ApplySalary(job, parsed)
+}
+
+// setJobLocation sets the location of a job from the most specific place
+// mentioned in text, leaving it unchanged when no known place is mentioned
+func setJobLocation(job *models.Job, text string) {
+	location := geo.Resolve(text)
+	if location == nil {
+		return
+	}
+	job.Location = location.String()
+	services.ApplyLocation(job, location)
+}
+
+// setJobDuration sets the contract terms of a job from the ones mentioned in text
+func setJobDuration(job *models.Job, text string) {
+	parsed := contract.Parse(text)
+	if parsed == nil {
+		return
+	}
+	job.Duration = parsed.String()
+	services.ApplyContract(job, parsed)
+}
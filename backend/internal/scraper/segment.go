@@ -0,0 +1,167 @@
+package scraper
+
+import (
+	"regexp"
+	"strings"
+)
+
+// roleSegment is the part of a multi-position post about one role
+type roleSegment struct {
+	Title string
+	Text  string // What the post says about this role alone, e.g. its salary
+}
+
+// roleSegments is a post split by the positions it advertises
+type roleSegments struct {
+	Roles  []roleSegment
+	Shared string // Text outside every role, such as the vessel intro and contact details
+}
+
+var (
+	// listGap matches what may separate the positions of an inline list,
+	// as in "Chief Stew, 2nd Stew & Deckhand" or "marinero y azafata"
+	listGap = regexp.MustCompile(`(?i)^(?:[\s,;/&+|]|\b(?:and|or|plus|et|ou|e|o|y)\b)*$`)
+	// listGapNoise is what an inline list may carry between positions, such as
+	// counts and salaries: "Chief Stew (x1) €6k, Deckhand 3000 EUR"
+	listGapNoise = regexp.MustCompile(`(?i)\([^)\n]*\)|[€$£]|\b(?:x?\d+(?:[.,]\d+)*k?x?|eur|usd|gbp|pm|p/m|per\s+month|month|monthly)\b|[:-]`)
+	// lineLead matches what may come before a position heading on its line,
+	// as in "2) Position: Deckhand" or "• Wanted - Bosun"
+	lineLead = regexp.MustCompile(`(?i)^[^\p{L}]*(?:(?:vacancy|position|role|job|wanted|needed|hiring|seeking|looking\s+for|we\s+need|poste|recherch(?:e|ons)|posizione|cerc(?:hiamo|asi)|puesto|buscamos)\b[^\p{L}]*)?(?:(?:an?|one|experienced|qualified|sole)\s+)*$`)
+	// listIntro matches what introduces an inline list of positions, as in
+	// "M/Y 60m seeking:", "Now hiring an experienced" or "cerchiamo un"
+	listIntro = regexp.MustCompile(`(?i)\b(?:seeking|looking\s+for|hiring|recruiting|needs?|needed|wanted|requires?|required|vacanc(?:y|ies)|positions?|roles?|openings?|recherch(?:e|ons)|postes?|cerc(?:hiamo|asi|a)|posizioni|buscamos|busca|puestos)\b[^\p{L}]*(?:(?:an?|experienced|qualified|the\s+following|une?|des|un[oa]?|dei|delle|unos?|unas?)\s+)*$`)
+	// requirementPattern matches sentences stating what a candidate needs
+	requirementPattern = regexp.MustCompile(`(?i)\b(?:stcw|eng\s?1|b1/?b2|visas?|licen[cs]es?|certificat\w*|ticket|experience[d]?|requirements?|must|essential|non[- ]?smok\w*|tattoos?|yachtmaster|aec|pbh|oow|coc|hygiene|fluent|speaks?|languages?|expérience|requis|obligatoire|permis|brevet|esperienza|richiest[oa]|indispensabile|patente|experiencia|obligatori[oa]|imprescindible|titulación)\b`)
+	sentenceEnd        = regexp.MustCompile(`[.!?]\s+`)
+	listMarker         = regexp.MustCompile(`^[\s\-•*·]*(?:\d+[.)]\s+)?`)
+)
+
+// segmentRoles splits a post advertising several positions, either listed
+// inline ("M/Y 60m seeking: Chief Stew, 2nd Stew, Deckhand") or as blocks
+// starting with a position, into one segment per role. Posts with a single
+// position yield no roles.
+func segmentRoles(extractor *Extractor, text string) roleSegments {
+	mentions := extractor.FieldMatches(FieldTitle, text)
+	if len(mentions) < 2 {
+		return roleSegments{Shared: text}
+	}
+
+	// Only positions that head a line or are listed after an introduction are
+	// roles, not ones mentioned in passing, like "report to the captain and bosun"
+	leading := make([]bool, len(mentions))
+	listed := make([]bool, len(mentions))
+	for i, mention := range mentions {
+		leading[i] = lineLead.MatchString(text[lineStart(text, mention.Start):mention.Start])
+	}
+	for first := 0; first < len(mentions); {
+		last := first
+		for last+1 < len(mentions) && isListGap(text[mentions[last].End:mentions[last+1].Start]) {
+			last++
+		}
+		intro := text[lineStart(text, mentions[first].Start):mentions[first].Start]
+		if last > first && (leading[first] || listIntro.MatchString(intro)) {
+			for i := first; i <= last; i++ {
+				listed[i] = true
+			}
+		}
+		first = last + 1
+	}
+
+	type role struct {
+		title      string
+		start, end int
+		inline     bool
+	}
+	var roles []role
+	titles := make(map[string]bool)
+	for i, mention := range mentions {
+		if !leading[i] && !listed[i] || titles[mention.Value] {
+			continue
+		}
+		titles[mention.Value] = true
+
+		r := role{title: mention.Value, start: mention.Start, inline: !leading[i]}
+		if leading[i] {
+			r.start = lineStart(text, mention.Start)
+		}
+		roles = append(roles, r)
+	}
+	if len(roles) < 2 {
+		return roleSegments{Shared: text}
+	}
+
+	// A role runs until the next one. Roles listed inline end with their
+	// sentence, the text after the list being about all of them.
+	for i := range roles {
+		roles[i].end = len(text)
+		if i+1 < len(roles) {
+			roles[i].end = roles[i+1].start
+		}
+		if roles[i].inline {
+			if end := sentenceEndAfter(text, roles[i].start); end < roles[i].end {
+				roles[i].end = end
+			}
+		}
+	}
+
+	var segments roleSegments
+	shared := []string{text[:roles[0].start]}
+	for i, r := range roles {
+		segments.Roles = append(segments.Roles, roleSegment{
+			Title: r.title,
+			Text:  strings.TrimSpace(text[r.start:r.end]),
+		})
+		next := len(text)
+		if i+1 < len(roles) {
+			next = roles[i+1].start
+		}
+		shared = append(shared, text[r.end:next])
+	}
+	for i := range shared {
+		shared[i] = strings.TrimSpace(shared[i])
+	}
+	segments.Shared = strings.Join(nonEmpty(shared...), "\n")
+	return segments
+}
+
+// isListGap reports whether the text between two positions separates the
+// items of a list
+func isListGap(gap string) bool {
+	if strings.Contains(gap, "\n") || len(gap) > 40 {
+		return false
+	}
+	return listGap.MatchString(listGapNoise.ReplaceAllString(gap, " "))
+}
+
+// lineStart returns the offset of the line containing offset i
+func lineStart(text string, i int) int {
+	return strings.LastIndex(text[:i], "\n") + 1
+}
+
+// sentenceEndAfter returns the offset of the end of the sentence or line
+// containing offset i
+func sentenceEndAfter(text string, i int) int {
+	end := len(text)
+	if newline := strings.Index(text[i:], "\n"); newline >= 0 {
+		end = i + newline
+	}
+	if loc := sentenceEnd.FindStringIndex(text[i:end]); loc != nil {
+		end = i + loc[0] + 1
+	}
+	return end
+}
+
+// requirements returns the sentences of text stating what a candidate needs,
+// such as certificates, visas and experience
+func requirements(text string) string {
+	var found []string
+	for _, line := range strings.Split(text, "\n") {
+		for _, sentence := range sentenceEnd.Split(line, -1) {
+			sentence = strings.TrimSpace(listMarker.ReplaceAllString(sentence, ""))
+			if sentence != "" && requirementPattern.MatchString(sentence) {
+				found = append(found, sentence)
+			}
+		}
+	}
+	return strings.Join(found, "\n")
+}
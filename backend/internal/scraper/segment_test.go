@@ -0,0 +1,137 @@
+package scraper
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestScraper(t *testing.T) *YachtScraperService {
+	t.Helper()
+
+	engine, err := NewRuleEngine(RulesConfig{})
+	if err != nil {
+		t.Fatalf("NewRuleEngine: %v", err)
+	}
+	return &YachtScraperService{rules: engine}
+}
+
+func TestSegmentRoles(t *testing.T) {
+	extractor := newTestScraper(t).rules.Extractor(postRules)
+
+	tests := []struct {
+		name   string
+		text   string
+		titles []string
+	}{
+		{
+			"inline list",
+			"M/Y 60m seeking: Chief Stew, 2nd Stew, Deckhand. Based in Antibes.",
+			[]string{"Chief Stewardess", "Second Stewardess", "Deckhand"},
+		},
+		{
+			"position blocks",
+			"M/Y 55m hiring\n\nChief Stew\nSalary €5,500 per month\n\nDeckhand\nSalary €3,000 per month",
+			[]string{"Chief Stewardess", "Deckhand"},
+		},
+		{
+			"positions mentioned in passing",
+			"Deckhand wanted on M/Y 50m, reporting to the captain and bosun.",
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segments := segmentRoles(extractor, tt.text)
+			if len(segments.Roles) != len(tt.titles) {
+				t.Fatalf("got %d roles %+v, want %v", len(segments.Roles), segments.Roles, tt.titles)
+			}
+			for i, role := range segments.Roles {
+				if role.Title != tt.titles[i] {
+					t.Errorf("role %d = %q, want %q", i, role.Title, tt.titles[i])
+				}
+			}
+			if len(tt.titles) == 0 && segments.Shared != tt.text {
+				t.Errorf("shared = %q, want the whole post", segments.Shared)
+			}
+		})
+	}
+}
+
+func TestConvertPostToJobs(t *testing.T) {
+	type wantJob struct {
+		title        string
+		salary       string
+		requirements string
+	}
+	tests := []struct {
+		name     string
+		text     string
+		location string
+		jobs     []wantJob
+	}{
+		{
+			"inline list",
+			"M/Y 60m seeking: Chief Stew, 2nd Stew, Deckhand. Based in Antibes, salary €4,000 per month. Non smokers only.",
+			"Antibes, France",
+			[]wantJob{
+				{"Chief Stewardess", "€4,000", "Non smokers only."},
+				{"Second Stewardess", "€4,000", "Non smokers only."},
+				{"Deckhand", "€4,000", "Non smokers only."},
+			},
+		},
+		{
+			"position blocks",
+			"M/Y Serenity 55m based in Palma is hiring for the season.\n\n" +
+				"Chief Stew\nSalary €5,500 per month. Must have 3 years experience on 50m+.\n\n" +
+				"Deckhand\nSalary €3,000 per month. STCW and ENG1 required.\n\n" +
+				"Send CVs to crew@example.com",
+			"Palma, Spain",
+			[]wantJob{
+				{"Chief Stewardess", "€5,500", "Must have 3 years experience on 50m+."},
+				{"Deckhand", "€3,000", "STCW and ENG1 required."},
+			},
+		},
+	}
+
+	s := newTestScraper(t)
+	extractor := s.rules.LanguageExtractor(postRules, "en")
+	postedAt := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := ScrapedPost{Text: tt.text, URL: "https://example.com/posts/1", Timestamp: postedAt}
+			jobs := s.convertPostToJobs(extractor, post, "en")
+			if len(jobs) != len(tt.jobs) {
+				t.Fatalf("got %d jobs, want %d", len(jobs), len(tt.jobs))
+			}
+
+			fingerprints := make(map[string]bool)
+			for i, job := range jobs {
+				want := tt.jobs[i]
+				if job.Title != want.title {
+					t.Errorf("job %d title = %q, want %q", i, job.Title, want.title)
+				}
+				if job.Vessel != jobs[0].Vessel || job.Vessel == "" {
+					t.Errorf("job %d vessel = %q, want %q shared by every role", i, job.Vessel, jobs[0].Vessel)
+				}
+				if job.Location != tt.location {
+					t.Errorf("job %d location = %q, want %q", i, job.Location, tt.location)
+				}
+				if !job.PostedAt.Equal(postedAt) || job.Duration != jobs[0].Duration {
+					t.Errorf("job %d posted %v for %q, want %v for %q", i, job.PostedAt, job.Duration, postedAt, jobs[0].Duration)
+				}
+				if job.Salary != want.salary {
+					t.Errorf("job %d salary = %q, want %q", i, job.Salary, want.salary)
+				}
+				if job.Requirements != want.requirements {
+					t.Errorf("job %d requirements = %q, want %q", i, job.Requirements, want.requirements)
+				}
+				fingerprints[job.URLFingerprint] = true
+			}
+			if len(fingerprints) != len(jobs) {
+				t.Errorf("roles share URL fingerprints: %v", fingerprints)
+			}
+		})
+	}
+}
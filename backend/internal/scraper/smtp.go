@@ -0,0 +1,392 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidMessage is returned for received messages that cannot be parsed,
+// which are rejected for good rather than retried by the sender
+var ErrInvalidMessage = errors.New("invalid message")
+
+const (
+	// smtpCommandTimeout bounds the wait for each command of a session
+	smtpCommandTimeout = 5 * time.Minute
+	// smtpDataTimeout bounds the transfer of a message body
+	smtpDataTimeout = 10 * time.Minute
+	// maxSMTPConnections bounds the sessions served at once
+	maxSMTPConnections = 20
+	// maxSMTPRecipients bounds the recipients of a single message
+	maxSMTPRecipients = 100
+)
+
+// SMTPServer is a minimal SMTP listener that accepts vacancy emails for the
+// configured recipients and delivers them to the Maildir the EmailSource reads
+type SMTPServer struct {
+	config   EmailConfig
+	hostname string
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	wg       sync.WaitGroup
+	slots    chan struct{}
+}
+
+func NewSMTPServer(config EmailConfig) *SMTPServer {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "localhost"
+	}
+	return &SMTPServer{
+		config:   config,
+		hostname: hostname,
+		conns:    make(map[net.Conn]struct{}),
+		slots:    make(chan struct{}, maxSMTPConnections),
+	}
+}
+
+// Enabled reports whether SCRAPER_SMTP_ADDR configures a listener
+func (s *SMTPServer) Enabled() bool {
+	return s.config.SMTPAddr != ""
+}
+
+// Start creates the Maildir and starts accepting connections
+func (s *SMTPServer) Start() error {
+	for _, dir := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(s.config.Dir, dir), 0o755); err != nil {
+			return fmt.Errorf("failed to create Maildir: %w", err)
+		}
+	}
+
+	listener, err := net.Listen("tcp", s.config.SMTPAddr)
+	if err != nil {
+		return fmt.Errorf("failed to start SMTP listener: %w", err)
+	}
+	s.mu.Lock()
+	s.listener = listener
+	s.mu.Unlock()
+
+	log.Printf("📬 SMTP listener accepting mail for %s on %s", strings.Join(s.config.Recipients, ", "), listener.Addr())
+	s.wg.Add(1)
+	go s.serve(listener)
+	return nil
+}
+
+// Stop closes the listener and any open sessions
+func (s *SMTPServer) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *SMTPServer) serve(listener net.Listener) {
+	defer s.wg.Done()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			log.Printf("Error accepting SMTP connection: %v", err)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		select {
+		case s.slots <- struct{}{}:
+		default:
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			fmt.Fprintf(conn, "421 %s Too many connections, try again later\r\n", s.hostname)
+			conn.Close()
+			continue
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer func() {
+				s.mu.Lock()
+				delete(s.conns, conn)
+				s.mu.Unlock()
+				conn.Close()
+				<-s.slots
+			}()
+			s.session(conn)
+		}()
+	}
+}
+
+// smtpSession is the envelope of the message being received
+type smtpSession struct {
+	helo       bool
+	from       string
+	hasFrom    bool
+	recipients []string
+}
+
+func (e *smtpSession) reset() {
+	e.from = ""
+	e.hasFrom = false
+	e.recipients = nil
+}
+
+// session runs the SMTP dialogue of one connection
+func (s *SMTPServer) session(conn net.Conn) {
+	text := textproto.NewConn(conn)
+	reply := func(format string, args ...interface{}) bool {
+		conn.SetWriteDeadline(time.Now().Add(smtpCommandTimeout))
+		return text.PrintfLine(format, args...) == nil
+	}
+
+	if !reply("220 %s ESMTP CarverJobs", s.hostname) {
+		return
+	}
+
+	var session smtpSession
+	for {
+		conn.SetReadDeadline(time.Now().Add(smtpCommandTimeout))
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+		arg = strings.TrimSpace(arg)
+		switch strings.ToUpper(verb) {
+		case "HELO":
+			session.helo = true
+			session.reset()
+			reply("250 %s", s.hostname)
+		case "EHLO":
+			session.helo = true
+			session.reset()
+			reply("250-%s", s.hostname)
+			reply("250-SIZE %d", s.config.MaxMessageSize)
+			reply("250-8BITMIME")
+			reply("250 PIPELINING")
+		case "MAIL":
+			if !session.helo {
+				reply("503 5.5.1 Send HELO or EHLO first")
+				continue
+			}
+			if session.hasFrom {
+				reply("503 5.5.1 Sender already given")
+				continue
+			}
+			from, params, ok := smtpPath(arg, "FROM:")
+			if !ok {
+				reply("501 5.5.4 Syntax: MAIL FROM:<address>")
+				continue
+			}
+			if size, ok := smtpSize(params); ok && size > s.config.MaxMessageSize {
+				reply("552 5.3.4 Message too big")
+				continue
+			}
+			if !s.senderAllowed(from) {
+				log.Printf("📪 Rejected email from %s", from)
+				reply("550 5.7.1 Sender not allowed")
+				continue
+			}
+			session.from = from
+			session.hasFrom = true
+			reply("250 2.1.0 OK")
+		case "RCPT":
+			if !session.hasFrom {
+				reply("503 5.5.1 Send MAIL first")
+				continue
+			}
+			to, _, ok := smtpPath(arg, "TO:")
+			if !ok || to == "" {
+				reply("501 5.5.4 Syntax: RCPT TO:<address>")
+				continue
+			}
+			if !s.recipientAllowed(to) {
+				reply("550 5.1.1 No such recipient here")
+				continue
+			}
+			if len(session.recipients) >= maxSMTPRecipients {
+				reply("452 4.5.3 Too many recipients")
+				continue
+			}
+			session.recipients = append(session.recipients, to)
+			reply("250 2.1.5 OK")
+		case "DATA":
+			if len(session.recipients) == 0 {
+				reply("503 5.5.1 Send RCPT first")
+				continue
+			}
+			if !reply("354 End data with <CR><LF>.<CR><LF>") {
+				return
+			}
+
+			conn.SetReadDeadline(time.Now().Add(smtpDataTimeout))
+			data := text.DotReader()
+			message, err := io.ReadAll(io.LimitReader(data, s.config.MaxMessageSize+1))
+			if err != nil {
+				return
+			}
+			if int64(len(message)) > s.config.MaxMessageSize {
+				// Drain the rest of the message so the session can go on
+				if _, err := io.Copy(io.Discard, data); err != nil {
+					return
+				}
+				reply("552 5.3.4 Message too big")
+				session.reset()
+				continue
+			}
+
+			if err := s.deliver(conn.RemoteAddr(), session, message); errors.Is(err, ErrInvalidMessage) {
+				log.Printf("Rejected email from %s: %v", session.from, err)
+				reply("554 5.6.0 Message could not be parsed")
+			} else if err != nil {
+				log.Printf("Error delivering email from %s: %v", session.from, err)
+				reply("451 4.3.0 Delivery failed, try again later")
+			} else {
+				log.Printf("📨 Received email from %s", session.from)
+				reply("250 2.0.0 OK queued")
+			}
+			session.reset()
+		case "RSET":
+			session.reset()
+			reply("250 2.0.0 OK")
+		case "NOOP":
+			reply("250 2.0.0 OK")
+		case "VRFY":
+			reply("252 2.5.2 Cannot verify user")
+		case "QUIT":
+			reply("221 2.0.0 Bye")
+			return
+		default:
+			reply("502 5.5.2 Command not implemented")
+		}
+	}
+}
+
+// smtpPath parses the <address> argument of MAIL FROM or RCPT TO and the
+// parameters following it
+func smtpPath(arg, prefix string) (string, []string, bool) {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", nil, false
+	}
+	arg = strings.TrimSpace(arg[len(prefix):])
+	if !strings.HasPrefix(arg, "<") {
+		return "", nil, false
+	}
+	end := strings.Index(arg, ">")
+	if end < 0 {
+		return "", nil, false
+	}
+	address := strings.ToLower(strings.TrimSpace(arg[1:end]))
+	// Source routes such as <@relay:user@host> are obsolete but legal
+	if i := strings.LastIndex(address, ":"); strings.HasPrefix(address, "@") && i >= 0 {
+		address = address[i+1:]
+	}
+	return address, strings.Fields(arg[end+1:]), true
+}
+
+// smtpSize returns the SIZE parameter of MAIL FROM
+func smtpSize(params []string) (int64, bool) {
+	for _, param := range params {
+		if key, value, ok := strings.Cut(param, "="); ok && strings.EqualFold(key, "SIZE") {
+			var size int64
+			if _, err := fmt.Sscan(value, &size); err == nil {
+				return size, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// senderAllowed matches a sender against SCRAPER_SMTP_ALLOWED_SENDERS
+func (s *SMTPServer) senderAllowed(from string) bool {
+	if len(s.config.AllowedSenders) == 0 {
+		return true
+	}
+	for _, allowed := range s.config.AllowedSenders {
+		if strings.HasPrefix(allowed, "@") && strings.HasSuffix(from, allowed) {
+			return true
+		}
+		if from == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// recipientAllowed matches a recipient against SCRAPER_SMTP_RECIPIENTS
+func (s *SMTPServer) recipientAllowed(to string) bool {
+	for _, recipient := range s.config.Recipients {
+		if to == recipient {
+			return true
+		}
+	}
+	return false
+}
+
+// deliver writes a message to the Maildir: into tmp first, then moved to new
+// so the EmailSource never reads a partial message. Messages that cannot be
+// parsed fail with ErrInvalidMessage.
+func (s *SMTPServer) deliver(remote net.Addr, session smtpSession, message []byte) error {
+	if _, err := mail.ReadMessage(bytes.NewReader(message)); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+
+	random := make([]byte, 8)
+	if _, err := rand.Read(random); err != nil {
+		return err
+	}
+	now := time.Now()
+	name := fmt.Sprintf("%d.%s.%s", now.Unix(), hex.EncodeToString(random), strings.ReplaceAll(s.hostname, "/", "_"))
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "Return-Path: <%s>\r\n", session.from)
+	fmt.Fprintf(&b, "Received: from %s by %s with ESMTP for <%s>; %s\r\n",
+		remote, s.hostname, strings.Join(session.recipients, ">, <"), now.Format(time.RFC1123Z))
+	b.Write(message)
+
+	tmp := filepath.Join(s.config.Dir, "tmp", name)
+	if err := os.WriteFile(tmp, b.Bytes(), 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(s.config.Dir, "new", name)); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/models"
+	"go.uber.org/fx"
+)
+
+// Source is a job board, group or channel that jobs can be scraped from
+type Source interface {
+	// Name uniquely identifies the source and is used to enable it in config
+	Name() string
+	// Fetch retrieves the raw items currently published by the source
+	Fetch(ctx context.Context) ([]Item, error)
+	// Parse converts a fetched item into zero or more jobs
+	Parse(item Item) []*models.Job
+}
+
+// ResumableSource is a Source whose fetches run remotely, such as Apify actor
+// runs, so that a run started before a restart can still be collected
+type ResumableSource interface {
+	Source
+	// Resume waits for the remote run to finish and returns its items
+	Resume(ctx context.Context, runID string) ([]Item, error)
+}
+
+// RunClaims tracks the remote runs being collected, so that a run is
+// collected once even when a fetch, a webhook and a resume all reach it
+type RunClaims struct {
+	mu      sync.Mutex
+	claimed map[string]bool
+}
+
+func NewRunClaims() *RunClaims {
+	return &RunClaims{claimed: make(map[string]bool)}
+}
+
+// Claim marks a run as being collected. It reports false when the run is
+// already claimed.
+func (c *RunClaims) Claim(runID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.claimed[runID] {
+		return false
+	}
+	c.claimed[runID] = true
+	return true
+}
+
+// Claimed reports whether a run is being collected
+func (c *RunClaims) Claimed(runID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.claimed[runID]
+}
+
+// Release marks a run as no longer being collected
+func (c *RunClaims) Release(runID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.claimed, runID)
+}
+
+// CrewSource is a Source whose posts may be crew advertising their
+// availability, such as Facebook crew groups
+type CrewSource interface {
+	Source
+	// ParseCrew extracts a crew availability post from an item that yielded
+	// no jobs, or returns nil when the item is not one
+	ParseCrew(item Item) *models.CrewAvailabilityPost
+}
+
+// Item is a single raw record returned by a Source. Social and feed sources
+// fill Post, structured job boards fill Listing.
+type Item struct {
+	Post    *ScrapedPost
+	Listing *ScrapedJob
+}
+
+// Commit records the progress a fetch made, such as advanced cursors or posts
+// marked seen. It runs once the fetched items have been saved; failed holds
+// the items that could not be, whose progress must not be recorded so they
+// are fetched again.
+type Commit func(failed []Item)
+
+// fetchCommits collects the commits of a fetch
+type fetchCommits struct {
+	mu      sync.Mutex
+	commits []Commit
+}
+
+type fetchCommitsKey struct{}
+
+// withFetchCommits returns a context whose fetches defer their commits to commits
+func withFetchCommits(ctx context.Context, commits *fetchCommits) context.Context {
+	return context.WithValue(ctx, fetchCommitsKey{}, commits)
+}
+
+// deferCommit registers the progress of a fetch to be recorded once its items
+// have been saved. Outside a scrape it is recorded right away.
+func deferCommit(ctx context.Context, commit Commit) {
+	commits, ok := ctx.Value(fetchCommitsKey{}).(*fetchCommits)
+	if !ok {
+		commit(nil)
+		return
+	}
+
+	commits.mu.Lock()
+	defer commits.mu.Unlock()
+	commits.commits = append(commits.commits, commit)
+}
+
+// commit runs every deferred commit
+func (c *fetchCommits) commit(failed []Item) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, commit := range c.commits {
+		commit(failed)
+	}
+	c.commits = nil
+}
+
+// defaultSources are enabled when SCRAPER_SOURCES is not set
+var defaultSources = []string{"facebook", "telegram"}
+
+type RegistryConfig struct {
+	// Enabled lists the source names to run; nil means every registered source
+	Enabled []string
+}
+
+// NewRegistryConfig reads the enabled sources from SCRAPER_SOURCES, a comma
+// separated list of source names. Use "*" to enable every registered source.
+func NewRegistryConfig() RegistryConfig {
+	value := strings.TrimSpace(os.Getenv("SCRAPER_SOURCES"))
+	if value == "" {
+		return RegistryConfig{Enabled: defaultSources}
+	}
+	if value == "*" {
+		return RegistryConfig{}
+	}
+
+	var enabled []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			enabled = append(enabled, name)
+		}
+	}
+	return RegistryConfig{Enabled: enabled}
+}
+
+// Registry keeps track of every registered Source and which ones are enabled
+type Registry struct {
+	mu      sync.RWMutex
+	sources []Source
+	byName  map[string]Source
+	enabled map[string]bool // nil means all sources are enabled
+}
+
+// NewRegistry creates a registry from the sources provided through the fx
+// "sources" value group. Value groups are unordered, so sources are sorted by
+// name to keep runs deterministic.
+func NewRegistry(config RegistryConfig, sources []Source) (*Registry, error) {
+	r := &Registry{
+		byName: make(map[string]Source),
+	}
+
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i].Name() < sources[j].Name()
+	})
+
+	for _, source := range sources {
+		if err := r.Register(source); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.Enabled != nil {
+		r.enabled = make(map[string]bool)
+		for _, name := range config.Enabled {
+			if _, ok := r.byName[name]; !ok {
+				log.Printf("Warning: scraper source %q is enabled but not registered", name)
+			}
+			r.enabled[name] = true
+		}
+	}
+
+	return r, nil
+}
+
+// Register adds a source to the registry
+func (r *Registry) Register(source Source) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name := source.Name()
+	if _, exists := r.byName[name]; exists {
+		return fmt.Errorf("scraper source %q is already registered", name)
+	}
+
+	r.sources = append(r.sources, source)
+	r.byName[name] = source
+	return nil
+}
+
+// Get returns the source registered under name
+func (r *Registry) Get(name string) (Source, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	source, ok := r.byName[name]
+	return source, ok
+}
+
+// Sources returns every registered source in registration order
+func (r *Registry) Sources() []Source {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]Source(nil), r.sources...)
+}
+
+// Enabled returns the enabled sources in registration order
+func (r *Registry) Enabled() []Source {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var enabled []Source
+	for _, source := range r.sources {
+		if r.enabled == nil || r.enabled[source.Name()] {
+			enabled = append(enabled, source)
+		}
+	}
+	return enabled
+}
+
+// IsEnabled reports whether the named source is enabled
+func (r *Registry) IsEnabled(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.byName[name]; !ok {
+		return false
+	}
+	return r.enabled == nil || r.enabled[name]
+}
+
+// AsSource annotates a Source constructor so its result joins the fx
+// "sources" value group consumed by NewRegistry
+func AsSource(constructor interface{}) interface{} {
+	return fx.Annotate(
+		constructor,
+		fx.As(new(Source)),
+		fx.ResultTags(`group:"sources"`),
+	)
+}
@@ -0,0 +1,420 @@
+package scraper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/services"
+)
+
+const (
+	// minModelPosts is the number of spam and of legitimate posts the model
+	// must be trained on before its score is used
+	minModelPosts = 10
+	// blastGroups is the number of groups the same post must be seen in to
+	// count as a blast
+	blastGroups = 3
+	// blastWindow is how long a post is remembered to detect blasts
+	blastWindow = 7 * 24 * time.Hour
+)
+
+// SpamConfig configures the classifier that holds scam and spam posts for review
+type SpamConfig struct {
+	// ModelPath is the file the trained model is stored in
+	ModelPath string
+	// Threshold is the score from which a post is quarantined; 0 disables
+	// quarantining
+	Threshold float64
+	// Domains are hosts, besides the built-in ones, links to which are suspicious
+	Domains []string
+}
+
+// NewSpamConfig reads the spam classifier settings from SCRAPER_SPAM_MODEL,
+// SCRAPER_SPAM_THRESHOLD and SCRAPER_SPAM_DOMAINS, a comma separated list of hosts
+func NewSpamConfig() SpamConfig {
+	config := SpamConfig{
+		ModelPath: "data/spam_model.json",
+		Threshold: 0.7,
+	}
+
+	if value := os.Getenv("SCRAPER_SPAM_MODEL"); value != "" {
+		config.ModelPath = value
+	}
+	if value := os.Getenv("SCRAPER_SPAM_THRESHOLD"); value != "" {
+		if threshold, err := strconv.ParseFloat(value, 64); err == nil && threshold >= 0 && threshold <= 1 {
+			config.Threshold = threshold
+		} else {
+			log.Printf("Invalid SCRAPER_SPAM_THRESHOLD %q", value)
+		}
+	}
+	for _, domain := range strings.Split(os.Getenv("SCRAPER_SPAM_DOMAINS"), ",") {
+		if domain = strings.ToLower(strings.TrimSpace(domain)); domain != "" {
+			config.Domains = append(config.Domains, domain)
+		}
+	}
+	return config
+}
+
+// SpamVerdict is the outcome of classifying a post
+type SpamVerdict struct {
+	Score   float64  `json:"score"`   // From 0, legitimate, to 1, spam
+	Reasons []string `json:"reasons"` // Signals that raised the score
+	Spam    bool     `json:"spam"`    // Whether the score reaches the threshold
+}
+
+// SpamModelStats describes the trained model
+type SpamModelStats struct {
+	SpamPosts  int  `json:"spam_posts"`
+	LegitPosts int  `json:"legit_posts"`
+	Tokens     int  `json:"tokens"`
+	Active     bool `json:"active"` // Trained on enough posts to be used
+}
+
+var (
+	// feeRequest matches a post asking candidates for money
+	feeRequest = regexp.MustCompile(`(?i)\b(?:registration|processing|application|placement|recruitment|agency|admin(?:istration)?|visa|medical|training|booking|security|insurance|uniform|documentation)\s+(?:fees?|charges?|deposit)\b|\bpay\s+(?:a\s+|the\s+)?(?:small\s+|one[- ]time\s+)?(?:fee|deposit)\b|\bfees?\s+(?:of\s+)?[$€£]\s?\d|\brefundable\s+deposit\b|\b(?:western\s+union|moneygram|gift\s+cards?)\b|\bpay(?:ment)?\s+(?:upfront|in\s+advance)\b`)
+	// noFee matches a post stating that candidates pay nothing, as in "no
+	// agency fees", which must not count as a fee request
+	noFee = regexp.MustCompile(`(?i)\b(?:no|never|without|zero|not\s+charge\s+any)\s+(?:\w+\s+){0,2}(?:fees?|charges?|deposit)\b|\bfree\s+of\s+charge\b|\bnever\s+(?:ask|pay)\b[^.\n]*`)
+	// courseMarker matches the wording of course and training ads
+	courseMarker = regexp.MustCompile(`(?i)\b(?:courses?|enrol(?:l)?(?:ment|ing)?|book\s+(?:now|today|your\s+(?:place|seat|course))|discount(?:ed)?|\d+\s*%\s*off|special\s+offer|limited\s+(?:places|seats|spaces)|early\s+bird|training\s+cent(?:re|er))\b`)
+	whatsApp     = regexp.MustCompile(`(?i)\bwhats\s?app\b|\bwa\.me/`)
+	emailAddress = regexp.MustCompile(`[\w.+-]+@[\w-]+(?:\.[\w-]+)+`)
+	link         = regexp.MustCompile(`(?i)(?:https?://|www\.)[^\s<>"')]+|\b(?:[a-z0-9-]+\.)+[a-z]{2,}/[^\s<>"')]*`)
+)
+
+// suspiciousHosts are link shorteners and free site builders that hide
+// where a link leads
+var suspiciousHosts = []string{
+	"bit.ly", "tinyurl.com", "goo.gl", "cutt.ly", "rb.gy", "shorturl.at", "is.gd",
+	"ow.ly", "t.ly", "tiny.cc", "rebrand.ly", "linktr.ee", "wixsite.com",
+	"weebly.com", "blogspot.com", "000webhostapp.com", "sites.google.com", "forms.gle",
+}
+
+// suspiciousTLDs are top-level domains favoured by scam sites
+var suspiciousTLDs = []string{
+	"xyz", "top", "click", "online", "site", "buzz", "icu", "work", "loan", "live", "rest", "cfd",
+}
+
+// whatsAppHosts are the hosts of WhatsApp contact links
+var whatsAppHosts = []string{"wa.me", "whatsapp.com"}
+
+// SpamClassifier scores scraped posts on heuristics, such as fee requests,
+// and on a Naive Bayes model trained from the posts admins review
+type SpamClassifier struct {
+	config    SpamConfig
+	sightings *services.PostSightingService
+
+	mu         sync.Mutex
+	model      *bayesModel
+	prunedAt   time.Time
+	suspicious []string
+}
+
+// bayesModel counts, for spam and legitimate posts, the posts it was trained
+// on and the number of them each token appears in
+type bayesModel struct {
+	SpamPosts   int            `json:"spam_posts"`
+	LegitPosts  int            `json:"legit_posts"`
+	SpamTokens  map[string]int `json:"spam_tokens"`
+	LegitTokens map[string]int `json:"legit_tokens"`
+}
+
+// NewSpamClassifier creates a classifier, loading its model from disk when
+// it was trained before
+func NewSpamClassifier(config SpamConfig, sightings *services.PostSightingService) *SpamClassifier {
+	c := &SpamClassifier{
+		config:     config,
+		sightings:  sightings,
+		model:      &bayesModel{SpamTokens: map[string]int{}, LegitTokens: map[string]int{}},
+		suspicious: append(append([]string{}, suspiciousHosts...), config.Domains...),
+	}
+
+	data, err := os.ReadFile(config.ModelPath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		log.Printf("Error reading spam model %s: %v", config.ModelPath, err)
+	default:
+		model := &bayesModel{}
+		if err := json.Unmarshal(data, model); err != nil {
+			log.Printf("Error decoding spam model %s: %v", config.ModelPath, err)
+			break
+		}
+		if model.SpamTokens == nil {
+			model.SpamTokens = map[string]int{}
+		}
+		if model.LegitTokens == nil {
+			model.LegitTokens = map[string]int{}
+		}
+		c.model = model
+		log.Printf("Loaded spam model trained on %d spam and %d legitimate posts", model.SpamPosts, model.LegitPosts)
+	}
+	return c
+}
+
+// Threshold returns the score from which posts are quarantined
+func (c *SpamClassifier) Threshold() float64 {
+	return c.config.Threshold
+}
+
+// Classify scores a post. The heuristic signals are combined as independent
+// probabilities, and the score is the highest of that and the model's score.
+func (c *SpamClassifier) Classify(post *ScrapedPost) SpamVerdict {
+	verdict := SpamVerdict{Reasons: []string{}}
+	legit := 1.0
+	signal := func(weight float64, reason string) {
+		legit *= 1 - weight
+		verdict.Reasons = append(verdict.Reasons, reason)
+	}
+
+	text := post.Text
+	if feeRequest.MatchString(noFee.ReplaceAllString(text, " ")) {
+		signal(0.7, "asks candidates for a fee")
+	}
+	if markers := distinctMatches(courseMarker, text); markers >= 2 {
+		signal(math.Min(0.3*float64(markers), 0.9), "reads like a course or training ad")
+	}
+
+	links := linkHosts(text)
+	if whatsApp.MatchString(text) && !emailAddress.MatchString(text) && allHosts(links, whatsAppHosts) {
+		signal(0.3, "WhatsApp is the only contact")
+	}
+	for _, host := range links {
+		if c.isSuspicious(host) {
+			signal(0.4, "links to suspicious domain "+host)
+			break
+		}
+	}
+	if groups := c.recordBlast(post); groups >= blastGroups {
+		signal(0.3, fmt.Sprintf("posted in %d groups", groups))
+	}
+	verdict.Score = 1 - legit
+
+	c.mu.Lock()
+	probability, active := c.model.spamProbability(postTokens(text))
+	c.mu.Unlock()
+	if active && probability > 0.5 {
+		verdict.Reasons = append(verdict.Reasons, fmt.Sprintf("model scores it %.2f", probability))
+		verdict.Score = math.Max(verdict.Score, probability)
+	}
+
+	verdict.Score = math.Round(verdict.Score*100) / 100
+	verdict.Spam = c.config.Threshold > 0 && verdict.Score >= c.config.Threshold
+	return verdict
+}
+
+// Train adds a post reviewed by an admin to the model and saves it to disk
+func (c *SpamClassifier) Train(text string, spam bool) (SpamModelStats, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counts := c.model.LegitTokens
+	if spam {
+		counts = c.model.SpamTokens
+		c.model.SpamPosts++
+	} else {
+		c.model.LegitPosts++
+	}
+	for _, token := range postTokens(text) {
+		counts[token]++
+	}
+
+	err := c.save()
+	return c.stats(), err
+}
+
+// Stats describes the trained model
+func (c *SpamClassifier) Stats() SpamModelStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats()
+}
+
+func (c *SpamClassifier) stats() SpamModelStats {
+	tokens := len(c.model.SpamTokens)
+	for token := range c.model.LegitTokens {
+		if _, ok := c.model.SpamTokens[token]; !ok {
+			tokens++
+		}
+	}
+	return SpamModelStats{
+		SpamPosts:  c.model.SpamPosts,
+		LegitPosts: c.model.LegitPosts,
+		Tokens:     tokens,
+		Active:     c.model.active(),
+	}
+}
+
+// save writes the model to a temporary file then renames it, so a crash
+// never leaves a truncated model behind
+func (c *SpamClassifier) save() error {
+	data, err := json.Marshal(c.model)
+	if err != nil {
+		return fmt.Errorf("failed to encode spam model: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.config.ModelPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create spam model directory: %w", err)
+	}
+	tmp := c.config.ModelPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write spam model: %w", err)
+	}
+	if err := os.Rename(tmp, c.config.ModelPath); err != nil {
+		return fmt.Errorf("failed to save spam model: %w", err)
+	}
+	return nil
+}
+
+// recordBlast remembers the group a post was seen in and returns the number
+// of groups the same text was posted in recently. Sightings are stored, so
+// blasts are still recognised after a restart.
+func (c *SpamClassifier) recordBlast(post *ScrapedPost) int {
+	fingerprint := services.ContentFingerprint("", "", post.Text)
+	if fingerprint == "" {
+		return 0
+	}
+	group := post.Source
+	if names := nonEmpty(post.GroupName, post.ChannelName); len(names) > 0 {
+		group += ":" + names[0]
+	}
+
+	now := time.Now()
+	c.mu.Lock()
+	prune := now.Sub(c.prunedAt) > time.Hour
+	if prune {
+		c.prunedAt = now
+	}
+	c.mu.Unlock()
+	if prune {
+		if err := c.sightings.PruneSightings(now.Add(-blastWindow)); err != nil {
+			log.Printf("Error pruning post sightings: %v", err)
+		}
+	}
+
+	groups, err := c.sightings.RecordSighting(fingerprint, group, now.Add(-blastWindow))
+	if err != nil {
+		log.Printf("Error recording post sighting: %v", err)
+		return 0
+	}
+	return groups
+}
+
+// isSuspicious reports whether a host is, or is a subdomain of, a suspicious
+// host, or has a suspicious top-level domain
+func (c *SpamClassifier) isSuspicious(host string) bool {
+	if matchesHost(host, c.suspicious) {
+		return true
+	}
+	tld := host[strings.LastIndex(host, ".")+1:]
+	for _, suspicious := range suspiciousTLDs {
+		if tld == suspicious {
+			return true
+		}
+	}
+	return false
+}
+
+// active reports whether the model was trained on enough posts to be used
+func (m *bayesModel) active() bool {
+	return m.SpamPosts >= minModelPosts && m.LegitPosts >= minModelPosts
+}
+
+// spamProbability returns the probability that a post with the given tokens
+// is spam, and whether the model is trained enough to tell. Tokens the model
+// never saw are ignored.
+func (m *bayesModel) spamProbability(tokens []string) (float64, bool) {
+	if !m.active() {
+		return 0, false
+	}
+
+	total := float64(m.SpamPosts + m.LegitPosts)
+	logSpam := math.Log(float64(m.SpamPosts) / total)
+	logLegit := math.Log(float64(m.LegitPosts) / total)
+	for _, token := range tokens {
+		spamCount, inSpam := m.SpamTokens[token]
+		legitCount, inLegit := m.LegitTokens[token]
+		if !inSpam && !inLegit {
+			continue
+		}
+		// Laplace smoothing of the share of posts containing the token
+		logSpam += math.Log(float64(spamCount+1) / float64(m.SpamPosts+2))
+		logLegit += math.Log(float64(legitCount+1) / float64(m.LegitPosts+2))
+	}
+	return 1 / (1 + math.Exp(logLegit-logSpam)), true
+}
+
+// postTokens returns the distinct lowercase words and numbers of a post
+func postTokens(text string) []string {
+	seen := make(map[string]bool)
+	var tokens []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		if n := len([]rune(word)); n < 2 || n > 30 || seen[word] {
+			continue
+		}
+		seen[word] = true
+		tokens = append(tokens, word)
+	}
+	sort.Strings(tokens)
+	return tokens
+}
+
+// distinctMatches counts the distinct lowercase matches of a pattern in text
+func distinctMatches(pattern *regexp.Regexp, text string) int {
+	seen := make(map[string]bool)
+	for _, match := range pattern.FindAllString(text, -1) {
+		seen[strings.ToLower(match)] = true
+	}
+	return len(seen)
+}
+
+// linkHosts returns the lowercase hosts of the links in text
+func linkHosts(text string) []string {
+	var hosts []string
+	for _, raw := range link.FindAllString(text, -1) {
+		if !strings.Contains(raw, "://") {
+			raw = "http://" + raw
+		}
+		parsed, err := url.Parse(raw)
+		if err != nil || parsed.Hostname() == "" {
+			continue
+		}
+		hosts = append(hosts, strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www."))
+	}
+	return hosts
+}
+
+// allHosts reports whether every host is one of, or a subdomain of one of, known
+func allHosts(hosts, known []string) bool {
+	for _, host := range hosts {
+		if !matchesHost(host, known) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesHost reports whether host is one of, or a subdomain of one of, known
+func matchesHost(host string, known []string) bool {
+	for _, k := range known {
+		if host == k || strings.HasSuffix(host, "."+k) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,24 +1,49 @@
 package scraper
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-	"net/http"
-	"os"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/language"
 	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/models"
 	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/services"
 	"github.com/google/uuid"
 )
 
+// Apify actors used to scrape the yacht crew groups and channels
+const (
+	facebookActorID = "apify~facebook-groups-scraper"
+	telegramActorID = "cYGAiWbhiASIZSZb5"
+)
+
+// Posts requested per Facebook group once every group has a cursor. The run
+// then only returns posts newer than it, so a larger cap catches up busy groups.
+const incrementalMaxPosts = 100
+
+// How long to wait for each actor run to finish
+const (
+	facebookRunTimeout = 10 * time.Minute
+	telegramRunTimeout = 15 * time.Minute
+)
+
 type YachtScraperService struct {
-	jobService *services.JobService
-	client     *http.Client
-	apifyKey   string
+	apify     *ApifyClient
+	actorRuns *services.ActorRunService
+	cursors   *services.CursorService
+	sources   *services.ScrapeSourceService
+	rules     *RuleEngine
+	claims    *RunClaims
+
+	mu      sync.Mutex
+	waiters map[string]chan struct{} // Runs awaiting a completion webhook
 }
 
 type ApifyRunRequest struct {
@@ -26,24 +51,13 @@ type ApifyRunRequest struct {
 	Channels     []string            `json:"channels,omitempty"`
 	MaxPosts     int                 `json:"maxPosts"`
 	MaxPostDate  string              `json:"maxPostDate,omitempty"`
+	NewerThan    string              `json:"onlyPostsNewerThan,omitempty"`
 	MaxComments  int                 `json:"maxComments,omitempty"`
-	PostsFrom    int                 `json:"postsFrom,omitempty"`
-	PostsTo      int                 `json:"postsTo,omitempty"`
+	PostsFrom    int64               `json:"postsFrom,omitempty"`
+	PostsTo      int64               `json:"postsTo,omitempty"`
 	Timeout      int                 `json:"timeout,omitempty"`
 }
 
-type ApifyRunResponse struct {
-	Data struct {
-		ID string `json:"id"`
-	} `json:"data"`
-}
-
-type ApifyStatusResponse struct {
-	Data struct {
-		Status string `json:"status"`
-	} `json:"data"`
-}
-
 type ScrapedPost struct {
 	Text        string    `json:"text"`
 	URL         string    `json:"url"`
@@ -53,114 +67,160 @@ type ScrapedPost struct {
 	ChannelName string    `json:"channelName,omitempty"`
 }
 
-// Top yacht job sources - compressed from original 100+ to most effective 20
-var (
-	facebookGroups = []string{
-		"https://www.facebook.com/groups/239411889867327/",  // Yacht Crew Jobs
-		"https://www.facebook.com/groups/338532096967628/",  // Yacht Crew Jobs International
-		"https://www.facebook.com/groups/2258415597510901/", // Yacht Crew Jobs Worldwide
-		"https://www.facebook.com/groups/1281653486137390/", // Yacht Crew Jobs Mediterranean
-		"https://www.facebook.com/groups/crewhq/",           // Crew HQ
-		"https://www.facebook.com/groups/252411372371043/",  // Yacht Crew Jobs Europe
-		"https://www.facebook.com/groups/1506610199573250/", // Yacht Crew Jobs Caribbean
-		"https://www.facebook.com/groups/147250563939706/",  // Seazone Yacht Crew & Jobs
-		"https://www.facebook.com/groups/983255258859510/",  // Junior Yacht Crew
-		"https://www.facebook.com/groups/396758057344877/",  // Yacht Stew Jobs
-	}
-
-	telegramChannels = []string{
-		"cvcrewcom",            // CV-CREW Maritime Jobs (25k+ members)
-		"yachtjobs",            // Yacht Jobs
-		"superyachtjobs",       // Superyacht Jobs
-		"megayachtjobs",        // Mega Yacht Jobs
-		"motoryachtjobs",       // Motor Yacht Jobs
-		"yachtcrewnetwork",     // Yacht Crew Network
-		"superyachtcrew",       // Superyacht Crew Network
-		"yachtingprofessionals", // Yachting Professionals
-		"marinepedia",          // Marinepedia Jobs (5.7k+ members)
-		"abroadjbs",            // Abroad Jobs (22k+ members)
+func NewYachtScraperService(
+	apify *ApifyClient,
+	actorRuns *services.ActorRunService,
+	cursors *services.CursorService,
+	sources *services.ScrapeSourceService,
+	rules *RuleEngine,
+	claims *RunClaims,
+) *YachtScraperService {
+	return &YachtScraperService{
+		apify:     apify,
+		actorRuns: actorRuns,
+		cursors:   cursors,
+		sources:   sources,
+		rules:     rules,
+		claims:    claims,
+		waiters:   make(map[string]chan struct{}),
 	}
-)
+}
+
+// CompleteRun wakes up the fetch waiting for the given actor run. It reports
+// false when nothing is waiting for the run, e.g. after a server restart.
+func (s *YachtScraperService) CompleteRun(runID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-func NewYachtScraperService(jobService *services.JobService) *YachtScraperService {
-	apifyKey := os.Getenv("APIFY_API_KEY")
-	if apifyKey == "" {
-		log.Println("Warning: APIFY_API_KEY not set, yacht scraper will be disabled")
+	done, ok := s.waiters[runID]
+	if !ok {
+		return false
 	}
 
-	return &YachtScraperService{
-		jobService: jobService,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		apifyKey: apifyKey,
+	select {
+	case done <- struct{}{}:
+	default:
 	}
+	return true
 }
 
-// ScrapeYachtJobs scrapes yacht jobs from Facebook and Telegram
-func (s *YachtScraperService) ScrapeYachtJobs() error {
-	if s.apifyKey == "" {
-		log.Println("Skipping yacht scraping - no APIFY_API_KEY provided")
-		return nil
-	}
+// FacebookSource scrapes yacht jobs from the Facebook crew groups via Apify
+type FacebookSource struct {
+	yacht *YachtScraperService
+}
 
-	log.Println("🚀 Starting Yacht Job Scraper System")
-	log.Println("====================================================")
+func NewFacebookSource(yacht *YachtScraperService) *FacebookSource {
+	return &FacebookSource{yacht: yacht}
+}
+
+func (s *FacebookSource) Name() string {
+	return "facebook"
+}
 
-	// Scrape Facebook groups
-	facebookPosts, err := s.scrapeFacebookGroups()
+func (s *FacebookSource) Fetch(ctx context.Context) ([]Item, error) {
+	if !s.yacht.apify.Enabled() {
+		log.Println("Skipping Facebook scraping - no APIFY_API_KEY provided")
+		return nil, nil
+	}
+
+	posts, err := s.yacht.scrapeFacebookGroups(ctx)
 	if err != nil {
 		log.Printf("❌ Facebook scraping failed: %v", err)
-		facebookPosts = []ScrapedPost{}
+		return nil, err
 	}
+	return postItems(posts), nil
+}
+
+func (s *FacebookSource) Parse(item Item) []*models.Job {
+	return s.yacht.parsePost(item)
+}
 
-	// Scrape Telegram channels
-	telegramPosts, err := s.scrapeTelegramChannels()
+// ParseCrew extracts the availability of a crew member from a group post
+func (s *FacebookSource) ParseCrew(item Item) *models.CrewAvailabilityPost {
+	return s.yacht.parseCrewPost(item)
+}
+
+// Resume collects the posts of a previously started actor run
+func (s *FacebookSource) Resume(ctx context.Context, runID string) ([]Item, error) {
+	posts, err := s.yacht.collectRun(ctx, "facebook", runID, facebookRunTimeout)
 	if err != nil {
-		log.Printf("❌ Telegram scraping failed: %v", err)
-		telegramPosts = []ScrapedPost{}
+		return nil, err
 	}
+	return postItems(posts), nil
+}
 
-	allPosts := append(facebookPosts, telegramPosts...)
-	log.Printf("📊 Total posts scraped: %d", len(allPosts))
+// TelegramSource scrapes yacht jobs from the Telegram crew channels via Apify
+type TelegramSource struct {
+	yacht *YachtScraperService
+}
 
-	if len(allPosts) == 0 {
-		log.Println("❌ No posts found to process")
-		return nil
+func NewTelegramSource(yacht *YachtScraperService) *TelegramSource {
+	return &TelegramSource{yacht: yacht}
+}
+
+func (s *TelegramSource) Name() string {
+	return "telegram"
+}
+
+func (s *TelegramSource) Fetch(ctx context.Context) ([]Item, error) {
+	if !s.yacht.apify.Enabled() {
+		log.Println("Skipping Telegram scraping - no APIFY_API_KEY provided")
+		return nil, nil
 	}
 
-	// Process posts and extract jobs (simplified without OpenAI)
-	jobs := s.extractJobsFromPosts(allPosts)
-	log.Printf("💼 Jobs extracted: %d", len(jobs))
+	posts, err := s.yacht.scrapeTelegramChannels(ctx)
+	if err != nil {
+		log.Printf("❌ Telegram scraping failed: %v", err)
+		return nil, err
+	}
+	return postItems(posts), nil
+}
 
-	// Save jobs to database
-	saved := 0
-	for _, job := range jobs {
-		if err := s.jobService.CreateJob(job); err != nil {
-			log.Printf("Error saving job: %v", err)
-		} else {
-			saved++
-		}
+func (s *TelegramSource) Parse(item Item) []*models.Job {
+	return s.yacht.parsePost(item)
+}
+
+// ParseCrew extracts the availability of a crew member from a channel message
+func (s *TelegramSource) ParseCrew(item Item) *models.CrewAvailabilityPost {
+	return s.yacht.parseCrewPost(item)
+}
+
+// Resume collects the messages of a previously started actor run
+func (s *TelegramSource) Resume(ctx context.Context, runID string) ([]Item, error) {
+	posts, err := s.yacht.collectRun(ctx, "telegram", runID, telegramRunTimeout)
+	if err != nil {
+		return nil, err
 	}
+	return postItems(posts), nil
+}
 
-	log.Printf("💾 Jobs saved: %d/%d", saved, len(jobs))
-	log.Println("🎉 Yacht scraping completed")
-	return nil
+// postItems wraps scraped posts as source items
+func postItems(posts []ScrapedPost) []Item {
+	items := make([]Item, len(posts))
+	for i := range posts {
+		items[i] = Item{Post: &posts[i]}
+	}
+	return items
 }
 
-func (s *YachtScraperService) scrapeFacebookGroups() ([]ScrapedPost, error) {
+func (s *YachtScraperService) scrapeFacebookGroups(ctx context.Context) ([]ScrapedPost, error) {
 	log.Println("🌐 Starting Facebook scraping...")
 
-	runID, err := s.startFacebookActor()
+	groups, err := s.sources.ListEnabled(models.ScrapeSourceTypeFacebook)
 	if err != nil {
 		return nil, err
 	}
+	if len(groups) == 0 {
+		log.Println("Skipping Facebook scraping - no enabled groups")
+		return nil, nil
+	}
 
-	if err := s.waitForCompletion(runID, 10*time.Minute); err != nil {
+	runID, err := s.startFacebookActor(ctx, groups)
+	if err != nil {
 		return nil, err
 	}
 
-	posts, err := s.getRunResults(runID)
+	posts, err := s.collectRun(ctx, "facebook", runID, facebookRunTimeout)
 	if err != nil {
 		return nil, err
 	}
@@ -169,380 +229,550 @@ func (s *YachtScraperService) scrapeFacebookGroups() ([]ScrapedPost, error) {
 	return posts, nil
 }
 
-func (s *YachtScraperService) scrapeTelegramChannels() ([]ScrapedPost, error) {
+func (s *YachtScraperService) scrapeTelegramChannels(ctx context.Context) ([]ScrapedPost, error) {
 	log.Println("📱 Starting Telegram scraping...")
 
-	runID, err := s.startTelegramActor()
+	channels, err := s.sources.ListEnabled(models.ScrapeSourceTypeTelegram)
 	if err != nil {
 		return nil, err
 	}
-
-	if err := s.waitForCompletion(runID, 15*time.Minute); err != nil {
-		return nil, err
+	if len(channels) == 0 {
+		log.Println("Skipping Telegram scraping - no enabled channels")
+		return nil, nil
 	}
 
-	posts, err := s.getRunResults(runID)
-	if err != nil {
-		return nil, err
+	runIDs, errs := s.startTelegramActors(ctx, channels)
+
+	// A failed run does not hold back the channels of the others
+	var posts []ScrapedPost
+	collected := 0
+	for _, runID := range runIDs {
+		runPosts, err := s.collectRun(ctx, "telegram", runID, telegramRunTimeout)
+		if err != nil {
+			log.Printf("Error collecting Telegram actor run %s: %v", runID, err)
+			errs = append(errs, err)
+			continue
+		}
+		posts = append(posts, runPosts...)
+		collected++
+	}
+	if collected == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
 	}
 
 	log.Printf("✅ Retrieved %d Telegram messages", len(posts))
 	return posts, nil
 }
 
-func (s *YachtScraperService) startFacebookActor() (string, error) {
-	url := "https://api.apify.com/v2/acts/apify~facebook-groups-scraper/runs"
-
-	startUrls := make([]map[string]string, len(facebookGroups))
-	for i, group := range facebookGroups {
-		startUrls[i] = map[string]string{"url": group}
+// startFacebookActor starts a run over the given groups. The actor takes one
+// post limit per run, so the largest max_posts of the groups applies.
+func (s *YachtScraperService) startFacebookActor(ctx context.Context, groups []models.ScrapeSource) (string, error) {
+	startUrls := make([]map[string]string, len(groups))
+	identifiers := make([]string, len(groups))
+	for i, group := range groups {
+		startUrls[i] = map[string]string{"url": group.Identifier}
+		identifiers[i] = group.Identifier
 	}
 
 	payload := ApifyRunRequest{
 		StartUrls:   startUrls,
-		MaxPosts:    20,
+		MaxPosts:    maxPosts(groups),
 		MaxPostDate: time.Now().Format("2006-01-02"),
 		MaxComments: 0,
 	}
 
-	return s.runApifyActor(url, payload)
+	// The actor takes a single date for all groups, so request posts newer
+	// than the least recent group cursor and filter the rest client-side
+	if oldest := s.oldestCursor("facebook", identifiers, facebookGroupKey); oldest != nil {
+		payload.NewerThan = oldest.Format("2006-01-02")
+		if payload.MaxPosts < incrementalMaxPosts {
+			payload.MaxPosts = incrementalMaxPosts
+		}
+	}
+
+	return s.startActor(ctx, "facebook", facebookActorID, payload)
 }
 
-func (s *YachtScraperService) startTelegramActor() (string, error) {
-	url := "https://api.apify.com/v2/acts/cYGAiWbhiASIZSZb5/runs"
+// startTelegramActors starts the runs over the given channels. The actor takes
+// one range of message IDs per run, so every channel with a cursor gets a run
+// of its own starting after its last message, while channels without one
+// share a run over their latest messages. Messages at or behind a cursor are
+// still dropped after the run, in case the actor returns them anyway.
+func (s *YachtScraperService) startTelegramActors(ctx context.Context, channels []models.ScrapeSource) ([]string, []error) {
+	cursors, err := s.cursors.GetCursors("telegram")
+	if err != nil {
+		log.Printf("Error loading telegram cursors, reading the latest messages: %v", err)
+		cursors = map[string]models.ScrapeCursor{}
+	}
+
+	var runIDs []string
+	var errs []error
+	var fresh []models.ScrapeSource
+	for _, channel := range channels {
+		cursor, ok := cursors[telegramChannelKey(channel.Identifier)]
+		if !ok || cursor.LastMessageID == 0 {
+			fresh = append(fresh, channel)
+			continue
+		}
+
+		limit := channel.MaxPosts
+		if limit < incrementalMaxPosts {
+			limit = incrementalMaxPosts
+		}
+		first := cursor.LastMessageID + 1
+		runID, err := s.startTelegramActor(ctx, []string{channel.Identifier}, first, first+int64(limit)-1, limit)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", channel.Identifier, err))
+			continue
+		}
+		runIDs = append(runIDs, runID)
+	}
+
+	if len(fresh) > 0 {
+		names := make([]string, len(fresh))
+		for i, channel := range fresh {
+			names[i] = channel.Identifier
+		}
+		limit := maxPosts(fresh)
+		runID, err := s.startTelegramActor(ctx, names, 1, int64(limit), limit)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			runIDs = append(runIDs, runID)
+		}
+	}
+	return runIDs, errs
+}
 
+// startTelegramActor starts a run over the given channels, reading the
+// messages from postsFrom to postsTo
+func (s *YachtScraperService) startTelegramActor(ctx context.Context, names []string, postsFrom, postsTo int64, limit int) (string, error) {
 	payload := ApifyRunRequest{
-		Channels:  telegramChannels,
-		PostsFrom: 1,
-		PostsTo:   20,
-		MaxPosts:  20,
+		Channels:  names,
+		PostsFrom: postsFrom,
+		PostsTo:   postsTo,
+		MaxPosts:  limit,
 		Timeout:   900, // 15 minutes
 	}
 
-	return s.runApifyActor(url, payload)
+	return s.startActor(ctx, "telegram", telegramActorID, payload)
 }
 
-func (s *YachtScraperService) runApifyActor(url string, payload ApifyRunRequest) (string, error) {
-	payloadBytes, err := json.Marshal(payload)
+// startActor starts an actor run and records it so it can be resumed
+func (s *YachtScraperService) startActor(ctx context.Context, source, actorID string, payload ApifyRunRequest) (string, error) {
+	run, err := s.apify.StartActor(ctx, actorID, payload)
 	if err != nil {
 		return "", err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payloadBytes))
+	err = s.actorRuns.SaveStarted(&models.ActorRun{
+		RunID:     run.ID,
+		Source:    source,
+		ActorID:   actorID,
+		Status:    run.Status,
+		StartedAt: time.Now(),
+	})
 	if err != nil {
-		return "", err
+		log.Printf("Error recording actor run %s: %v", run.ID, err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+s.apifyKey)
-
-	resp, err := s.client.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
+	return run.ID, nil
+}
 
-	if resp.StatusCode != 201 {
-		return "", fmt.Errorf("failed to start actor: HTTP %d", resp.StatusCode)
+// collectRun waits for an actor run to finish and returns its posts. The run
+// is claimed while it is collected, so a webhook or resume arriving meanwhile
+// leaves it alone, and is marked collected, and the posts seen, once they have
+// been saved.
+func (s *YachtScraperService) collectRun(ctx context.Context, source, runID string, timeout time.Duration) ([]ScrapedPost, error) {
+	if !s.claims.Claim(runID) {
+		log.Printf("Actor run %s is already being collected", runID)
+		return nil, nil
 	}
 
-	var response ApifyRunResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return "", err
+	posts, err := s.collectClaimedRun(ctx, runID, timeout)
+	if err != nil {
+		s.claims.Release(runID)
+		return nil, err
 	}
+	posts, commit := s.filterNewPosts(source, posts)
 
-	return response.Data.ID, nil
+	deferCommit(ctx, func(failed []Item) {
+		commit(failed)
+		// Failed posts are fetched again by later runs, not by resuming this one
+		if err := s.actorRuns.MarkCollected(runID); err != nil {
+			log.Printf("Error recording actor run %s: %v", runID, err)
+		}
+		s.claims.Release(runID)
+	})
+	return posts, nil
 }
 
-func (s *YachtScraperService) waitForCompletion(runID string, timeout time.Duration) error {
-	start := time.Now()
-	ticker := time.NewTicker(10 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			status, err := s.getRunStatus(runID)
-			if err != nil {
-				return err
-			}
-
-			switch status {
-			case "SUCCEEDED":
-				return nil
-			case "FAILED", "ABORTED", "TIMED-OUT":
-				return fmt.Errorf("actor run %s: %s", runID, status)
-			}
-
-			if time.Since(start) > timeout {
-				return fmt.Errorf("timeout waiting for actor completion")
-			}
+// collectClaimedRun waits for a claimed actor run to finish and returns its posts
+func (s *YachtScraperService) collectClaimedRun(ctx context.Context, runID string, timeout time.Duration) ([]ScrapedPost, error) {
+	run, err := s.awaitRun(ctx, runID, timeout)
+	if run != nil && run.Finished() {
+		if err := s.actorRuns.MarkFinished(runID, run.Status); err != nil {
+			log.Printf("Error recording actor run %s: %v", runID, err)
 		}
 	}
+	if err != nil {
+		return nil, err
+	}
+	return s.getRunResults(ctx, runID)
 }
 
-func (s *YachtScraperService) getRunStatus(runID string) (string, error) {
-	url := fmt.Sprintf("https://api.apify.com/v2/actor-runs/%s", runID)
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return "", err
+// awaitRun waits for an actor run to finish. With webhooks configured it
+// sleeps until the completion callback arrives, otherwise it polls.
+func (s *YachtScraperService) awaitRun(ctx context.Context, runID string, timeout time.Duration) (*ApifyRun, error) {
+	if !s.apify.WebhooksEnabled() {
+		return s.apify.WaitForRun(ctx, runID, timeout)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+s.apifyKey)
+	done := s.addWaiter(runID)
+	defer s.removeWaiter(runID)
 
-	resp, err := s.client.Do(req)
+	// The run may have finished before the waiter was registered
+	run, err := s.apify.GetRun(ctx, runID)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer resp.Body.Close()
-
-	var response ApifyStatusResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return "", err
+	if run.Finished() {
+		return run, run.Err()
 	}
 
-	return response.Data.Status, nil
-}
-
-func (s *YachtScraperService) getRunResults(runID string) ([]ScrapedPost, error) {
-	url := fmt.Sprintf("https://api.apify.com/v2/actor-runs/%s/dataset/items", runID)
+	deadline := time.Now().Add(timeout)
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-done:
+	case <-timer.C:
+		log.Printf("No webhook received for actor run %s, checking its status", runID)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+s.apifyKey)
-
-	resp, err := s.client.Do(req)
+	// Always confirm the status with Apify rather than trusting the callback
+	run, err = s.apify.GetRun(ctx, runID)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	if run.Finished() {
+		return run, run.Err()
+	}
 
-	var posts []ScrapedPost
-	if err := json.NewDecoder(resp.Body).Decode(&posts); err != nil {
-		return nil, err
+	// The callback arrived before the run status settled, keep polling
+	if remaining := time.Until(deadline); remaining > 0 {
+		return s.apify.WaitForRun(ctx, runID, remaining)
 	}
+	return run, fmt.Errorf("timeout waiting for actor run %s", runID)
+}
 
-	return posts, nil
+func (s *YachtScraperService) addWaiter(runID string) chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	done := make(chan struct{}, 1)
+	s.waiters[runID] = done
+	return done
 }
 
-// extractJobsFromPosts - simplified job extraction without OpenAI
-func (s *YachtScraperService) extractJobsFromPosts(posts []ScrapedPost) []*models.Job {
-	var jobs []*models.Job
+func (s *YachtScraperService) removeWaiter(runID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.waiters, runID)
+}
 
+// filterNewPosts drops posts at or behind their channel's cursor and posts
+// whose URL was already processed. The returned commit marks the rest seen and
+// advances the cursors past them, except for channels with failed posts.
+func (s *YachtScraperService) filterNewPosts(source string, posts []ScrapedPost) ([]ScrapedPost, Commit) {
+	cursors, err := s.cursors.GetCursors(source)
+	if err != nil {
+		log.Printf("Error loading %s cursors, processing all posts: %v", source, err)
+		return posts, func([]Item) {}
+	}
+
+	urls := make([]string, 0, len(posts))
 	for _, post := range posts {
-		if s.isJobPost(post.Text) {
-			job := s.convertPostToJob(post)
-			if job != nil {
-				jobs = append(jobs, job)
-			}
+		urls = append(urls, post.URL)
+	}
+	seen, err := s.cursors.SeenURLs(urls)
+	if err != nil {
+		log.Printf("Error loading seen %s posts: %v", source, err)
+		seen = map[string]bool{}
+	}
+
+	fresh := make([]ScrapedPost, 0, len(posts))
+	for _, post := range posts {
+		channel, messageID := postChannel(post)
+		if seen[post.URL] {
+			continue
 		}
+		if cursor, ok := cursors[channel]; ok && !cursor.IsNewer(post.Timestamp, messageID) {
+			continue
+		}
+		fresh = append(fresh, post)
+		seen[post.URL] = true
 	}
 
-	return jobs
-}
+	if skipped := len(posts) - len(fresh); skipped > 0 {
+		log.Printf("⏭️ Skipped %d already seen %s posts", skipped, source)
+	}
 
-// isJobPost - simplified job detection using keywords
-func (s *YachtScraperService) isJobPost(text string) bool {
-	text = strings.ToLower(text)
+	// Callers may still change the returned posts, so the commit keeps a copy
+	kept := append([]ScrapedPost(nil), fresh...)
+	return fresh, func(failed []Item) {
+		s.commitPosts(source, kept, failed)
+	}
+}
 
-	// Job indicators
-	jobKeywords := []string{
-		"hiring", "job", "position", "crew", "vacancy", "looking for",
-		"seeking", "needed", "required", "opportunity", "application",
-		"captain", "engineer", "stewardess", "chef", "deckhand",
-		"bosun", "officer", "stew", "cook", "mate",
+// commitPosts marks saved posts seen and advances the cursors of the channels
+// whose posts were all saved. A cursor is held back at a failed post so that
+// later runs fetch it again.
+func (s *YachtScraperService) commitPosts(source string, posts []ScrapedPost, failed []Item) {
+	// Reload the cursors, which may have changed since the fetch
+	cursors, err := s.cursors.GetCursors(source)
+	if err != nil {
+		log.Printf("Error loading %s cursors: %v", source, err)
+		return
 	}
 
-	// Yacht/marine indicators
-	yachtKeywords := []string{
-		"yacht", "superyacht", "motor yacht", "sailing yacht",
-		"vessel", "boat", "ship", "charter", "private",
+	failedURLs := make(map[string]bool)
+	failedChannels := make(map[string]bool)
+	for _, item := range failed {
+		if item.Post == nil {
+			continue
+		}
+		channel, _ := postChannel(*item.Post)
+		failedChannels[channel] = true
+		if item.Post.URL != "" {
+			failedURLs[item.Post.URL] = true
+		}
 	}
 
-	hasJobKeyword := false
-	hasYachtKeyword := false
+	advanced := make(map[string]*models.ScrapeCursor)
+	seenByChannel := make(map[string][]string)
 
-	for _, keyword := range jobKeywords {
-		if strings.Contains(text, keyword) {
-			hasJobKeyword = true
-			break
+	for _, post := range posts {
+		channel, messageID := postChannel(post)
+		if post.URL != "" && failedURLs[post.URL] {
+			continue
+		}
+		if post.URL != "" {
+			seenByChannel[channel] = append(seenByChannel[channel], post.URL)
 		}
-	}
 
-	for _, keyword := range yachtKeywords {
-		if strings.Contains(text, keyword) {
-			hasYachtKeyword = true
-			break
+		if channel == "" || failedChannels[channel] {
+			continue
+		}
+		cursor, ok := advanced[channel]
+		if !ok {
+			existing := cursors[channel]
+			cursor = &existing
+			cursor.Source = source
+			cursor.Channel = channel
+			advanced[channel] = cursor
 		}
+		cursor.Advance(post.Timestamp, messageID)
 	}
 
-	return hasJobKeyword && hasYachtKeyword
+	for channel, urls := range seenByChannel {
+		if err := s.cursors.MarkSeen(source, channel, urls); err != nil {
+			log.Printf("Error marking %s posts seen: %v", source, err)
+		}
+	}
+	for _, cursor := range advanced {
+		if err := s.cursors.SaveCursor(cursor); err != nil {
+			log.Printf("Error saving %s cursor for %s: %v", source, cursor.Channel, err)
+		}
+	}
 }
 
-// convertPostToJob - convert scraped post to job model
-func (s *YachtScraperService) convertPostToJob(post ScrapedPost) *models.Job {
-	title := s.extractJobTitle(post.Text)
-	if title == "" {
-		title = "Yacht Crew Position"
+// oldestCursor returns the least recent post time across the given channels,
+// or nil when any of them has no cursor yet
+func (s *YachtScraperService) oldestCursor(source string, channels []string, key func(string) string) *time.Time {
+	cursors, err := s.cursors.GetCursors(source)
+	if err != nil {
+		log.Printf("Error loading %s cursors: %v", source, err)
+		return nil
 	}
 
-	company := s.extractCompany(post.Text)
-	if company == "" {
-		company = "Private Yacht"
+	var oldest *time.Time
+	for _, channel := range channels {
+		cursor, ok := cursors[key(channel)]
+		if !ok || cursor.LastPostAt == nil {
+			return nil
+		}
+		if oldest == nil || cursor.LastPostAt.Before(*oldest) {
+			oldest = cursor.LastPostAt
+		}
 	}
+	return oldest
+}
 
-	return &models.Job{
-		ID:          uuid.New().String(),
-		Title:       title,
-		Company:     company,
-		Location:    s.extractLocation(post.Text),
-		Type:        s.extractJobType(post.Text),
-		Vessel:      s.extractVesselType(post.Text),
-		Duration:    s.extractDuration(post.Text),
-		Salary:      s.extractSalary(post.Text),
-		Description: s.cleanText(post.Text),
-		SourceURL:   post.URL,
-		Source:      "Yacht Scraper",
-		PostedAt:    post.Timestamp,
-		ScrapedAt:   time.Now(),
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+// maxPosts returns the largest post limit of the given groups or channels
+func maxPosts(sources []models.ScrapeSource) int {
+	limit := 0
+	for _, source := range sources {
+		if source.MaxPosts > limit {
+			limit = source.MaxPosts
+		}
 	}
+	return limit
 }
 
-// Helper functions for data extraction
-func (s *YachtScraperService) extractJobTitle(text string) string {
-	text = strings.ToLower(text)
-	
-	titles := map[string]string{
-		"captain":     "Captain",
-		"chief officer": "Chief Officer",
-		"first officer": "First Officer",
-		"second officer": "Second Officer",
-		"bosun":       "Bosun",
-		"deckhand":    "Deckhand",
-		"able seaman": "Able Seaman",
-		"chief engineer": "Chief Engineer",
-		"first engineer": "First Engineer",
-		"second engineer": "Second Engineer",
-		"engineer":    "Engineer",
-		"chief stewardess": "Chief Stewardess",
-		"chief stew":  "Chief Stewardess",
-		"stewardess":  "Stewardess",
-		"stew":        "Stewardess",
-		"chef":        "Chef",
-		"cook":        "Cook",
-		"purser":      "Purser",
-	}
-
-	for keyword, title := range titles {
-		if strings.Contains(text, keyword) {
-			return title
+// postChannel returns the Facebook group or Telegram channel a post was
+// scraped from and, for Telegram, its message ID
+func postChannel(post ScrapedPost) (string, int64) {
+	if parsed, err := url.Parse(post.URL); err == nil {
+		host := strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+		segments := strings.FieldsFunc(parsed.Path, func(r rune) bool { return r == '/' })
+
+		switch {
+		case strings.HasSuffix(host, "facebook.com") && len(segments) >= 2 && segments[0] == "groups":
+			return strings.ToLower(segments[1]), 0
+		case host == "t.me" || host == "telegram.me":
+			if len(segments) > 0 && segments[0] == "s" {
+				segments = segments[1:] // Public preview URLs, t.me/s/<channel>/<id>
+			}
+			if len(segments) > 0 {
+				var messageID int64
+				if len(segments) > 1 {
+					messageID, _ = strconv.ParseInt(segments[1], 10, 64)
+				}
+				return strings.ToLower(segments[0]), messageID
+			}
 		}
 	}
-	return ""
+
+	return strings.ToLower(strings.TrimPrefix(post.ChannelName, "@")), 0
 }
 
-func (s *YachtScraperService) extractCompany(text string) string {
-	// Look for company indicators
-	if strings.Contains(strings.ToLower(text), "m/y ") {
-		return "Motor Yacht"
-	}
-	if strings.Contains(strings.ToLower(text), "s/y ") {
-		return "Sailing Yacht"
-	}
-	return ""
+// telegramChannelKey returns the cursor channel of a Telegram channel username or URL
+func telegramChannelKey(identifier string) string {
+	channel, _ := postChannel(ScrapedPost{URL: identifier, ChannelName: identifier})
+	return channel
 }
 
-func (s *YachtScraperService) extractLocation(text string) string {
-	text = strings.ToLower(text)
-	
-	locations := []string{
-		"mediterranean", "caribbean", "bahamas", "florida", "french riviera",
-		"monaco", "antibes", "palma", "barcelona", "genoa", "miami",
-		"fort lauderdale", "newport", "sardinia", "corsica", "ibiza",
-		"worldwide", "global", "anywhere",
+// facebookGroupKey returns the cursor channel of a Facebook group URL
+func facebookGroupKey(groupURL string) string {
+	channel, _ := postChannel(ScrapedPost{URL: groupURL})
+	return channel
+}
+
+func (s *YachtScraperService) getRunResults(ctx context.Context, runID string) ([]ScrapedPost, error) {
+	items, err := s.apify.GetDatasetItems(ctx, runID)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, location := range locations {
-		if strings.Contains(text, location) {
-			return strings.Title(location)
+	posts := make([]ScrapedPost, 0, len(items))
+	for _, item := range items {
+		var post ScrapedPost
+		if err := json.Unmarshal(item, &post); err != nil {
+			log.Printf("Skipping undecodable post from run %s: %v", runID, err)
+			continue
 		}
+		posts = append(posts, post)
 	}
-	return ""
+
+	return posts, nil
 }
 
-func (s *YachtScraperService) extractJobType(text string) string {
-	text = strings.ToLower(text)
-	
-	if strings.Contains(text, "captain") || strings.Contains(text, "officer") || 
-	   strings.Contains(text, "bosun") || strings.Contains(text, "deckhand") {
-		return "deck"
-	}
-	if strings.Contains(text, "engineer") || strings.Contains(text, "eto") {
-		return "engine"
-	}
-	if strings.Contains(text, "stewardess") || strings.Contains(text, "stew") || 
-	   strings.Contains(text, "chef") || strings.Contains(text, "cook") {
-		return "interior"
+// extractJobsFromPosts - simplified job extraction without OpenAI
+func (s *YachtScraperService) extractJobsFromPosts(posts []ScrapedPost) []*models.Job {
+	var jobs []*models.Job
+
+	for _, post := range posts {
+		lang := language.Detect(post.Text)
+		extractor := s.rules.LanguageExtractor(postRules, lang)
+		// Crew advertising their availability name positions like job posts do
+		if extractor.Accepts(post.Text) && !isCrewAvailability(extractor, post.Text) {
+			jobs = append(jobs, s.convertPostToJobs(extractor, post, lang)...)
+		}
 	}
-	return "crew"
+
+	return jobs
 }
 
-func (s *YachtScraperService) extractVesselType(text string) string {
-	text = strings.ToLower(text)
-	
-	if strings.Contains(text, "motor yacht") || strings.Contains(text, "m/y") {
-		return "motor yacht"
-	}
-	if strings.Contains(text, "sailing yacht") || strings.Contains(text, "s/y") {
-		return "sailing yacht"
-	}
-	if strings.Contains(text, "superyacht") {
-		return "superyacht"
-	}
-	if strings.Contains(text, "catamaran") {
-		return "catamaran"
+// parsePost extracts the jobs from a single scraped post item
+func (s *YachtScraperService) parsePost(item Item) []*models.Job {
+	if item.Post == nil {
+		return nil
 	}
-	return "yacht"
+	return s.extractJobsFromPosts([]ScrapedPost{*item.Post})
 }
 
-func (s *YachtScraperService) extractDuration(text string) string {
-	text = strings.ToLower(text)
-	
-	durations := []string{
-		"permanent", "rotational", "seasonal", "summer season",
-		"winter season", "4/4", "6/6", "8/4", "2/2", "3/3",
-		"6 months", "4 months", "8 months",
+// parseCrewPost extracts a crew availability post from a single scraped post
+// item, or returns nil when the post is not one
+func (s *YachtScraperService) parseCrewPost(item Item) *models.CrewAvailabilityPost {
+	if item.Post == nil {
+		return nil
+	}
+	lang := language.Detect(item.Post.Text)
+	extractor := s.rules.LanguageExtractor(postRules, lang)
+	if !isCrewAvailability(extractor, item.Post.Text) {
+		return nil
 	}
+	return convertCrewPost(extractor, *item.Post, lang)
+}
 
-	for _, duration := range durations {
-		if strings.Contains(text, duration) {
-			return duration
+// convertPostToJobs converts a post to one job per position it advertises.
+// The jobs share the vessel, location and dates of the post, while each takes
+// its salary and requirements from the text about its role when there is one.
+// Salaries and contract terms of posts in other languages are read from their
+// English rewording.
+func (s *YachtScraperService) convertPostToJobs(extractor *Extractor, post ScrapedPost, lang string) []*models.Job {
+	segments := segmentRoles(extractor, post.Text)
+	if len(segments.Roles) == 0 {
+		job := s.convertPostToJob(extractor, post, lang)
+		setJobSalary(job, language.Normalize(post.Text, lang))
+		job.Requirements = requirements(post.Text)
+		return []*models.Job{job}
+	}
+
+	jobs := make([]*models.Job, 0, len(segments.Roles))
+	for _, role := range segments.Roles {
+		job := s.convertPostToJob(extractor, post, lang)
+		job.Title = role.Title
+		job.Type = extractor.Field(FieldType, role.Text)
+
+		setJobSalary(job, language.Normalize(role.Text, lang))
+		if job.Salary == "" {
+			setJobSalary(job, language.Normalize(segments.Shared, lang))
 		}
+		job.Requirements = strings.TrimSpace(requirements(role.Text) + "\n" + requirements(segments.Shared))
+
+		// Roles split from one post share its URL but are different jobs
+		job.URLFingerprint = services.RoleURLFingerprint(post.URL, role.Title)
+		jobs = append(jobs, job)
 	}
-	return ""
+	log.Printf("✂️ Split post into %d roles: %s", len(jobs), post.URL)
+	return jobs
 }
 
-func (s *YachtScraperService) extractSalary(text string) string {
-	// Look for salary patterns
-	if strings.Contains(text, "€") || strings.Contains(text, "$") || 
-	   strings.Contains(text, "salary") || strings.Contains(text, "wage") {
-		// Simple extraction - could be improved with regex
-		lines := strings.Split(text, "\n")
-		for _, line := range lines {
-			line = strings.ToLower(line)
-			if strings.Contains(line, "€") || strings.Contains(line, "$") ||
-			   strings.Contains(line, "salary") || strings.Contains(line, "wage") {
-				return strings.TrimSpace(line)
-			}
-		}
+// convertPostToJob - convert scraped post to job model, without its salary
+func (s *YachtScraperService) convertPostToJob(extractor *Extractor, post ScrapedPost, lang string) *models.Job {
+	job := &models.Job{
+		ID:          uuid.New().String(),
+		Title:       extractor.Field(FieldTitle, post.Text),
+		Company:     extractor.Field(FieldCompany, post.Text),
+		Type:        extractor.Field(FieldType, post.Text),
+		Vessel:      extractor.Field(FieldVessel, post.Text),
+		Description: s.cleanText(post.Text),
+		SourceURL:   post.URL,
+		Source:      "Yacht Scraper",
+		PostedAt:    post.Timestamp,
+		Language:    lang,
+		ScrapedAt:   time.Now(),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
 	}
-	return ""
+	setJobDuration(job, language.Normalize(post.Text, lang))
+	setJobLocation(job, language.Normalize(post.Text, lang))
+
+	return job
 }
 
 func (s *YachtScraperService) cleanText(text string) string {
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/database"
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/models"
+)
+
+// pendingRunMaxAge is how long an uncollected actor run is worth resuming;
+// Apify keeps run datasets around for at least a week
+const pendingRunMaxAge = 7 * 24 * time.Hour
+
+type ActorRunService struct {
+	db     *database.DB
+	driver string
+}
+
+func NewActorRunService(db *database.DB) *ActorRunService {
+	driver := "sqlite3" // default fallback
+	if db != nil {
+		driver = db.GetDriver()
+	}
+	return &ActorRunService{
+		db:     db,
+		driver: driver,
+	}
+}
+
+// getPlaceholder returns the appropriate placeholder for the database driver
+func (s *ActorRunService) getPlaceholder(index int) string {
+	if s.driver == "postgres" {
+		return fmt.Sprintf("$%d", index)
+	}
+	return "?"
+}
+
+// SaveStarted records a newly started actor run
+func (s *ActorRunService) SaveStarted(run *models.ActorRun) error {
+	query := fmt.Sprintf(`
+		INSERT INTO actor_runs (run_id, source, actor_id, status, started_at)
+		VALUES (%s, %s, %s, %s, %s)
+	`, s.getPlaceholder(1), s.getPlaceholder(2), s.getPlaceholder(3), s.getPlaceholder(4), s.getPlaceholder(5))
+
+	_, err := s.db.Exec(query, run.RunID, run.Source, run.ActorID, run.Status, run.StartedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save actor run: %w", err)
+	}
+	return nil
+}
+
+// MarkFinished records the terminal status of an actor run
+func (s *ActorRunService) MarkFinished(runID, status string) error {
+	query := fmt.Sprintf("UPDATE actor_runs SET status = %s, finished_at = %s WHERE run_id = %s",
+		s.getPlaceholder(1), s.getPlaceholder(2), s.getPlaceholder(3))
+
+	if _, err := s.db.Exec(query, status, time.Now(), runID); err != nil {
+		return fmt.Errorf("failed to update actor run: %w", err)
+	}
+	return nil
+}
+
+// MarkCollected records that the dataset of an actor run has been processed
+func (s *ActorRunService) MarkCollected(runID string) error {
+	query := fmt.Sprintf("UPDATE actor_runs SET collected_at = %s WHERE run_id = %s",
+		s.getPlaceholder(1), s.getPlaceholder(2))
+
+	if _, err := s.db.Exec(query, time.Now(), runID); err != nil {
+		return fmt.Errorf("failed to update actor run: %w", err)
+	}
+	return nil
+}
+
+// GetRun returns a tracked actor run
+func (s *ActorRunService) GetRun(runID string) (*models.ActorRun, error) {
+	query := fmt.Sprintf(`
+		SELECT run_id, source, actor_id, status, started_at, finished_at, collected_at
+		FROM actor_runs WHERE run_id = %s
+	`, s.getPlaceholder(1))
+
+	return scanActorRun(s.db.QueryRow(query, runID))
+}
+
+// ListPending returns recent actor runs whose datasets have not been collected
+func (s *ActorRunService) ListPending() ([]models.ActorRun, error) {
+	query := fmt.Sprintf(`
+		SELECT run_id, source, actor_id, status, started_at, finished_at, collected_at
+		FROM actor_runs
+		WHERE collected_at IS NULL AND started_at > %s
+		ORDER BY started_at
+	`, s.getPlaceholder(1))
+
+	rows, err := s.db.Query(query, time.Now().Add(-pendingRunMaxAge))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending actor runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []models.ActorRun
+	for rows.Next() {
+		run, err := scanActorRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, *run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return runs, nil
+}
+
+func scanActorRun(row rowScanner) (*models.ActorRun, error) {
+	run := &models.ActorRun{}
+	var finishedAt, collectedAt sql.NullTime
+
+	err := row.Scan(&run.RunID, &run.Source, &run.ActorID, &run.Status, &run.StartedAt, &finishedAt, &collectedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan actor run: %w", err)
+	}
+
+	if finishedAt.Valid {
+		run.FinishedAt = &finishedAt.Time
+	}
+	if collectedAt.Valid {
+		run.CollectedAt = &collectedAt.Time
+	}
+	return run, nil
+}
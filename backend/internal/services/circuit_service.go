@@ -0,0 +1,187 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/database"
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/models"
+)
+
+const sourceCircuitColumns = "source, consecutive_failures, open, opened_at, last_error, updated_at"
+
+type CircuitService struct {
+	db     *database.DB
+	driver string
+}
+
+func NewCircuitService(db *database.DB) *CircuitService {
+	driver := "sqlite3" // default fallback
+	if db != nil {
+		driver = db.GetDriver()
+	}
+	return &CircuitService{
+		db:     db,
+		driver: driver,
+	}
+}
+
+// getPlaceholder returns the appropriate placeholder for the database driver
+func (s *CircuitService) getPlaceholder(index int) string {
+	if s.driver == "postgres" {
+		return fmt.Sprintf("$%d", index)
+	}
+	return "?"
+}
+
+// ListCircuits returns the circuit of every source that has run
+func (s *CircuitService) ListCircuits() ([]models.SourceCircuit, error) {
+	rows, err := s.db.Query("SELECT " + sourceCircuitColumns + " FROM source_circuits ORDER BY source")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query source circuits: %w", err)
+	}
+	defer rows.Close()
+
+	circuits := []models.SourceCircuit{}
+	for rows.Next() {
+		circuit, err := scanSourceCircuit(rows)
+		if err != nil {
+			return nil, err
+		}
+		circuits = append(circuits, *circuit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return circuits, nil
+}
+
+// GetCircuit returns the circuit of a source, or sql.ErrNoRows when the
+// source never ran
+func (s *CircuitService) GetCircuit(source string) (*models.SourceCircuit, error) {
+	query := fmt.Sprintf("SELECT %s FROM source_circuits WHERE source = %s", sourceCircuitColumns, s.getPlaceholder(1))
+	return scanSourceCircuit(s.db.QueryRow(query, source))
+}
+
+// IsOpen reports whether the circuit of a source is open
+func (s *CircuitService) IsOpen(source string) (bool, error) {
+	circuit, err := s.GetCircuit(source)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return circuit.Open, nil
+}
+
+// RecordRun records the outcome of a source run. A failed run opens the
+// circuit once threshold runs in a row failed, while a successful run closes
+// it again; a threshold of 0 never opens it. It reports whether this run
+// opened the circuit.
+func (s *CircuitService) RecordRun(source string, failed bool, lastError string, threshold int) (bool, error) {
+	circuit, err := s.GetCircuit(source)
+	if errors.Is(err, sql.ErrNoRows) {
+		circuit = &models.SourceCircuit{Source: source}
+	} else if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	opened := false
+	if failed {
+		circuit.ConsecutiveFailures++
+		circuit.LastError = lastError
+		if !circuit.Open && threshold > 0 && circuit.ConsecutiveFailures >= threshold {
+			circuit.Open = true
+			circuit.OpenedAt = &now
+			opened = true
+		}
+	} else {
+		circuit.ConsecutiveFailures = 0
+		circuit.Open = false
+		circuit.OpenedAt = nil
+	}
+	circuit.UpdatedAt = now
+
+	if err := s.saveCircuit(circuit); err != nil {
+		return false, err
+	}
+	return opened, nil
+}
+
+// Reset closes the circuit of a source so it is scraped again, returning
+// sql.ErrNoRows when the source never ran
+func (s *CircuitService) Reset(source string) (*models.SourceCircuit, error) {
+	query := fmt.Sprintf(`
+		UPDATE source_circuits SET consecutive_failures = 0, open = %s, opened_at = NULL, updated_at = %s
+		WHERE source = %s
+	`, s.getPlaceholder(1), s.getPlaceholder(2), s.getPlaceholder(3))
+
+	result, err := s.db.Exec(query, false, time.Now(), source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reset source circuit: %w", err)
+	}
+	if updated, err := result.RowsAffected(); err == nil && updated == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return s.GetCircuit(source)
+}
+
+// saveCircuit creates or updates a source circuit
+func (s *CircuitService) saveCircuit(circuit *models.SourceCircuit) error {
+	var openedAt interface{}
+	if circuit.OpenedAt != nil {
+		openedAt = *circuit.OpenedAt
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE source_circuits SET consecutive_failures = %s, open = %s, opened_at = %s, last_error = %s, updated_at = %s
+		WHERE source = %s
+	`, s.getPlaceholder(1), s.getPlaceholder(2), s.getPlaceholder(3), s.getPlaceholder(4), s.getPlaceholder(5), s.getPlaceholder(6))
+
+	result, err := s.db.Exec(query, circuit.ConsecutiveFailures, circuit.Open, openedAt,
+		circuit.LastError, circuit.UpdatedAt, circuit.Source)
+	if err != nil {
+		return fmt.Errorf("failed to update source circuit: %w", err)
+	}
+	if updated, err := result.RowsAffected(); err == nil && updated > 0 {
+		return nil
+	}
+
+	query = fmt.Sprintf(`
+		INSERT INTO source_circuits (%s)
+		VALUES (%s, %s, %s, %s, %s, %s)
+	`, sourceCircuitColumns, s.getPlaceholder(1), s.getPlaceholder(2), s.getPlaceholder(3),
+		s.getPlaceholder(4), s.getPlaceholder(5), s.getPlaceholder(6))
+
+	_, err = s.db.Exec(query, circuit.Source, circuit.ConsecutiveFailures, circuit.Open, openedAt,
+		circuit.LastError, circuit.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create source circuit: %w", err)
+	}
+	return nil
+}
+
+func scanSourceCircuit(row rowScanner) (*models.SourceCircuit, error) {
+	var circuit models.SourceCircuit
+	var openedAt sql.NullTime
+	var lastError sql.NullString
+
+	err := row.Scan(&circuit.Source, &circuit.ConsecutiveFailures, &circuit.Open, &openedAt,
+		&lastError, &circuit.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan source circuit: %w", err)
+	}
+
+	if openedAt.Valid {
+		circuit.OpenedAt = &openedAt.Time
+	}
+	circuit.LastError = lastError.String
+	return &circuit, nil
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/database"
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/geo"
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/language"
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidCrewFilter is returned for crew availability filters that cannot be applied
+var ErrInvalidCrewFilter = errors.New("invalid crew availability filter")
+
+const crewAvailabilityColumns = `id, source, url, url_fingerprint, content_fingerprint, group_name, channel_name,
+	text, position, available_from, availability, certifications, location, country_code, region,
+	language, posted_at, created_at`
+
+type CrewAvailabilityService struct {
+	db     *database.DB
+	driver string
+}
+
+func NewCrewAvailabilityService(db *database.DB) *CrewAvailabilityService {
+	driver := "sqlite3" // default fallback
+	if db != nil {
+		driver = db.GetDriver()
+	}
+	return &CrewAvailabilityService{
+		db:     db,
+		driver: driver,
+	}
+}
+
+// getPlaceholder returns the appropriate placeholder for the database driver
+func (s *CrewAvailabilityService) getPlaceholder(index int) string {
+	if s.driver == "postgres" {
+		return fmt.Sprintf("$%d", index)
+	}
+	return "?"
+}
+
+// SavePost stores a crew availability post unless the same post, or the same
+// text reposted elsewhere, was saved before. It reports whether it was created.
+func (s *CrewAvailabilityService) SavePost(post *models.CrewAvailabilityPost) (bool, error) {
+	post.URLFingerprint = URLFingerprint(post.URL)
+	post.ContentFingerprint = ContentFingerprint("", "", post.Text)
+
+	duplicate, err := s.isDuplicate(post)
+	if err != nil || duplicate {
+		return false, err
+	}
+
+	post.ID = uuid.New().String()
+	post.CreatedAt = time.Now()
+	if post.Certifications == nil {
+		post.Certifications = []string{}
+	}
+	certificationsJSON, err := json.Marshal(post.Certifications)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode certifications: %w", err)
+	}
+
+	var availableFrom, postedAt interface{}
+	if post.AvailableFrom != nil {
+		availableFrom = *post.AvailableFrom
+	}
+	if post.PostedAt != nil {
+		postedAt = *post.PostedAt
+	}
+
+	placeholders := make([]string, 18)
+	for i := range placeholders {
+		placeholders[i] = s.getPlaceholder(i + 1)
+	}
+	query := fmt.Sprintf("INSERT INTO crew_availability_posts (%s) VALUES (%s)",
+		crewAvailabilityColumns, strings.Join(placeholders, ", "))
+
+	_, err = s.db.Exec(query,
+		post.ID, post.Source, nullString(post.URL), nullString(post.URLFingerprint),
+		nullString(post.ContentFingerprint), nullString(post.GroupName), nullString(post.ChannelName),
+		post.Text, nullString(post.Position), availableFrom, nullString(post.Availability),
+		string(certificationsJSON), nullString(post.Location), nullString(post.CountryCode),
+		nullString(post.Region), nullString(post.Language), postedAt, post.CreatedAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to save crew availability post: %w", err)
+	}
+	return true, nil
+}
+
+// ListPosts returns crew availability posts, newest first
+func (s *CrewAvailabilityService) ListPosts(filter models.CrewAvailabilityFilter) (*models.CrewAvailabilityResponse, error) {
+	// Set default pagination
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	whereClause := []string{}
+	args := []interface{}{}
+
+	if filter.Position != "" {
+		args = append(args, strings.ToLower(filter.Position))
+		whereClause = append(whereClause, fmt.Sprintf("LOWER(position) = %s", s.getPlaceholder(len(args))))
+	}
+	if filter.Certification != "" {
+		// Certifications are stored as a JSON array of names
+		args = append(args, "%"+strings.ToLower(`"`+filter.Certification+`"`)+"%")
+		whereClause = append(whereClause, fmt.Sprintf("LOWER(certifications) LIKE %s", s.getPlaceholder(len(args))))
+	}
+	if filter.Region != "" {
+		region := geo.Lookup(filter.Region)
+		if region == nil || region.Country != "" {
+			return nil, fmt.Errorf("%w: unknown region %q", ErrInvalidCrewFilter, filter.Region)
+		}
+		args = append(args, region.Region)
+		whereClause = append(whereClause, fmt.Sprintf("region = %s", s.getPlaceholder(len(args))))
+	}
+	if filter.Country != "" {
+		args = append(args, strings.ToUpper(filter.Country))
+		whereClause = append(whereClause, fmt.Sprintf("country_code = %s", s.getPlaceholder(len(args))))
+	}
+	if filter.Language != "" {
+		if !language.IsLanguage(strings.ToLower(filter.Language)) {
+			return nil, fmt.Errorf("%w: unknown language %q", ErrInvalidCrewFilter, filter.Language)
+		}
+		args = append(args, strings.ToLower(filter.Language))
+		whereClause = append(whereClause, fmt.Sprintf("language = %s", s.getPlaceholder(len(args))))
+	}
+	if filter.AvailableBy != nil {
+		args = append(args, *filter.AvailableBy)
+		whereClause = append(whereClause, fmt.Sprintf("available_from <= %s", s.getPlaceholder(len(args))))
+	}
+
+	where := ""
+	if len(whereClause) > 0 {
+		where = "WHERE " + strings.Join(whereClause, " AND ")
+	}
+
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM crew_availability_posts %s", where)
+	if err := s.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to get crew availability post count: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM crew_availability_posts %s
+		ORDER BY COALESCE(posted_at, created_at) DESC
+		LIMIT %s OFFSET %s
+	`, crewAvailabilityColumns, where, s.getPlaceholder(len(args)+1), s.getPlaceholder(len(args)+2))
+
+	args = append(args, filter.Limit, filter.Offset)
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query crew availability posts: %w", err)
+	}
+	defer rows.Close()
+
+	posts := []models.CrewAvailabilityPost{}
+	for rows.Next() {
+		post, err := scanCrewAvailabilityPost(rows)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, *post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return &models.CrewAvailabilityResponse{
+		Posts: posts,
+		Total: total,
+		Page:  (filter.Offset / filter.Limit) + 1,
+		Limit: filter.Limit,
+	}, nil
+}
+
+// GetPost returns a crew availability post, or sql.ErrNoRows when it does not exist
+func (s *CrewAvailabilityService) GetPost(id string) (*models.CrewAvailabilityPost, error) {
+	query := fmt.Sprintf("SELECT %s FROM crew_availability_posts WHERE id = %s",
+		crewAvailabilityColumns, s.getPlaceholder(1))
+	return scanCrewAvailabilityPost(s.db.QueryRow(query, id))
+}
+
+// isDuplicate reports whether a post with the same URL or text was saved before
+func (s *CrewAvailabilityService) isDuplicate(post *models.CrewAvailabilityPost) (bool, error) {
+	queries := []struct {
+		column string
+		value  string
+	}{
+		{"url_fingerprint", post.URLFingerprint},
+		{"content_fingerprint", post.ContentFingerprint},
+	}
+
+	for _, q := range queries {
+		if q.value == "" {
+			continue
+		}
+
+		var id string
+		query := fmt.Sprintf("SELECT id FROM crew_availability_posts WHERE %s = %s LIMIT 1", q.column, s.getPlaceholder(1))
+		err := s.db.QueryRow(query, q.value).Scan(&id)
+		if err == nil {
+			return true, nil
+		}
+		if err != sql.ErrNoRows {
+			return false, fmt.Errorf("failed to look up duplicate crew availability post: %w", err)
+		}
+	}
+	return false, nil
+}
+
+func scanCrewAvailabilityPost(row rowScanner) (*models.CrewAvailabilityPost, error) {
+	var post models.CrewAvailabilityPost
+	var url, urlFingerprint, contentFingerprint, groupName, channelName sql.NullString
+	var position, availability, certifications, location, countryCode, region, lang sql.NullString
+	var availableFrom, postedAt sql.NullTime
+
+	err := row.Scan(
+		&post.ID, &post.Source, &url, &urlFingerprint, &contentFingerprint, &groupName, &channelName,
+		&post.Text, &position, &availableFrom, &availability, &certifications, &location,
+		&countryCode, &region, &lang, &postedAt, &post.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan crew availability post: %w", err)
+	}
+
+	post.URL = url.String
+	post.URLFingerprint = urlFingerprint.String
+	post.ContentFingerprint = contentFingerprint.String
+	post.GroupName = groupName.String
+	post.ChannelName = channelName.String
+	post.Position = position.String
+	post.Availability = availability.String
+	post.Location = location.String
+	post.CountryCode = countryCode.String
+	post.Region = region.String
+	post.Language = lang.String
+	if availableFrom.Valid {
+		post.AvailableFrom = &availableFrom.Time
+	}
+	if postedAt.Valid {
+		post.PostedAt = &postedAt.Time
+	}
+	post.Certifications = []string{}
+	if certifications.Valid && certifications.String != "" {
+		if err := json.Unmarshal([]byte(certifications.String), &post.Certifications); err != nil {
+			return nil, fmt.Errorf("failed to decode certifications: %w", err)
+		}
+	}
+	return &post, nil
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/database"
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/models"
+)
+
+// seenPostMaxAge is how long a processed post URL is remembered
+const seenPostMaxAge = 90 * 24 * time.Hour
+
+// seenBatchSize caps the URLs checked per query to stay below SQL parameter limits
+const seenBatchSize = 500
+
+type CursorService struct {
+	db     *database.DB
+	driver string
+}
+
+func NewCursorService(db *database.DB) *CursorService {
+	driver := "sqlite3" // default fallback
+	if db != nil {
+		driver = db.GetDriver()
+	}
+	return &CursorService{
+		db:     db,
+		driver: driver,
+	}
+}
+
+// getPlaceholder returns the appropriate placeholder for the database driver
+func (s *CursorService) getPlaceholder(index int) string {
+	if s.driver == "postgres" {
+		return fmt.Sprintf("$%d", index)
+	}
+	return "?"
+}
+
+// ListCursors returns the cursors of a source, or of every source when source is empty
+func (s *CursorService) ListCursors(source string) ([]models.ScrapeCursor, error) {
+	query := "SELECT source, channel, last_post_at, last_message_id, etag, last_modified, byte_offset, updated_at FROM scrape_cursors"
+	var args []interface{}
+	if source != "" {
+		query += " WHERE source = " + s.getPlaceholder(1)
+		args = append(args, source)
+	}
+	query += " ORDER BY source, channel"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scrape cursors: %w", err)
+	}
+	defer rows.Close()
+
+	cursors := []models.ScrapeCursor{}
+	for rows.Next() {
+		var cursor models.ScrapeCursor
+		var lastPostAt sql.NullTime
+		var etag, lastModified sql.NullString
+		err := rows.Scan(&cursor.Source, &cursor.Channel, &lastPostAt, &cursor.LastMessageID,
+			&etag, &lastModified, &cursor.ByteOffset, &cursor.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scrape cursor: %w", err)
+		}
+		if lastPostAt.Valid {
+			cursor.LastPostAt = &lastPostAt.Time
+		}
+		cursor.ETag = etag.String
+		cursor.LastModified = lastModified.String
+		cursors = append(cursors, cursor)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return cursors, nil
+}
+
+// GetCursors returns the cursors of a source keyed by channel
+func (s *CursorService) GetCursors(source string) (map[string]models.ScrapeCursor, error) {
+	cursors, err := s.ListCursors(source)
+	if err != nil {
+		return nil, err
+	}
+
+	byChannel := make(map[string]models.ScrapeCursor, len(cursors))
+	for _, cursor := range cursors {
+		byChannel[cursor.Channel] = cursor
+	}
+	return byChannel, nil
+}
+
+// SaveCursor creates or updates a channel cursor
+func (s *CursorService) SaveCursor(cursor *models.ScrapeCursor) error {
+	cursor.UpdatedAt = time.Now()
+
+	var lastPostAt interface{}
+	if cursor.LastPostAt != nil {
+		lastPostAt = *cursor.LastPostAt
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE scrape_cursors SET last_post_at = %s, last_message_id = %s, etag = %s, last_modified = %s, byte_offset = %s, updated_at = %s
+		WHERE source = %s AND channel = %s
+	`, s.getPlaceholder(1), s.getPlaceholder(2), s.getPlaceholder(3), s.getPlaceholder(4),
+		s.getPlaceholder(5), s.getPlaceholder(6), s.getPlaceholder(7), s.getPlaceholder(8))
+
+	result, err := s.db.Exec(query, lastPostAt, cursor.LastMessageID, nullString(cursor.ETag),
+		nullString(cursor.LastModified), cursor.ByteOffset, cursor.UpdatedAt, cursor.Source, cursor.Channel)
+	if err != nil {
+		return fmt.Errorf("failed to update scrape cursor: %w", err)
+	}
+	if updated, err := result.RowsAffected(); err == nil && updated > 0 {
+		return nil
+	}
+
+	query = fmt.Sprintf(`
+		INSERT INTO scrape_cursors (source, channel, last_post_at, last_message_id, etag, last_modified, byte_offset, updated_at)
+		VALUES (%s, %s, %s, %s, %s, %s, %s, %s)
+	`, s.getPlaceholder(1), s.getPlaceholder(2), s.getPlaceholder(3), s.getPlaceholder(4),
+		s.getPlaceholder(5), s.getPlaceholder(6), s.getPlaceholder(7), s.getPlaceholder(8))
+
+	_, err = s.db.Exec(query, cursor.Source, cursor.Channel, lastPostAt, cursor.LastMessageID,
+		nullString(cursor.ETag), nullString(cursor.LastModified), cursor.ByteOffset, cursor.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create scrape cursor: %w", err)
+	}
+	return nil
+}
+
+// ResetCursors removes the cursor and seen posts of a channel, or of every
+// channel of the source when channel is empty, so the next scrape backfills
+// it. It returns the number of cursors and of seen posts removed.
+func (s *CursorService) ResetCursors(source, channel string) (int64, int64, error) {
+	where := "source = " + s.getPlaceholder(1)
+	args := []interface{}{source}
+	if channel != "" {
+		where += " AND channel = " + s.getPlaceholder(2)
+		args = append(args, channel)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("DELETE FROM scrape_cursors WHERE "+where, args...)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to reset scrape cursors: %w", err)
+	}
+	cursors, err := result.RowsAffected()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count reset scrape cursors: %w", err)
+	}
+
+	result, err = tx.Exec("DELETE FROM seen_posts WHERE "+where, args...)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to reset seen posts: %w", err)
+	}
+	seen, err := result.RowsAffected()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count reset seen posts: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit cursor reset: %w", err)
+	}
+	return cursors, seen, nil
+}
+
+// SeenURLs returns which of the given post URLs were already processed
+func (s *CursorService) SeenURLs(urls []string) (map[string]bool, error) {
+	var fingerprints []string
+	for _, url := range urls {
+		if fingerprint := URLFingerprint(url); fingerprint != "" {
+			fingerprints = append(fingerprints, fingerprint)
+		}
+	}
+
+	seenFingerprints := make(map[string]bool)
+	for start := 0; start < len(fingerprints); start += seenBatchSize {
+		end := start + seenBatchSize
+		if end > len(fingerprints) {
+			end = len(fingerprints)
+		}
+		if err := s.querySeen(fingerprints[start:end], seenFingerprints); err != nil {
+			return nil, err
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, url := range urls {
+		if seenFingerprints[URLFingerprint(url)] {
+			seen[url] = true
+		}
+	}
+	return seen, nil
+}
+
+// querySeen adds the fingerprints of a batch that are in seen_posts to seen
+func (s *CursorService) querySeen(fingerprints []string, seen map[string]bool) error {
+	placeholders := make([]string, len(fingerprints))
+	args := make([]interface{}, len(fingerprints))
+	for i, fingerprint := range fingerprints {
+		placeholders[i] = s.getPlaceholder(i + 1)
+		args[i] = fingerprint
+	}
+
+	query := fmt.Sprintf("SELECT url_fingerprint FROM seen_posts WHERE url_fingerprint IN (%s)",
+		strings.Join(placeholders, ", "))
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query seen posts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var fingerprint string
+		if err := rows.Scan(&fingerprint); err != nil {
+			return fmt.Errorf("failed to scan seen post: %w", err)
+		}
+		seen[fingerprint] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("row iteration error: %w", err)
+	}
+	return nil
+}
+
+// MarkSeen records post URLs of a channel as processed and forgets URLs
+// older than seenPostMaxAge
+func (s *CursorService) MarkSeen(source, channel string, urls []string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var query string
+	if s.driver == "postgres" {
+		query = `
+			INSERT INTO seen_posts (url_fingerprint, source, channel, seen_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (url_fingerprint) DO NOTHING
+		`
+	} else {
+		query = `
+			INSERT OR IGNORE INTO seen_posts (url_fingerprint, source, channel, seen_at)
+			VALUES (?, ?, ?, ?)
+		`
+	}
+
+	now := time.Now()
+	for _, url := range urls {
+		fingerprint := URLFingerprint(url)
+		if fingerprint == "" {
+			continue
+		}
+		if _, err := tx.Exec(query, fingerprint, source, channel, now); err != nil {
+			return fmt.Errorf("failed to mark post seen: %w", err)
+		}
+	}
+
+	prune := fmt.Sprintf("DELETE FROM seen_posts WHERE seen_at < %s", s.getPlaceholder(1))
+	if _, err := tx.Exec(prune, now.Add(-seenPostMaxAge)); err != nil {
+		return fmt.Errorf("failed to prune seen posts: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit seen posts: %w", err)
+	}
+	return nil
+}
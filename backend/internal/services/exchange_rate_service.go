@@ -0,0 +1,138 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/database"
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/models"
+)
+
+// BaseCurrency is the currency exchange rates are quoted against
+const BaseCurrency = "EUR"
+
+// ErrUnknownCurrency is returned for a currency without an exchange rate
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// ErrInvalidExchangeRate is returned when an exchange rate update fails validation
+var ErrInvalidExchangeRate = errors.New("invalid exchange rate")
+
+var currencyCodeRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+
+type ExchangeRateService struct {
+	db     *database.DB
+	driver string
+}
+
+func NewExchangeRateService(db *database.DB) *ExchangeRateService {
+	driver := "sqlite3" // default fallback
+	if db != nil {
+		driver = db.GetDriver()
+	}
+	return &ExchangeRateService{
+		db:     db,
+		driver: driver,
+	}
+}
+
+// getPlaceholder returns the appropriate placeholder for the database driver
+func (s *ExchangeRateService) getPlaceholder(index int) string {
+	if s.driver == "postgres" {
+		return fmt.Sprintf("$%d", index)
+	}
+	return "?"
+}
+
+// ListRates returns every exchange rate sorted by currency
+func (s *ExchangeRateService) ListRates() ([]models.ExchangeRate, error) {
+	rows, err := s.db.Query("SELECT currency, rate, updated_at FROM exchange_rates ORDER BY currency")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query exchange rates: %w", err)
+	}
+	defer rows.Close()
+
+	rates := []models.ExchangeRate{}
+	for rows.Next() {
+		var rate models.ExchangeRate
+		if err := rows.Scan(&rate.Currency, &rate.Rate, &rate.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan exchange rate: %w", err)
+		}
+		rates = append(rates, rate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return rates, nil
+}
+
+// GetRate returns the value of 1 EUR in the given currency
+func (s *ExchangeRateService) GetRate(currency string) (float64, error) {
+	currency = strings.ToUpper(currency)
+
+	var rate float64
+	query := fmt.Sprintf("SELECT rate FROM exchange_rates WHERE currency = %s", s.getPlaceholder(1))
+	err := s.db.QueryRow(query, currency).Scan(&rate)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownCurrency, currency)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+	return rate, nil
+}
+
+// UpdateRates creates or updates the given rates and returns every rate
+func (s *ExchangeRateService) UpdateRates(rates map[string]float64) ([]models.ExchangeRate, error) {
+	currencies := make([]string, 0, len(rates))
+	normalized := make(map[string]float64, len(rates))
+	for currency, rate := range rates {
+		code := strings.ToUpper(strings.TrimSpace(currency))
+		if !currencyCodeRegex.MatchString(code) {
+			return nil, fmt.Errorf("%w: %q is not an ISO 4217 currency code", ErrInvalidExchangeRate, currency)
+		}
+		if rate <= 0 {
+			return nil, fmt.Errorf("%w: rate for %s must be positive", ErrInvalidExchangeRate, code)
+		}
+		if code == BaseCurrency && rate != 1 {
+			return nil, fmt.Errorf("%w: the rate of the base currency %s is always 1", ErrInvalidExchangeRate, BaseCurrency)
+		}
+		currencies = append(currencies, code)
+		normalized[code] = rate
+	}
+	sort.Strings(currencies)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	update := fmt.Sprintf("UPDATE exchange_rates SET rate = %s, updated_at = %s WHERE currency = %s",
+		s.getPlaceholder(1), s.getPlaceholder(2), s.getPlaceholder(3))
+	insert := fmt.Sprintf("INSERT INTO exchange_rates (currency, rate, updated_at) VALUES (%s, %s, %s)",
+		s.getPlaceholder(1), s.getPlaceholder(2), s.getPlaceholder(3))
+
+	for _, currency := range currencies {
+		result, err := tx.Exec(update, normalized[currency], now, currency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update exchange rate: %w", err)
+		}
+		if updated, err := result.RowsAffected(); err == nil && updated > 0 {
+			continue
+		}
+		if _, err := tx.Exec(insert, currency, normalized[currency], now); err != nil {
+			return nil, fmt.Errorf("failed to create exchange rate: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit exchange rates: %w", err)
+	}
+	return s.ListRates()
+}
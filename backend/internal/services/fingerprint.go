@@ -0,0 +1,156 @@
+package services
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// minContentTokens is the minimum number of distinct tokens a job needs before
+// a content fingerprint is computed, so short generic titles never collide
+const minContentTokens = 5
+
+// trackingParams are query parameters that do not change the page a URL points to
+var trackingParams = map[string]bool{
+	"fbclid":   true,
+	"mibextid": true,
+	"ref":      true,
+	"refid":    true,
+	"rdid":     true,
+	"__cft__":  true,
+	"__tn__":   true,
+	"gclid":    true,
+	"igshid":   true,
+	"single":   true,
+	"embed":    true,
+}
+
+// hostAliases maps alternative hostnames to their canonical form
+var hostAliases = map[string]string{
+	"m.facebook.com":      "facebook.com",
+	"mbasic.facebook.com": "facebook.com",
+	"web.facebook.com":    "facebook.com",
+	"fb.com":              "facebook.com",
+	"telegram.me":         "t.me",
+}
+
+var (
+	urlPattern     = regexp.MustCompile(`https?://\S+|www\.\S+`)
+	mentionPattern = regexp.MustCompile(`[#@][\p{L}\p{N}_]+`)
+)
+
+// stopwords are dropped from content fingerprints as they carry no meaning
+var stopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "for": true, "from": true, "has": true, "have": true,
+	"in": true, "is": true, "it": true, "of": true, "on": true, "or": true,
+	"our": true, "the": true, "to": true, "we": true, "with": true, "you": true,
+	"your": true, "will": true, "this": true, "that": true, "please": true,
+}
+
+// NormalizeURL returns a canonical form of a job URL so that the same post
+// shared with different tracking parameters or hostnames compares equal
+func NormalizeURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return strings.ToLower(raw)
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	host = strings.TrimPrefix(host, "www.")
+	if alias, ok := hostAliases[host]; ok {
+		host = alias
+	}
+
+	query := parsed.Query()
+	for key := range query {
+		if trackingParams[strings.ToLower(key)] || strings.HasPrefix(strings.ToLower(key), "utm_") {
+			query.Del(key)
+		}
+	}
+
+	path := strings.TrimRight(parsed.EscapedPath(), "/")
+	if host == "facebook.com" {
+		// Group posts are reachable both as /permalink/<id> and /posts/<id>
+		path = strings.Replace(path, "/permalink/", "/posts/", 1)
+	}
+
+	normalized := "https://" + host + path
+	if encoded := query.Encode(); encoded != "" {
+		normalized += "?" + encoded
+	}
+	return normalized
+}
+
+// URLFingerprint hashes the normalized source URL of a job
+func URLFingerprint(raw string) string {
+	normalized := NormalizeURL(raw)
+	if normalized == "" {
+		return ""
+	}
+	return hashString(normalized)
+}
+
+// RoleURLFingerprint hashes the source URL of one of several jobs split from
+// the same post, so that each role is deduplicated on its own
+func RoleURLFingerprint(raw, title string) string {
+	normalized := NormalizeURL(raw)
+	if normalized == "" {
+		return ""
+	}
+	return hashString(normalized + "#" + strings.ToLower(strings.TrimSpace(title)))
+}
+
+// ContentFingerprint computes a fuzzy hash of a job's title, company and text.
+// The text is reduced to its set of meaningful lowercase tokens, so reposts that
+// only differ in punctuation, emojis, hashtags, links or line ordering match.
+func ContentFingerprint(title, company, text string) string {
+	tokens := contentTokens(text)
+	if len(tokens) < minContentTokens {
+		return ""
+	}
+
+	return hashString(strings.Join([]string{
+		strings.Join(contentTokens(title), " "),
+		strings.Join(contentTokens(company), " "),
+		strings.Join(tokens, " "),
+	}, "|"))
+}
+
+// contentTokens returns the sorted set of meaningful tokens in text
+func contentTokens(text string) []string {
+	text = strings.ToLower(text)
+	text = urlPattern.ReplaceAllString(text, " ")
+	text = mentionPattern.ReplaceAllString(text, " ")
+
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	seen := make(map[string]bool, len(words))
+	tokens := make([]string, 0, len(words))
+	for _, word := range words {
+		if len([]rune(word)) < 2 || stopwords[word] || seen[word] {
+			continue
+		}
+		seen[word] = true
+		tokens = append(tokens, word)
+	}
+
+	sort.Strings(tokens)
+	return tokens
+}
+
+func hashString(value string) string {
+	sum := sha1.Sum([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
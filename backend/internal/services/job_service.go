@@ -2,19 +2,44 @@ package services
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/contract"
 	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/database"
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/geo"
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/language"
 	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/models"
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/salary"
+	"github.com/google/uuid"
 )
 
+// ErrInvalidJobFilter is returned for job filters that cannot be applied
+var ErrInvalidJobFilter = errors.New("invalid job filter")
+
+const (
+	// defaultRadiusKm is the search radius when near is given without radius_km
+	defaultRadiusKm = 100
+	// maxRadiusKm is half the circumference of the Earth
+	maxRadiusKm = 20000
+)
+
+// coordinatesRegex matches a "lat,lon" point
+var coordinatesRegex = regexp.MustCompile(`^\s*(-?\d{1,2}(?:\.\d+)?)\s*,\s*(-?\d{1,3}(?:\.\d+)?)\s*$`)
+
 type JobService struct {
 	db     *database.DB
 	driver string
+	rates  *ExchangeRateService
 }
 
-func NewJobService(db *database.DB) *JobService {
+func NewJobService(db *database.DB, rates *ExchangeRateService) *JobService {
 	// Get driver from the database instance
 	driver := "sqlite3" // default fallback
 	if db != nil {
@@ -23,6 +48,7 @@ func NewJobService(db *database.DB) *JobService {
 	return &JobService{
 		db:     db,
 		driver: driver,
+		rates:  rates,
 	}
 }
 
@@ -34,8 +60,20 @@ func (s *JobService) getPlaceholder(index int) string {
 	return "?"
 }
 
+// salaryBounds are salary filter bounds as monthly amounts in the base currency
+type salaryBounds struct {
+	min *float64
+	max *float64
+}
+
+// searchArea is the circle a geo search is limited to
+type searchArea struct {
+	lat, lon float64
+	radiusKm float64
+}
+
 // buildWhereClause builds the WHERE clause with appropriate placeholders
-func (s *JobService) buildWhereClause(filter models.JobFilter) (string, []interface{}) {
+func (s *JobService) buildWhereClause(filter models.JobFilter, bounds salaryBounds, area *searchArea) (string, []interface{}) {
 	whereClause := []string{}
 	args := []interface{}{}
 	argIndex := 1
@@ -50,13 +88,29 @@ func (s *JobService) buildWhereClause(filter models.JobFilter) (string, []interf
 		argIndex++
 	}
 	if filter.Location != "" {
+		like := "LIKE"
 		if s.driver == "postgres" {
-			whereClause = append(whereClause, fmt.Sprintf("location ILIKE %s", s.getPlaceholder(argIndex)))
+			like = "ILIKE"
+		}
+
+		// Known ports, countries and regions match the normalized columns;
+		// jobs saved before locations were normalized fall back to the text
+		if column, values := locationFilter(filter.Location); column != "" {
+			placeholders := make([]string, len(values))
+			for i, value := range values {
+				placeholders[i] = s.getPlaceholder(argIndex)
+				args = append(args, value)
+				argIndex++
+			}
+			whereClause = append(whereClause, fmt.Sprintf("(%s IN (%s) OR (country_code IS NULL AND region IS NULL AND location %s %s))",
+				column, strings.Join(placeholders, ", "), like, s.getPlaceholder(argIndex)))
+			args = append(args, "%"+filter.Location+"%")
+			argIndex++
 		} else {
-			whereClause = append(whereClause, fmt.Sprintf("location LIKE %s", s.getPlaceholder(argIndex)))
+			whereClause = append(whereClause, fmt.Sprintf("location %s %s", like, s.getPlaceholder(argIndex)))
+			args = append(args, "%"+filter.Location+"%")
+			argIndex++
 		}
-		args = append(args, "%"+filter.Location+"%")
-		argIndex++
 	}
 	if filter.Company != "" {
 		if s.driver == "postgres" {
@@ -67,6 +121,80 @@ func (s *JobService) buildWhereClause(filter models.JobFilter) (string, []interf
 		args = append(args, "%"+filter.Company+"%")
 		argIndex++
 	}
+	// Salary ranges match when they overlap the requested range
+	if bounds.min != nil {
+		whereClause = append(whereClause, fmt.Sprintf("%s >= %s", monthlySalarySQL("salary_max"), s.getPlaceholder(argIndex)))
+		args = append(args, *bounds.min)
+		argIndex++
+	}
+	if bounds.max != nil {
+		whereClause = append(whereClause, fmt.Sprintf("%s <= %s", monthlySalarySQL("salary_min"), s.getPlaceholder(argIndex)))
+		args = append(args, *bounds.max)
+		argIndex++
+	}
+	if filter.ContractKind != "" {
+		whereClause = append(whereClause, fmt.Sprintf("contract_kind = %s", s.getPlaceholder(argIndex)))
+		args = append(args, strings.ToLower(filter.ContractKind))
+		argIndex++
+	}
+	if on, off, ok := contract.ParseRotation(filter.Rotation); ok {
+		whereClause = append(whereClause, fmt.Sprintf("rotation_weeks_on = %s AND rotation_weeks_off = %s",
+			s.getPlaceholder(argIndex), s.getPlaceholder(argIndex+1)))
+		args = append(args, on, off)
+		argIndex += 2
+	}
+	if filter.Season != "" {
+		whereClause = append(whereClause, fmt.Sprintf("season = %s", s.getPlaceholder(argIndex)))
+		args = append(args, strings.ToLower(filter.Season))
+		argIndex++
+	}
+	if filter.MonthsMin != nil {
+		whereClause = append(whereClause, fmt.Sprintf("contract_months >= %s", s.getPlaceholder(argIndex)))
+		args = append(args, *filter.MonthsMin)
+		argIndex++
+	}
+	if filter.MonthsMax != nil {
+		whereClause = append(whereClause, fmt.Sprintf("contract_months <= %s", s.getPlaceholder(argIndex)))
+		args = append(args, *filter.MonthsMax)
+		argIndex++
+	}
+	if filter.Language != "" {
+		whereClause = append(whereClause, fmt.Sprintf("language = %s", s.getPlaceholder(argIndex)))
+		args = append(args, strings.ToLower(filter.Language))
+		argIndex++
+	}
+	if filter.Region != "" {
+		if region := geo.Lookup(filter.Region); region != nil {
+			whereClause = append(whereClause, fmt.Sprintf("region = %s", s.getPlaceholder(argIndex)))
+			args = append(args, region.Region)
+			argIndex++
+		}
+	}
+	// The bounding box narrows the search to jobs near the point, the exact
+	// distance is checked once they are loaded
+	if area != nil {
+		box := geo.BoundingBox(area.lat, area.lon, area.radiusKm)
+		whereClause = append(whereClause, fmt.Sprintf("latitude BETWEEN %s AND %s",
+			s.getPlaceholder(argIndex), s.getPlaceholder(argIndex+1)))
+		args = append(args, box.MinLat, box.MaxLat)
+		argIndex += 2
+
+		switch {
+		case box.AllLon:
+			whereClause = append(whereClause, "longitude IS NOT NULL")
+		case box.MinLon <= box.MaxLon:
+			whereClause = append(whereClause, fmt.Sprintf("longitude BETWEEN %s AND %s",
+				s.getPlaceholder(argIndex), s.getPlaceholder(argIndex+1)))
+			args = append(args, box.MinLon, box.MaxLon)
+			argIndex += 2
+		default:
+			// The box crosses the antimeridian
+			whereClause = append(whereClause, fmt.Sprintf("(longitude >= %s OR longitude <= %s)",
+				s.getPlaceholder(argIndex), s.getPlaceholder(argIndex+1)))
+			args = append(args, box.MinLon, box.MaxLon)
+			argIndex += 2
+		}
+	}
 
 	where := ""
 	if len(whereClause) > 0 {
@@ -85,13 +213,31 @@ func (s *JobService) GetJobs(filter models.JobFilter) (*models.JobResponse, erro
 		filter.Offset = 0
 	}
 
+	bounds, err := s.salaryBounds(filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateContractFilter(filter); err != nil {
+		return nil, err
+	}
+	if filter.Language != "" && !language.IsLanguage(strings.ToLower(filter.Language)) {
+		return nil, fmt.Errorf("%w: unknown language %q", ErrInvalidJobFilter, filter.Language)
+	}
+	area, err := geoSearch(filter)
+	if err != nil {
+		return nil, err
+	}
+
 	// Build WHERE clause
-	where, args := s.buildWhereClause(filter)
+	where, args := s.buildWhereClause(filter, bounds, area)
+	if area != nil {
+		return s.getJobsNear(filter, where, args, area)
+	}
 	
 	// Get total count
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM jobs %s", where)
 	var total int
-	err := s.db.QueryRow(countQuery, args...).Scan(&total)
+	err = s.db.QueryRow(countQuery, args...).Scan(&total)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get job count: %w", err)
 	}
@@ -101,13 +247,11 @@ func (s *JobService) GetJobs(filter models.JobFilter) (*models.JobResponse, erro
 	offsetPlaceholder := s.getPlaceholder(len(args) + 2)
 	
 	query := fmt.Sprintf(`
-		SELECT id, title, company, location, type, vessel, duration, salary, 
-		       description, requirements, source_url, source, posted_at, 
-		       scraped_at, created_at, updated_at
+		SELECT %s
 		FROM jobs %s
 		ORDER BY posted_at DESC, created_at DESC
 		LIMIT %s OFFSET %s
-	`, where, limitPlaceholder, offsetPlaceholder)
+	`, jobColumns, where, limitPlaceholder, offsetPlaceholder)
 
 	args = append(args, filter.Limit, filter.Offset)
 	rows, err := s.db.Query(query, args...)
@@ -118,115 +262,294 @@ func (s *JobService) GetJobs(filter models.JobFilter) (*models.JobResponse, erro
 
 	var jobs []models.Job
 	for rows.Next() {
-		var job models.Job
-		var title, company, location, jobType, vessel, duration, salary, description, requirements, sourceURL, source sql.NullString
-		var postedAt, scrapedAt, createdAt, updatedAt sql.NullTime
-		
-		err := rows.Scan(
-			&job.ID, &title, &company, &location, &jobType,
-			&vessel, &duration, &salary, &description,
-			&requirements, &sourceURL, &source, &postedAt,
-			&scrapedAt, &createdAt, &updatedAt,
-		)
+		job, err := scanJob(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan job: %w", err)
-		}
-		
-		// Handle NULL values for all string fields
-		if title.Valid {
-			job.Title = title.String
+			return nil, err
 		}
-		if company.Valid {
-			job.Company = company.String
+		jobs = append(jobs, *job)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	page := (filter.Offset / filter.Limit) + 1
+	return &models.JobResponse{
+		Jobs:  jobs,
+		Total: total,
+		Page:  page,
+		Limit: filter.Limit,
+	}, nil
+}
+
+// salaryBounds converts the salary filter into monthly amounts in the base currency
+func (s *JobService) salaryBounds(filter models.JobFilter) (salaryBounds, error) {
+	var bounds salaryBounds
+	if filter.SalaryMin == nil && filter.SalaryMax == nil {
+		return bounds, nil
+	}
+
+	currency := strings.ToUpper(filter.Currency)
+	if currency == "" {
+		currency = BaseCurrency
+	}
+	period := strings.ToLower(filter.Period)
+	if period == "" {
+		period = salary.PeriodMonth
+	}
+	if !salary.IsPeriod(period) {
+		return bounds, fmt.Errorf("%w: unknown period %q", ErrInvalidJobFilter, filter.Period)
+	}
+
+	rate, err := s.rates.GetRate(currency)
+	if err != nil {
+		if errors.Is(err, ErrUnknownCurrency) {
+			return bounds, fmt.Errorf("%w: %v", ErrInvalidJobFilter, err)
 		}
-		if location.Valid {
-			job.Location = location.String
+		return bounds, err
+	}
+
+	toMonthlyBase := func(amount float64) *float64 {
+		monthly := amount * salary.MonthlyFactor(period) / rate
+		return &monthly
+	}
+	if filter.SalaryMin != nil {
+		bounds.min = toMonthlyBase(*filter.SalaryMin)
+	}
+	if filter.SalaryMax != nil {
+		bounds.max = toMonthlyBase(*filter.SalaryMax)
+	}
+	return bounds, nil
+}
+
+// getJobsNear returns the jobs within the search area, nearest first. Every
+// job inside the bounding box is ranked by its coordinates alone, so the total
+// is exact, and only the requested page is loaded in full.
+func (s *JobService) getJobsNear(filter models.JobFilter, where string, args []interface{}, area *searchArea) (*models.JobResponse, error) {
+	query := fmt.Sprintf(`
+		SELECT id, latitude, longitude
+		FROM jobs %s
+		ORDER BY posted_at DESC, created_at DESC
+	`, where)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query jobs: %w", err)
+	}
+	defer rows.Close()
+
+	type candidate struct {
+		id       string
+		distance float64
+	}
+	var candidates []candidate
+	for rows.Next() {
+		var id string
+		var lat, lon sql.NullFloat64
+		if err := rows.Scan(&id, &lat, &lon); err != nil {
+			return nil, fmt.Errorf("failed to scan job coordinates: %w", err)
 		}
-		if jobType.Valid {
-			job.Type = jobType.String
+		if !lat.Valid || !lon.Valid {
+			continue
 		}
-		if vessel.Valid {
-			job.Vessel = vessel.String
+
+		distance := geo.DistanceKm(area.lat, area.lon, lat.Float64, lon.Float64)
+		if distance > area.radiusKm {
+			continue
 		}
-		if duration.Valid {
-			job.Duration = duration.String
+		candidates = append(candidates, candidate{id: id, distance: math.Round(distance*10) / 10})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	// Equally distant jobs stay newest first
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	total := len(candidates)
+	start := filter.Offset
+	if start > total {
+		start = total
+	}
+	end := start + filter.Limit
+	if end > total {
+		end = total
+	}
+	page := candidates[start:end]
+
+	jobs := make([]models.Job, 0, len(page))
+	if len(page) > 0 {
+		placeholders := make([]string, len(page))
+		ids := make([]interface{}, len(page))
+		for i, c := range page {
+			placeholders[i] = s.getPlaceholder(i + 1)
+			ids[i] = c.id
 		}
-		if salary.Valid {
-			job.Salary = salary.String
+
+		query = fmt.Sprintf("SELECT %s FROM jobs WHERE id IN (%s)", jobColumns, strings.Join(placeholders, ", "))
+		pageRows, err := s.db.Query(query, ids...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query jobs: %w", err)
 		}
-		if description.Valid {
-			job.Description = description.String
+		defer pageRows.Close()
+
+		byID := make(map[string]*models.Job, len(page))
+		for pageRows.Next() {
+			job, err := scanJob(pageRows)
+			if err != nil {
+				return nil, err
+			}
+			byID[job.ID] = job
 		}
-		if requirements.Valid {
-			job.Requirements = requirements.String
+		if err = pageRows.Err(); err != nil {
+			return nil, fmt.Errorf("row iteration error: %w", err)
 		}
-		if sourceURL.Valid {
-			job.SourceURL = sourceURL.String
+
+		for _, c := range page {
+			// A job deleted since it was ranked is left out of the page
+			job, ok := byID[c.id]
+			if !ok {
+				continue
+			}
+			distance := c.distance
+			job.DistanceKm = &distance
+			jobs = append(jobs, *job)
 		}
-		if source.Valid {
-			job.Source = source.String
+	}
+
+	return &models.JobResponse{
+		Jobs:  jobs,
+		Total: total,
+		Page:  (filter.Offset / filter.Limit) + 1,
+		Limit: filter.Limit,
+	}, nil
+}
+
+// geoSearch validates the region filter and returns the area to search
+// around near, or nil when near is not set
+func geoSearch(filter models.JobFilter) (*searchArea, error) {
+	if filter.Region != "" {
+		region := geo.Lookup(filter.Region)
+		if region == nil || region.Country != "" {
+			return nil, fmt.Errorf("%w: unknown region %q", ErrInvalidJobFilter, filter.Region)
 		}
-		
-		// Handle NULL values for time fields
-		if postedAt.Valid {
-			job.PostedAt = postedAt.Time
+	}
+
+	if filter.Near == "" {
+		if filter.RadiusKm != nil {
+			return nil, fmt.Errorf("%w: radius_km requires near", ErrInvalidJobFilter)
 		}
-		if scrapedAt.Valid {
-			job.ScrapedAt = scrapedAt.Time
+		return nil, nil
+	}
+
+	area := &searchArea{radiusKm: defaultRadiusKm}
+	if filter.RadiusKm != nil {
+		if *filter.RadiusKm <= 0 || *filter.RadiusKm > maxRadiusKm {
+			return nil, fmt.Errorf("%w: radius_km must be above 0 and at most %d", ErrInvalidJobFilter, maxRadiusKm)
 		}
-		if createdAt.Valid {
-			job.CreatedAt = createdAt.Time
+		area.radiusKm = *filter.RadiusKm
+	}
+
+	if match := coordinatesRegex.FindStringSubmatch(filter.Near); match != nil {
+		area.lat, _ = strconv.ParseFloat(match[1], 64)
+		area.lon, _ = strconv.ParseFloat(match[2], 64)
+		if area.lat < -90 || area.lat > 90 || area.lon < -180 || area.lon > 180 {
+			return nil, fmt.Errorf("%w: near coordinates out of range", ErrInvalidJobFilter)
 		}
-		if updatedAt.Valid {
-			job.UpdatedAt = updatedAt.Time
+		return area, nil
+	}
+
+	location := geo.Lookup(filter.Near)
+	if location == nil || location.Lat == nil || location.Lon == nil {
+		return nil, fmt.Errorf("%w: near must be a known port or lat,lon", ErrInvalidJobFilter)
+	}
+	area.lat, area.lon = *location.Lat, *location.Lon
+	return area, nil
+}
+
+// locationFilter returns the normalized column and values matching a location
+// name, or an empty column when the gazetteer does not know it. Areas such as
+// "Florida" match the ports within them.
+func locationFilter(name string) (string, []string) {
+	location := geo.Lookup(name)
+	switch {
+	case location == nil:
+		return "", nil
+	case location.Port != "":
+		return "port", geo.PortsWithin(location.Port)
+	case location.Country != "":
+		return "country_code", []string{location.Country}
+	}
+	return "region", []string{location.Region}
+}
+
+// validateContractFilter rejects unknown contract kinds, seasons and rotation patterns
+func validateContractFilter(filter models.JobFilter) error {
+	if filter.ContractKind != "" && !contract.IsKind(strings.ToLower(filter.ContractKind)) {
+		return fmt.Errorf("%w: unknown contract %q", ErrInvalidJobFilter, filter.ContractKind)
+	}
+	if filter.Season != "" && !contract.IsSeason(strings.ToLower(filter.Season)) {
+		return fmt.Errorf("%w: unknown season %q", ErrInvalidJobFilter, filter.Season)
+	}
+	if filter.Rotation != "" {
+		if _, _, ok := contract.ParseRotation(filter.Rotation); !ok {
+			return fmt.Errorf("%w: rotation must look like 10/10", ErrInvalidJobFilter)
 		}
-		
-		jobs = append(jobs, job)
 	}
+	return nil
+}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("row iteration error: %w", err)
+// monthlySalarySQL converts a salary amount column into a monthly amount in
+// the base currency. Jobs without a currency are assumed to pay in the base
+// currency and jobs in a currency without an exchange rate never match.
+func monthlySalarySQL(column string) string {
+	cases := make([]string, 0, len(salary.Periods))
+	for _, period := range salary.Periods {
+		cases = append(cases, fmt.Sprintf("WHEN '%s' THEN %g", period, salary.MonthlyFactor(period)))
 	}
 
-	page := (filter.Offset / filter.Limit) + 1
-	return &models.JobResponse{
-		Jobs:  jobs,
-		Total: total,
-		Page:  page,
-		Limit: filter.Limit,
-	}, nil
+	return fmt.Sprintf(
+		"(%s * CASE salary_period %s ELSE 1 END / (SELECT rate FROM exchange_rates WHERE exchange_rates.currency = COALESCE(jobs.salary_currency, '%s')))",
+		column, strings.Join(cases, " "), BaseCurrency)
 }
 
 func (s *JobService) CreateJob(job *models.Job) error {
-	var query string
-	var args []interface{}
-	
-	if s.driver == "postgres" {
-		query = `
-			INSERT INTO jobs (
-				id, title, company, location, type, vessel, duration, salary,
-				description, requirements, source_url, source, posted_at,
-				scraped_at, created_at, updated_at
-			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
-		`
-	} else {
-		query = `
-			INSERT INTO jobs (
-				id, title, company, location, type, vessel, duration, salary,
-				description, requirements, source_url, source, posted_at,
-				scraped_at, created_at, updated_at
-			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		`
-	}
-	
-	args = []interface{}{
+	setSalary(job)
+	setContract(job)
+	setLocation(job)
+	setLanguage(job)
+	s.setFingerprints(job)
+	return s.insertJob(s.db, job)
+}
+
+// insertJob inserts a job using the given executor (database or transaction)
+func (s *JobService) insertJob(exec executor, job *models.Job) error {
+	args := []interface{}{
 		job.ID, job.Title, job.Company, job.Location, job.Type,
 		job.Vessel, job.Duration, job.Salary, job.Description,
 		job.Requirements, job.SourceURL, job.Source, job.PostedAt,
 		job.ScrapedAt, job.CreatedAt, job.UpdatedAt,
+		job.SalaryMin, job.SalaryMax, nullString(job.Currency), nullString(job.Period), job.Negotiable,
+		nullString(job.ContractKind), nullInt(job.RotationOn), nullInt(job.RotationOff),
+		nullFloat(job.ContractMonths), nullString(job.Season),
+		nullString(job.Port), nullString(job.CountryCode), nullString(job.Region), job.Latitude, job.Longitude,
+		job.ValidThrough, nullString(job.Language),
+		nullString(job.URLFingerprint), nullString(job.ContentFingerprint),
 	}
-	
-	_, err := s.db.Exec(query, args...)
+
+	placeholders := make([]string, len(args))
+	for i := range args {
+		placeholders[i] = s.getPlaceholder(i + 1)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO jobs (%s, url_fingerprint, content_fingerprint)
+		VALUES (%s)
+	`, jobColumns, strings.Join(placeholders, ", "))
+
+	_, err := exec.Exec(query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to create job: %w", err)
 	}
@@ -234,74 +557,348 @@ func (s *JobService) CreateJob(job *models.Job) error {
 }
 
 func (s *JobService) GetJobByID(jobID string) (*models.Job, error) {
-	job := &models.Job{}
-	var query string
-	
-	if s.driver == "postgres" {
-		query = `
-			SELECT id, title, company, location, type, vessel, duration, salary,
-			       description, requirements, source_url, source, posted_at,
-			       scraped_at, created_at, updated_at
-			FROM jobs WHERE id = $1
-		`
-	} else {
-		query = `
-			SELECT id, title, company, location, type, vessel, duration, salary,
-			       description, requirements, source_url, source, posted_at,
-			       scraped_at, created_at, updated_at
-			FROM jobs WHERE id = ?
-		`
+	query := fmt.Sprintf("SELECT %s FROM jobs WHERE id = %s", jobColumns, s.getPlaceholder(1))
+
+	job, err := scanJob(s.db.QueryRow(query, jobID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
 	}
-	
-	var vessel, duration, salary, description, requirements, sourceURL, source sql.NullString
+
+	sources, err := s.getJobSources(job.ID)
+	if err != nil {
+		return nil, err
+	}
+	job.Sources = sources
+
+	return job, nil
+}
+
+// jobColumns are the columns read by scanJob, in order
+const jobColumns = `id, title, company, location, type, vessel, duration, salary,
+	description, requirements, source_url, source, posted_at,
+	scraped_at, created_at, updated_at,
+	salary_min, salary_max, salary_currency, salary_period, salary_negotiable,
+	contract_kind, rotation_weeks_on, rotation_weeks_off, contract_months, season,
+	port, country_code, region, latitude, longitude, valid_through, language`
+
+// scanJob scans a row of jobColumns, treating NULL columns as empty values
+func scanJob(row rowScanner) (*models.Job, error) {
+	job := &models.Job{}
+	var title, company, location, jobType, vessel, duration, salary, description, requirements, sourceURL, source sql.NullString
 	var postedAt, scrapedAt, createdAt, updatedAt sql.NullTime
-	
-	err := s.db.QueryRow(query, jobID).Scan(
-		&job.ID, &job.Title, &job.Company, &job.Location, &job.Type,
+	var salaryMin, salaryMax sql.NullFloat64
+	var currency, period sql.NullString
+	var negotiable sql.NullBool
+	var contractKind, season sql.NullString
+	var rotationOn, rotationOff sql.NullInt64
+	var contractMonths sql.NullFloat64
+	var port, countryCode, region sql.NullString
+	var latitude, longitude sql.NullFloat64
+	var validThrough sql.NullTime
+	var lang sql.NullString
+
+	err := row.Scan(
+		&job.ID, &title, &company, &location, &jobType,
 		&vessel, &duration, &salary, &description,
 		&requirements, &sourceURL, &source, &postedAt,
 		&scrapedAt, &createdAt, &updatedAt,
+		&salaryMin, &salaryMax, &currency, &period, &negotiable,
+		&contractKind, &rotationOn, &rotationOff, &contractMonths, &season,
+		&port, &countryCode, &region, &latitude, &longitude, &validThrough, &lang,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get job: %w", err)
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan job: %w", err)
 	}
-	
-	// Handle NULL values
-	if vessel.Valid {
-		job.Vessel = vessel.String
+
+	job.Title = title.String
+	job.Company = company.String
+	job.Location = location.String
+	job.Type = jobType.String
+	job.Vessel = vessel.String
+	job.Duration = duration.String
+	job.Salary = salary.String
+	job.Description = description.String
+	job.Requirements = requirements.String
+	job.SourceURL = sourceURL.String
+	job.Source = source.String
+
+	job.PostedAt = postedAt.Time
+	job.ScrapedAt = scrapedAt.Time
+	job.CreatedAt = createdAt.Time
+	job.UpdatedAt = updatedAt.Time
+
+	if salaryMin.Valid {
+		job.SalaryMin = &salaryMin.Float64
 	}
-	if duration.Valid {
-		job.Duration = duration.String
+	if salaryMax.Valid {
+		job.SalaryMax = &salaryMax.Float64
 	}
-	if salary.Valid {
-		job.Salary = salary.String
+	job.Currency = currency.String
+	job.Period = period.String
+	job.Negotiable = negotiable.Bool
+
+	job.ContractKind = contractKind.String
+	job.RotationOn = int(rotationOn.Int64)
+	job.RotationOff = int(rotationOff.Int64)
+	job.ContractMonths = contractMonths.Float64
+	job.Season = season.String
+
+	job.Port = port.String
+	job.CountryCode = countryCode.String
+	job.Region = region.String
+	if latitude.Valid && longitude.Valid {
+		job.Latitude = &latitude.Float64
+		job.Longitude = &longitude.Float64
 	}
-	if description.Valid {
-		job.Description = description.String
+	if validThrough.Valid {
+		job.ValidThrough = &validThrough.Time
 	}
-	if requirements.Valid {
-		job.Requirements = requirements.String
+	job.Language = lang.String
+
+	return job, nil
+}
+
+// executor is implemented by both *database.DB and *sql.Tx
+type executor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// UpsertJob saves a scraped job unless it has been seen before. A re-sighted
+// job has its scraped_at/updated_at refreshed and the new source linked to the
+// existing canonical job, whose ID is written back to job. It reports whether
+// a new job was created.
+func (s *JobService) UpsertJob(job *models.Job) (bool, error) {
+	setSalary(job)
+	setContract(job)
+	setLocation(job)
+	setLanguage(job)
+	s.setFingerprints(job)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	if sourceURL.Valid {
-		job.SourceURL = sourceURL.String
+	defer tx.Rollback()
+
+	existingID, err := s.findDuplicate(tx, job)
+	if err != nil {
+		return false, err
 	}
-	if source.Valid {
-		job.Source = source.String
+
+	now := time.Now()
+	created := existingID == ""
+	if created {
+		if err := s.insertJob(tx, job); err != nil {
+			return false, err
+		}
+	} else {
+		query := fmt.Sprintf("UPDATE jobs SET scraped_at = %s, updated_at = %s WHERE id = %s",
+			s.getPlaceholder(1), s.getPlaceholder(2), s.getPlaceholder(3))
+		if _, err := tx.Exec(query, now, now, existingID); err != nil {
+			return false, fmt.Errorf("failed to refresh job: %w", err)
+		}
+		job.ID = existingID
 	}
-	
-	// Handle NULL values for time fields
-	if postedAt.Valid {
-		job.PostedAt = postedAt.Time
+
+	if err := s.linkSource(tx, job, now); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit job: %w", err)
+	}
+	return created, nil
+}
+
+// findDuplicate returns the ID of an existing job matching the job's URL or
+// content fingerprint, or an empty string when the job is new
+func (s *JobService) findDuplicate(exec executor, job *models.Job) (string, error) {
+	queries := []struct {
+		query string
+		value string
+	}{
+		{"SELECT id FROM jobs WHERE url_fingerprint = %s LIMIT 1", job.URLFingerprint},
+		{"SELECT job_id FROM job_sources WHERE url_fingerprint = %s LIMIT 1", job.URLFingerprint},
+		{"SELECT id FROM jobs WHERE content_fingerprint = %s ORDER BY created_at LIMIT 1", job.ContentFingerprint},
 	}
-	if scrapedAt.Valid {
-		job.ScrapedAt = scrapedAt.Time
+
+	for _, q := range queries {
+		if q.value == "" {
+			continue
+		}
+
+		var id string
+		err := exec.QueryRow(fmt.Sprintf(q.query, s.getPlaceholder(1)), q.value).Scan(&id)
+		if err == nil {
+			return id, nil
+		}
+		if err != sql.ErrNoRows {
+			return "", fmt.Errorf("failed to look up duplicate job: %w", err)
+		}
 	}
-	if createdAt.Valid {
-		job.CreatedAt = createdAt.Time
+	return "", nil
+}
+
+// linkSource records that job.ID was seen at job.Source/job.SourceURL
+func (s *JobService) linkSource(exec executor, job *models.Job, seenAt time.Time) error {
+	var existingID string
+	var err error
+	if job.URLFingerprint != "" {
+		query := fmt.Sprintf("SELECT id FROM job_sources WHERE job_id = %s AND url_fingerprint = %s",
+			s.getPlaceholder(1), s.getPlaceholder(2))
+		err = exec.QueryRow(query, job.ID, job.URLFingerprint).Scan(&existingID)
+	} else {
+		query := fmt.Sprintf("SELECT id FROM job_sources WHERE job_id = %s AND source = %s AND url_fingerprint IS NULL",
+			s.getPlaceholder(1), s.getPlaceholder(2))
+		err = exec.QueryRow(query, job.ID, job.Source).Scan(&existingID)
 	}
-	if updatedAt.Valid {
-		job.UpdatedAt = updatedAt.Time
+
+	switch {
+	case err == nil:
+		query := fmt.Sprintf("UPDATE job_sources SET last_seen_at = %s WHERE id = %s",
+			s.getPlaceholder(1), s.getPlaceholder(2))
+		if _, err := exec.Exec(query, seenAt, existingID); err != nil {
+			return fmt.Errorf("failed to update job source: %w", err)
+		}
+	case err == sql.ErrNoRows:
+		query := fmt.Sprintf(`
+			INSERT INTO job_sources (id, job_id, source, source_url, url_fingerprint, first_seen_at, last_seen_at)
+			VALUES (%s, %s, %s, %s, %s, %s, %s)
+		`, s.getPlaceholder(1), s.getPlaceholder(2), s.getPlaceholder(3), s.getPlaceholder(4),
+			s.getPlaceholder(5), s.getPlaceholder(6), s.getPlaceholder(7))
+		_, err := exec.Exec(query, uuid.New().String(), job.ID, job.Source, job.SourceURL,
+			nullString(job.URLFingerprint), seenAt, seenAt)
+		if err != nil {
+			return fmt.Errorf("failed to link job source: %w", err)
+		}
+	default:
+		return fmt.Errorf("failed to look up job source: %w", err)
 	}
-	
-	return job, nil
-} 
\ No newline at end of file
+	return nil
+}
+
+// getJobSources returns every place a job has been seen, oldest first
+func (s *JobService) getJobSources(jobID string) ([]models.JobSource, error) {
+	query := fmt.Sprintf(`
+		SELECT id, job_id, source, source_url, first_seen_at, last_seen_at
+		FROM job_sources WHERE job_id = %s
+		ORDER BY first_seen_at
+	`, s.getPlaceholder(1))
+
+	rows, err := s.db.Query(query, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query job sources: %w", err)
+	}
+	defer rows.Close()
+
+	var sources []models.JobSource
+	for rows.Next() {
+		var source models.JobSource
+		var name, sourceURL sql.NullString
+		if err := rows.Scan(&source.ID, &source.JobID, &name, &sourceURL, &source.FirstSeenAt, &source.LastSeenAt); err != nil {
+			return nil, fmt.Errorf("failed to scan job source: %w", err)
+		}
+		source.Source = name.String
+		source.SourceURL = sourceURL.String
+		sources = append(sources, source)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return sources, nil
+}
+
+// setFingerprints fills in any missing deduplication fingerprints
+func (s *JobService) setFingerprints(job *models.Job) {
+	if job.URLFingerprint == "" {
+		job.URLFingerprint = URLFingerprint(job.SourceURL)
+	}
+	if job.ContentFingerprint == "" {
+		job.ContentFingerprint = ContentFingerprint(job.Title, job.Company, job.Description)
+	}
+}
+
+// setSalary parses the free-text salary of a job that has no structured salary yet
+func setSalary(job *models.Job) {
+	if job.Salary == "" || job.SalaryMax != nil || job.Negotiable {
+		return
+	}
+	if parsed := salary.Parse(job.Salary); parsed != nil {
+		ApplySalary(job, parsed)
+	}
+}
+
+// ApplySalary copies a parsed salary onto a job
+func ApplySalary(job *models.Job, parsed *salary.Salary) {
+	if parsed.HasAmount() {
+		job.SalaryMin = &parsed.Min
+		job.SalaryMax = &parsed.Max
+	}
+	job.Currency = parsed.Currency
+	job.Period = parsed.Period
+	job.Negotiable = parsed.Negotiable
+}
+
+// setContract parses the free-text duration of a job that has no structured contract yet
+func setContract(job *models.Job) {
+	if job.Duration == "" || job.ContractKind != "" || job.ContractMonths > 0 {
+		return
+	}
+	if parsed := contract.Parse(job.Duration); parsed != nil {
+		ApplyContract(job, parsed)
+	}
+}
+
+// ApplyContract copies parsed contract terms onto a job
+func ApplyContract(job *models.Job, parsed *contract.Contract) {
+	job.ContractKind = parsed.Kind
+	job.RotationOn = parsed.RotationOn
+	job.RotationOff = parsed.RotationOff
+	job.ContractMonths = parsed.Months
+	job.Season = parsed.Season
+}
+
+// setLanguage detects the language of a job that has none yet
+func setLanguage(job *models.Job) {
+	if job.Language == "" {
+		job.Language = language.Detect(job.Title + "\n" + job.Description)
+	}
+}
+
+// setLocation normalizes the free-text location of a job that has no normalized location yet
+func setLocation(job *models.Job) {
+	if job.Location == "" || job.CountryCode != "" || job.Region != "" {
+		return
+	}
+	if location := geo.Resolve(job.Location); location != nil {
+		ApplyLocation(job, location)
+	}
+}
+
+// ApplyLocation copies a normalized location onto a job
+func ApplyLocation(job *models.Job, location *geo.Location) {
+	job.Port = location.Port
+	job.CountryCode = location.Country
+	job.Region = location.Region
+	job.Latitude = location.Lat
+	job.Longitude = location.Lon
+}
+
+// nullString stores empty strings as NULL
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// nullInt stores zero as NULL
+func nullInt(value int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(value), Valid: value != 0}
+}
+
+// nullFloat stores zero as NULL
+func nullFloat(value float64) sql.NullFloat64 {
+	return sql.NullFloat64{Float64: value, Valid: value != 0}
+}
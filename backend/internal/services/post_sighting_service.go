@@ -0,0 +1,71 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/database"
+)
+
+// PostSightingService remembers the groups each post text was seen in, so
+// that posts blasted across groups are recognised across restarts
+type PostSightingService struct {
+	db     *database.DB
+	driver string
+}
+
+func NewPostSightingService(db *database.DB) *PostSightingService {
+	driver := "sqlite3" // default fallback
+	if db != nil {
+		driver = db.GetDriver()
+	}
+	return &PostSightingService{
+		db:     db,
+		driver: driver,
+	}
+}
+
+// getPlaceholder returns the appropriate placeholder for the database driver
+func (s *PostSightingService) getPlaceholder(index int) string {
+	if s.driver == "postgres" {
+		return fmt.Sprintf("$%d", index)
+	}
+	return "?"
+}
+
+// RecordSighting records that a post text was seen in a group and returns the
+// number of groups it was seen in since the given time
+func (s *PostSightingService) RecordSighting(fingerprint, group string, since time.Time) (int, error) {
+	now := time.Now()
+
+	query := fmt.Sprintf("UPDATE post_sightings SET seen_at = %s WHERE fingerprint = %s AND group_key = %s",
+		s.getPlaceholder(1), s.getPlaceholder(2), s.getPlaceholder(3))
+	result, err := s.db.Exec(query, now, fingerprint, group)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update post sighting: %w", err)
+	}
+	if updated, err := result.RowsAffected(); err != nil || updated == 0 {
+		query = fmt.Sprintf("INSERT INTO post_sightings (fingerprint, group_key, seen_at) VALUES (%s, %s, %s)",
+			s.getPlaceholder(1), s.getPlaceholder(2), s.getPlaceholder(3))
+		if _, err := s.db.Exec(query, fingerprint, group, now); err != nil {
+			return 0, fmt.Errorf("failed to create post sighting: %w", err)
+		}
+	}
+
+	var groups int
+	query = fmt.Sprintf("SELECT COUNT(*) FROM post_sightings WHERE fingerprint = %s AND seen_at > %s",
+		s.getPlaceholder(1), s.getPlaceholder(2))
+	if err := s.db.QueryRow(query, fingerprint, since).Scan(&groups); err != nil {
+		return 0, fmt.Errorf("failed to count post sightings: %w", err)
+	}
+	return groups, nil
+}
+
+// PruneSightings removes the sightings older than the given time
+func (s *PostSightingService) PruneSightings(before time.Time) error {
+	query := "DELETE FROM post_sightings WHERE seen_at < " + s.getPlaceholder(1)
+	if _, err := s.db.Exec(query, before); err != nil {
+		return fmt.Errorf("failed to prune post sightings: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/database"
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// ErrPostReviewed is returned when reviewing a quarantined post that was
+// already approved or rejected
+var ErrPostReviewed = errors.New("quarantined post was already reviewed")
+
+// ErrInvalidQuarantineFilter is returned when listing quarantined posts with
+// an unknown status
+var ErrInvalidQuarantineFilter = errors.New("invalid quarantine filter")
+
+const quarantinedPostColumns = `id, source, fingerprint, url, group_name, channel_name, post_source, text,
+	posted_at, score, reasons, status, reviewed_by, reviewed_at, created_at`
+
+type QuarantineService struct {
+	db     *database.DB
+	driver string
+}
+
+func NewQuarantineService(db *database.DB) *QuarantineService {
+	driver := "sqlite3" // default fallback
+	if db != nil {
+		driver = db.GetDriver()
+	}
+	return &QuarantineService{
+		db:     db,
+		driver: driver,
+	}
+}
+
+// getPlaceholder returns the appropriate placeholder for the database driver
+func (s *QuarantineService) getPlaceholder(index int) string {
+	if s.driver == "postgres" {
+		return fmt.Sprintf("$%d", index)
+	}
+	return "?"
+}
+
+// QuarantineFingerprint identifies a post by its URL, or by its text when it
+// has none, so a post scraped again is not quarantined twice
+func QuarantineFingerprint(url, text string) string {
+	if fingerprint := URLFingerprint(url); fingerprint != "" {
+		return fingerprint
+	}
+	if fingerprint := ContentFingerprint("", "", text); fingerprint != "" {
+		return fingerprint
+	}
+	return hashString(strings.TrimSpace(text))
+}
+
+// Quarantine holds a post for review. A post that was quarantined before is
+// left as it is and returned, so the caller can tell whether it was already
+// approved; created reports whether the post is new.
+func (s *QuarantineService) Quarantine(post *models.QuarantinedPost) (*models.QuarantinedPost, bool, error) {
+	if post.Fingerprint == "" {
+		post.Fingerprint = QuarantineFingerprint(post.URL, post.Text)
+	}
+
+	existing, err := s.getBy("fingerprint", post.Fingerprint)
+	if err == nil {
+		return existing, false, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, false, err
+	}
+
+	post.ID = uuid.New().String()
+	post.Status = models.QuarantineStatusPending
+	post.CreatedAt = time.Now()
+	if post.Reasons == nil {
+		post.Reasons = []string{}
+	}
+	reasonsJSON, err := json.Marshal(post.Reasons)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encode quarantine reasons: %w", err)
+	}
+
+	var postedAt interface{}
+	if post.PostedAt != nil {
+		postedAt = *post.PostedAt
+	}
+
+	placeholders := make([]string, 13)
+	for i := range placeholders {
+		placeholders[i] = s.getPlaceholder(i + 1)
+	}
+	query := fmt.Sprintf(`
+		INSERT INTO quarantined_posts (
+			id, source, fingerprint, url, group_name, channel_name, post_source, text,
+			posted_at, score, reasons, status, created_at
+		) VALUES (%s)
+	`, strings.Join(placeholders, ", "))
+
+	_, err = s.db.Exec(query,
+		post.ID, post.Source, post.Fingerprint, post.URL, post.GroupName, post.ChannelName,
+		post.PostSource, post.Text, postedAt, post.Score, string(reasonsJSON), post.Status,
+		post.CreatedAt,
+	)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to quarantine post: %w", err)
+	}
+	return post, true, nil
+}
+
+// ListPosts returns quarantined posts, newest first
+func (s *QuarantineService) ListPosts(filter models.QuarantinedPostFilter) (*models.QuarantinedPostResponse, error) {
+	// Set default pagination
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	whereClause := []string{}
+	args := []interface{}{}
+
+	if filter.Status != "" {
+		switch filter.Status {
+		case models.QuarantineStatusPending, models.QuarantineStatusApproved, models.QuarantineStatusRejected:
+		default:
+			return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidQuarantineFilter, filter.Status)
+		}
+		args = append(args, filter.Status)
+		whereClause = append(whereClause, fmt.Sprintf("status = %s", s.getPlaceholder(len(args))))
+	}
+	if filter.Source != "" {
+		args = append(args, filter.Source)
+		whereClause = append(whereClause, fmt.Sprintf("source = %s", s.getPlaceholder(len(args))))
+	}
+
+	where := ""
+	if len(whereClause) > 0 {
+		where = "WHERE " + strings.Join(whereClause, " AND ")
+	}
+
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM quarantined_posts %s", where)
+	if err := s.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to get quarantined post count: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM quarantined_posts %s
+		ORDER BY created_at DESC
+		LIMIT %s OFFSET %s
+	`, quarantinedPostColumns, where, s.getPlaceholder(len(args)+1), s.getPlaceholder(len(args)+2))
+
+	args = append(args, filter.Limit, filter.Offset)
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quarantined posts: %w", err)
+	}
+	defer rows.Close()
+
+	posts := []models.QuarantinedPost{}
+	for rows.Next() {
+		post, err := scanQuarantinedPost(rows)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, *post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return &models.QuarantinedPostResponse{
+		Posts: posts,
+		Total: total,
+		Page:  (filter.Offset / filter.Limit) + 1,
+		Limit: filter.Limit,
+	}, nil
+}
+
+// GetPost returns a quarantined post, or sql.ErrNoRows when it does not exist
+func (s *QuarantineService) GetPost(id string) (*models.QuarantinedPost, error) {
+	return s.getBy("id", id)
+}
+
+// Review approves or rejects a pending post. It returns sql.ErrNoRows when
+// the post does not exist and ErrPostReviewed when it was already reviewed.
+func (s *QuarantineService) Review(id, status, reviewer string) (*models.QuarantinedPost, error) {
+	query := fmt.Sprintf(`
+		UPDATE quarantined_posts SET status = %s, reviewed_by = %s, reviewed_at = %s
+		WHERE id = %s AND status = %s
+	`, s.getPlaceholder(1), s.getPlaceholder(2), s.getPlaceholder(3), s.getPlaceholder(4), s.getPlaceholder(5))
+
+	result, err := s.db.Exec(query, status, reviewer, time.Now(), id, models.QuarantineStatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to review quarantined post: %w", err)
+	}
+	if updated, err := result.RowsAffected(); err == nil && updated == 0 {
+		if _, err := s.GetPost(id); err != nil {
+			return nil, err
+		}
+		return nil, ErrPostReviewed
+	}
+	return s.GetPost(id)
+}
+
+// Reopen puts a reviewed post back in the queue, e.g. when its jobs could not
+// be saved after it was approved
+func (s *QuarantineService) Reopen(id string) error {
+	query := fmt.Sprintf(`
+		UPDATE quarantined_posts SET status = %s, reviewed_by = NULL, reviewed_at = NULL
+		WHERE id = %s
+	`, s.getPlaceholder(1), s.getPlaceholder(2))
+
+	if _, err := s.db.Exec(query, models.QuarantineStatusPending, id); err != nil {
+		return fmt.Errorf("failed to reopen quarantined post: %w", err)
+	}
+	return nil
+}
+
+// getBy returns the quarantined post whose column equals value
+func (s *QuarantineService) getBy(column, value string) (*models.QuarantinedPost, error) {
+	query := fmt.Sprintf("SELECT %s FROM quarantined_posts WHERE %s = %s",
+		quarantinedPostColumns, column, s.getPlaceholder(1))
+	return scanQuarantinedPost(s.db.QueryRow(query, value))
+}
+
+func scanQuarantinedPost(row rowScanner) (*models.QuarantinedPost, error) {
+	var post models.QuarantinedPost
+	var url, groupName, channelName, postSource, reasons, reviewedBy sql.NullString
+	var postedAt, reviewedAt sql.NullTime
+
+	err := row.Scan(
+		&post.ID, &post.Source, &post.Fingerprint, &url, &groupName, &channelName, &postSource,
+		&post.Text, &postedAt, &post.Score, &reasons, &post.Status, &reviewedBy, &reviewedAt,
+		&post.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan quarantined post: %w", err)
+	}
+
+	post.URL = url.String
+	post.GroupName = groupName.String
+	post.ChannelName = channelName.String
+	post.PostSource = postSource.String
+	post.ReviewedBy = reviewedBy.String
+	if postedAt.Valid {
+		post.PostedAt = &postedAt.Time
+	}
+	if reviewedAt.Valid {
+		post.ReviewedAt = &reviewedAt.Time
+	}
+	post.Reasons = []string{}
+	if reasons.Valid && reasons.String != "" {
+		if err := json.Unmarshal([]byte(reasons.String), &post.Reasons); err != nil {
+			return nil, fmt.Errorf("failed to decode quarantine reasons: %w", err)
+		}
+	}
+	return &post, nil
+}
@@ -0,0 +1,321 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/database"
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// maxStoredErrors caps how many error messages are kept per source run
+const maxStoredErrors = 50
+
+type ScrapeRunService struct {
+	db     *database.DB
+	driver string
+}
+
+func NewScrapeRunService(db *database.DB) *ScrapeRunService {
+	driver := "sqlite3" // default fallback
+	if db != nil {
+		driver = db.GetDriver()
+	}
+	return &ScrapeRunService{
+		db:     db,
+		driver: driver,
+	}
+}
+
+// getPlaceholder returns the appropriate placeholder for the database driver
+func (s *ScrapeRunService) getPlaceholder(index int) string {
+	if s.driver == "postgres" {
+		return fmt.Sprintf("$%d", index)
+	}
+	return "?"
+}
+
+// placeholders returns count comma separated placeholders starting at index 1
+func (s *ScrapeRunService) placeholders(count int) string {
+	parts := make([]string, count)
+	for i := range parts {
+		parts[i] = s.getPlaceholder(i + 1)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// StartRun records the start of a new scrape run
+func (s *ScrapeRunService) StartRun() (*models.ScrapeRun, error) {
+	run := &models.ScrapeRun{
+		ID:        uuid.New().String(),
+		Status:    models.ScrapeStatusRunning,
+		StartedAt: time.Now(),
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO scrape_runs (id, status, started_at)
+		VALUES (%s)
+	`, s.placeholders(3))
+
+	if _, err := s.db.Exec(query, run.ID, run.Status, run.StartedAt); err != nil {
+		return nil, fmt.Errorf("failed to create scrape run: %w", err)
+	}
+	return run, nil
+}
+
+// SaveRunSource stores the stats of a finished source within a run
+func (s *ScrapeRunService) SaveRunSource(source *models.ScrapeRunSource) error {
+	if source.ID == "" {
+		source.ID = uuid.New().String()
+	}
+
+	errors := source.Errors
+	if len(errors) > maxStoredErrors {
+		errors = errors[:maxStoredErrors]
+	}
+	if errors == nil {
+		errors = []string{}
+	}
+	errorsJSON, err := json.Marshal(errors)
+	if err != nil {
+		return fmt.Errorf("failed to encode scrape errors: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO scrape_run_sources (
+			id, run_id, source, status, started_at, finished_at,
+			posts_fetched, jobs_extracted, jobs_saved, duplicates_skipped,
+			error_count, blocked_fetches, posts_quarantined, crew_posts_saved, errors
+		) VALUES (%s)
+	`, s.placeholders(15))
+
+	_, err = s.db.Exec(query,
+		source.ID, source.RunID, source.Source, source.Status,
+		source.StartedAt, source.FinishedAt, source.PostsFetched,
+		source.JobsExtracted, source.JobsSaved, source.DuplicatesSkipped,
+		source.ErrorCount, source.BlockedFetches, source.PostsQuarantined, source.CrewPostsSaved,
+		string(errorsJSON),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save scrape run source: %w", err)
+	}
+	return nil
+}
+
+// FinishRun aggregates the source stats into the run and marks it finished
+func (s *ScrapeRunService) FinishRun(run *models.ScrapeRun) error {
+	now := time.Now()
+	run.FinishedAt = &now
+	run.PostsFetched, run.JobsExtracted, run.JobsSaved = 0, 0, 0
+	run.DuplicatesSkipped, run.ErrorCount, run.BlockedFetches = 0, 0, 0
+	run.PostsQuarantined, run.CrewPostsSaved = 0, 0
+
+	failed := 0
+	for _, source := range run.Sources {
+		run.PostsFetched += source.PostsFetched
+		run.JobsExtracted += source.JobsExtracted
+		run.JobsSaved += source.JobsSaved
+		run.DuplicatesSkipped += source.DuplicatesSkipped
+		run.ErrorCount += source.ErrorCount
+		run.BlockedFetches += source.BlockedFetches
+		run.PostsQuarantined += source.PostsQuarantined
+		run.CrewPostsSaved += source.CrewPostsSaved
+		if source.Status == models.ScrapeStatusFailed {
+			failed++
+		}
+	}
+
+	switch {
+	case len(run.Sources) > 0 && failed == len(run.Sources):
+		run.Status = models.ScrapeStatusFailed
+	case failed > 0 || run.ErrorCount > 0:
+		run.Status = models.ScrapeStatusPartial
+	default:
+		run.Status = models.ScrapeStatusSucceeded
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE scrape_runs
+		SET status = %s, finished_at = %s, posts_fetched = %s, jobs_extracted = %s,
+		    jobs_saved = %s, duplicates_skipped = %s, error_count = %s, blocked_fetches = %s,
+		    posts_quarantined = %s, crew_posts_saved = %s
+		WHERE id = %s
+	`, s.getPlaceholder(1), s.getPlaceholder(2), s.getPlaceholder(3), s.getPlaceholder(4),
+		s.getPlaceholder(5), s.getPlaceholder(6), s.getPlaceholder(7), s.getPlaceholder(8),
+		s.getPlaceholder(9), s.getPlaceholder(10), s.getPlaceholder(11))
+
+	_, err := s.db.Exec(query,
+		run.Status, run.FinishedAt, run.PostsFetched, run.JobsExtracted,
+		run.JobsSaved, run.DuplicatesSkipped, run.ErrorCount, run.BlockedFetches,
+		run.PostsQuarantined, run.CrewPostsSaved, run.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to finish scrape run: %w", err)
+	}
+	return nil
+}
+
+// ListRuns returns scrape runs, newest first
+func (s *ScrapeRunService) ListRuns(filter models.ScrapeRunFilter) (*models.ScrapeRunResponse, error) {
+	// Set default pagination
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	whereClause := []string{}
+	args := []interface{}{}
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		whereClause = append(whereClause, fmt.Sprintf("status = %s", s.getPlaceholder(len(args))))
+	}
+	if filter.Source != "" {
+		args = append(args, filter.Source)
+		whereClause = append(whereClause, fmt.Sprintf(
+			"id IN (SELECT run_id FROM scrape_run_sources WHERE source = %s)", s.getPlaceholder(len(args))))
+	}
+
+	where := ""
+	if len(whereClause) > 0 {
+		where = "WHERE " + strings.Join(whereClause, " AND ")
+	}
+
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM scrape_runs %s", where)
+	if err := s.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to get scrape run count: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, status, started_at, finished_at, posts_fetched, jobs_extracted,
+		       jobs_saved, duplicates_skipped, error_count, COALESCE(blocked_fetches, 0),
+		       COALESCE(posts_quarantined, 0), COALESCE(crew_posts_saved, 0)
+		FROM scrape_runs %s
+		ORDER BY started_at DESC
+		LIMIT %s OFFSET %s
+	`, where, s.getPlaceholder(len(args)+1), s.getPlaceholder(len(args)+2))
+
+	args = append(args, filter.Limit, filter.Offset)
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scrape runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []models.ScrapeRun{}
+	for rows.Next() {
+		run, err := scanScrapeRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, *run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return &models.ScrapeRunResponse{
+		Runs:  runs,
+		Total: total,
+		Page:  (filter.Offset / filter.Limit) + 1,
+		Limit: filter.Limit,
+	}, nil
+}
+
+// GetRun returns a scrape run together with its per-source stats
+func (s *ScrapeRunService) GetRun(runID string) (*models.ScrapeRun, error) {
+	query := fmt.Sprintf(`
+		SELECT id, status, started_at, finished_at, posts_fetched, jobs_extracted,
+		       jobs_saved, duplicates_skipped, error_count, COALESCE(blocked_fetches, 0),
+		       COALESCE(posts_quarantined, 0), COALESCE(crew_posts_saved, 0)
+		FROM scrape_runs WHERE id = %s
+	`, s.getPlaceholder(1))
+
+	run, err := scanScrapeRun(s.db.QueryRow(query, runID))
+	if err != nil {
+		return nil, err
+	}
+
+	sourcesQuery := fmt.Sprintf(`
+		SELECT id, run_id, source, status, started_at, finished_at, posts_fetched,
+		       jobs_extracted, jobs_saved, duplicates_skipped, error_count,
+		       COALESCE(blocked_fetches, 0), COALESCE(posts_quarantined, 0),
+		       COALESCE(crew_posts_saved, 0), errors
+		FROM scrape_run_sources WHERE run_id = %s
+		ORDER BY started_at
+	`, s.getPlaceholder(1))
+
+	rows, err := s.db.Query(sourcesQuery, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scrape run sources: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var source models.ScrapeRunSource
+		var finishedAt sql.NullTime
+		var errorsJSON sql.NullString
+
+		err := rows.Scan(
+			&source.ID, &source.RunID, &source.Source, &source.Status,
+			&source.StartedAt, &finishedAt, &source.PostsFetched,
+			&source.JobsExtracted, &source.JobsSaved, &source.DuplicatesSkipped,
+			&source.ErrorCount, &source.BlockedFetches, &source.PostsQuarantined,
+			&source.CrewPostsSaved, &errorsJSON,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scrape run source: %w", err)
+		}
+
+		if finishedAt.Valid {
+			source.FinishedAt = &finishedAt.Time
+		}
+		source.Errors = []string{}
+		if errorsJSON.Valid && errorsJSON.String != "" {
+			if err := json.Unmarshal([]byte(errorsJSON.String), &source.Errors); err != nil {
+				return nil, fmt.Errorf("failed to decode scrape errors: %w", err)
+			}
+		}
+
+		run.Sources = append(run.Sources, source)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return run, nil
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanScrapeRun(row rowScanner) (*models.ScrapeRun, error) {
+	run := &models.ScrapeRun{}
+	var finishedAt sql.NullTime
+
+	err := row.Scan(
+		&run.ID, &run.Status, &run.StartedAt, &finishedAt, &run.PostsFetched,
+		&run.JobsExtracted, &run.JobsSaved, &run.DuplicatesSkipped, &run.ErrorCount,
+		&run.BlockedFetches, &run.PostsQuarantined, &run.CrewPostsSaved,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan scrape run: %w", err)
+	}
+
+	if finishedAt.Valid {
+		run.FinishedAt = &finishedAt.Time
+	}
+	return run, nil
+}
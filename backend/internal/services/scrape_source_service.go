@@ -0,0 +1,464 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/database"
+	"github.com/Daniel-moen/CarverJobs-Mono/backend/internal/models"
+	"github.com/andybalholm/cascadia"
+	"github.com/google/uuid"
+)
+
+// ErrScrapeSourceExists is returned when adding a group or channel that is already configured
+var ErrScrapeSourceExists = errors.New("scrape source already exists")
+
+// ErrInvalidScrapeSource is returned when a scrape source fails validation
+var ErrInvalidScrapeSource = errors.New("invalid scrape source")
+
+// Bounds of the posts requested per group or channel
+const (
+	defaultSourceMaxPosts = 20
+	maxSourceMaxPosts     = 1000
+)
+
+// maxHTMLPages bounds the listing pages followed on an HTML job board
+const maxHTMLPages = 50
+
+// scrapeSourceTypes are the scraper sources configured through scrape_sources
+var scrapeSourceTypes = map[string]bool{
+	models.ScrapeSourceTypeFacebook: true,
+	models.ScrapeSourceTypeTelegram: true,
+	models.ScrapeSourceTypeHTML:     true,
+	models.ScrapeSourceTypeFeed:     true,
+}
+
+const scrapeSourceColumns = "id, type, identifier, display_name, enabled, max_posts, tags, config, created_at, updated_at"
+
+type ScrapeSourceService struct {
+	db     *database.DB
+	driver string
+}
+
+func NewScrapeSourceService(db *database.DB) *ScrapeSourceService {
+	driver := "sqlite3" // default fallback
+	if db != nil {
+		driver = db.GetDriver()
+	}
+	return &ScrapeSourceService{
+		db:     db,
+		driver: driver,
+	}
+}
+
+// getPlaceholder returns the appropriate placeholder for the database driver
+func (s *ScrapeSourceService) getPlaceholder(index int) string {
+	if s.driver == "postgres" {
+		return fmt.Sprintf("$%d", index)
+	}
+	return "?"
+}
+
+// ListSources returns the scrape sources matching the filter
+func (s *ScrapeSourceService) ListSources(filter models.ScrapeSourceFilter) ([]models.ScrapeSource, error) {
+	var conditions []string
+	var args []interface{}
+	argIndex := 1
+
+	if filter.Type != "" {
+		conditions = append(conditions, "type = "+s.getPlaceholder(argIndex))
+		args = append(args, filter.Type)
+		argIndex++
+	}
+	if filter.Enabled != nil {
+		conditions = append(conditions, "enabled = "+s.getPlaceholder(argIndex))
+		args = append(args, *filter.Enabled)
+		argIndex++
+	}
+
+	query := "SELECT " + scrapeSourceColumns + " FROM scrape_sources"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY type, display_name, identifier"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scrape sources: %w", err)
+	}
+	defer rows.Close()
+
+	sources := []models.ScrapeSource{}
+	for rows.Next() {
+		source, err := scanScrapeSource(rows)
+		if err != nil {
+			return nil, err
+		}
+		if filter.Tag != "" && !hasTag(source.Tags, filter.Tag) {
+			continue
+		}
+		sources = append(sources, *source)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return sources, nil
+}
+
+// ListEnabled returns the enabled scrape sources of a type
+func (s *ScrapeSourceService) ListEnabled(sourceType string) ([]models.ScrapeSource, error) {
+	enabled := true
+	return s.ListSources(models.ScrapeSourceFilter{Type: sourceType, Enabled: &enabled})
+}
+
+// GetSource returns a scrape source by ID
+func (s *ScrapeSourceService) GetSource(id string) (*models.ScrapeSource, error) {
+	query := fmt.Sprintf("SELECT %s FROM scrape_sources WHERE id = %s", scrapeSourceColumns, s.getPlaceholder(1))
+	return scanScrapeSource(s.db.QueryRow(query, id))
+}
+
+// CreateSource validates and adds a scrape source
+func (s *ScrapeSourceService) CreateSource(req models.CreateScrapeSourceRequest) (*models.ScrapeSource, error) {
+	sourceType := strings.ToLower(strings.TrimSpace(req.Type))
+	if !scrapeSourceTypes[sourceType] {
+		return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidScrapeSource, req.Type)
+	}
+
+	identifier, err := normalizeSourceIdentifier(sourceType, req.Identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	maxPosts := req.MaxPosts
+	if maxPosts == 0 {
+		maxPosts = defaultSourceMaxPosts
+	}
+	if err := validateMaxPosts(maxPosts); err != nil {
+		return nil, err
+	}
+	if err := validateSourceConfig(sourceType, req.Config); err != nil {
+		return nil, err
+	}
+
+	var exists bool
+	query := fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM scrape_sources WHERE type = %s AND identifier = %s)",
+		s.getPlaceholder(1), s.getPlaceholder(2))
+	if err := s.db.QueryRow(query, sourceType, identifier).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check scrape source existence: %w", err)
+	}
+	if exists {
+		return nil, ErrScrapeSourceExists
+	}
+
+	now := time.Now()
+	source := &models.ScrapeSource{
+		ID:          uuid.New().String(),
+		Type:        sourceType,
+		Identifier:  identifier,
+		DisplayName: strings.TrimSpace(req.DisplayName),
+		Enabled:     req.Enabled == nil || *req.Enabled,
+		MaxPosts:    maxPosts,
+		Tags:        normalizeTags(req.Tags),
+		Config:      req.Config,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if source.DisplayName == "" {
+		source.DisplayName = identifier
+	}
+
+	tags, err := json.Marshal(source.Tags)
+	if err != nil {
+		return nil, err
+	}
+	config, err := encodeSourceConfig(source.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf(`
+		INSERT INTO scrape_sources (%s)
+		VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+	`, scrapeSourceColumns,
+		s.getPlaceholder(1), s.getPlaceholder(2), s.getPlaceholder(3), s.getPlaceholder(4), s.getPlaceholder(5),
+		s.getPlaceholder(6), s.getPlaceholder(7), s.getPlaceholder(8), s.getPlaceholder(9), s.getPlaceholder(10))
+
+	_, err = s.db.Exec(query, source.ID, source.Type, source.Identifier, source.DisplayName, source.Enabled,
+		source.MaxPosts, string(tags), config, source.CreatedAt, source.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scrape source: %w", err)
+	}
+	return source, nil
+}
+
+// UpdateSource applies a partial update to a scrape source
+func (s *ScrapeSourceService) UpdateSource(id string, req models.UpdateScrapeSourceRequest) (*models.ScrapeSource, error) {
+	source, err := s.GetSource(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DisplayName != nil {
+		source.DisplayName = strings.TrimSpace(*req.DisplayName)
+	}
+	if req.Enabled != nil {
+		source.Enabled = *req.Enabled
+	}
+	if req.MaxPosts != nil {
+		if err := validateMaxPosts(*req.MaxPosts); err != nil {
+			return nil, err
+		}
+		source.MaxPosts = *req.MaxPosts
+	}
+	if req.Tags != nil {
+		source.Tags = normalizeTags(req.Tags)
+	}
+	if req.Config != nil {
+		if err := validateSourceConfig(source.Type, req.Config); err != nil {
+			return nil, err
+		}
+		source.Config = req.Config
+	}
+	source.UpdatedAt = time.Now()
+
+	tags, err := json.Marshal(source.Tags)
+	if err != nil {
+		return nil, err
+	}
+	config, err := encodeSourceConfig(source.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE scrape_sources SET display_name = %s, enabled = %s, max_posts = %s, tags = %s, config = %s, updated_at = %s
+		WHERE id = %s
+	`, s.getPlaceholder(1), s.getPlaceholder(2), s.getPlaceholder(3), s.getPlaceholder(4), s.getPlaceholder(5),
+		s.getPlaceholder(6), s.getPlaceholder(7))
+
+	_, err = s.db.Exec(query, source.DisplayName, source.Enabled, source.MaxPosts, string(tags), config, source.UpdatedAt, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update scrape source: %w", err)
+	}
+	return source, nil
+}
+
+// DeleteSource removes a scrape source
+func (s *ScrapeSourceService) DeleteSource(id string) error {
+	query := fmt.Sprintf("DELETE FROM scrape_sources WHERE id = %s", s.getPlaceholder(1))
+
+	result, err := s.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete scrape source: %w", err)
+	}
+	if deleted, err := result.RowsAffected(); err == nil && deleted == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func scanScrapeSource(row rowScanner) (*models.ScrapeSource, error) {
+	source := &models.ScrapeSource{}
+	var displayName, tags, config sql.NullString
+
+	err := row.Scan(&source.ID, &source.Type, &source.Identifier, &displayName, &source.Enabled,
+		&source.MaxPosts, &tags, &config, &source.CreatedAt, &source.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan scrape source: %w", err)
+	}
+
+	source.DisplayName = displayName.String
+	source.Tags = []string{}
+	if tags.Valid && tags.String != "" {
+		if err := json.Unmarshal([]byte(tags.String), &source.Tags); err != nil {
+			return nil, fmt.Errorf("failed to decode scrape source tags: %w", err)
+		}
+	}
+	if config.Valid && config.String != "" {
+		source.Config = &models.HTMLSiteConfig{}
+		if err := json.Unmarshal([]byte(config.String), source.Config); err != nil {
+			return nil, fmt.Errorf("failed to decode scrape source config: %w", err)
+		}
+	}
+	return source, nil
+}
+
+// encodeSourceConfig encodes a source config for the config column
+func encodeSourceConfig(config *models.HTMLSiteConfig) (interface{}, error) {
+	if config == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// normalizeSourceIdentifier validates a group URL or channel name and
+// returns it in the form the scraper actors expect
+func normalizeSourceIdentifier(sourceType, identifier string) (string, error) {
+	identifier = strings.TrimSpace(identifier)
+	if identifier == "" {
+		return "", fmt.Errorf("%w: identifier is required", ErrInvalidScrapeSource)
+	}
+
+	switch sourceType {
+	case models.ScrapeSourceTypeFacebook:
+		parsed, err := url.Parse(identifier)
+		if err != nil || !strings.HasSuffix(strings.ToLower(parsed.Hostname()), "facebook.com") {
+			return "", fmt.Errorf("%w: facebook identifier must be a group URL", ErrInvalidScrapeSource)
+		}
+		segments := strings.FieldsFunc(parsed.Path, func(r rune) bool { return r == '/' })
+		if len(segments) < 2 || segments[0] != "groups" {
+			return "", fmt.Errorf("%w: facebook identifier must be a group URL", ErrInvalidScrapeSource)
+		}
+		return "https://www.facebook.com/groups/" + segments[1] + "/", nil
+
+	case models.ScrapeSourceTypeTelegram:
+		channel := strings.TrimPrefix(identifier, "@")
+		for _, prefix := range []string{"https://", "http://", "www.", "t.me/", "telegram.me/", "s/"} {
+			channel = strings.TrimPrefix(channel, prefix)
+		}
+		channel = strings.Trim(channel, "/")
+		if channel == "" || strings.ContainsAny(channel, "/ ") {
+			return "", fmt.Errorf("%w: telegram identifier must be a channel username", ErrInvalidScrapeSource)
+		}
+		return strings.ToLower(channel), nil
+
+	case models.ScrapeSourceTypeHTML:
+		if !isHTTPURL(identifier) {
+			return "", fmt.Errorf("%w: html identifier must be the URL of the listing page", ErrInvalidScrapeSource)
+		}
+		return identifier, nil
+
+	case models.ScrapeSourceTypeFeed:
+		if !isHTTPURL(identifier) {
+			return "", fmt.Errorf("%w: feed identifier must be the URL of an RSS or Atom feed", ErrInvalidScrapeSource)
+		}
+		return identifier, nil
+	}
+	return identifier, nil
+}
+
+// validateSourceConfig checks the site config of a source. HTML job boards
+// need one to map their listings, other source types take none.
+func validateSourceConfig(sourceType string, config *models.HTMLSiteConfig) error {
+	if sourceType != models.ScrapeSourceTypeHTML {
+		if config != nil {
+			return fmt.Errorf("%w: config is only supported for html sources", ErrInvalidScrapeSource)
+		}
+		return nil
+	}
+	if config == nil {
+		return fmt.Errorf("%w: html sources require a config", ErrInvalidScrapeSource)
+	}
+
+	if strings.TrimSpace(config.ListSelector) == "" {
+		return fmt.Errorf("%w: config.list_selector is required", ErrInvalidScrapeSource)
+	}
+	if _, err := cascadia.ParseGroup(config.ListSelector); err != nil {
+		return fmt.Errorf("%w: config.list_selector: %v", ErrInvalidScrapeSource, err)
+	}
+
+	if title, ok := config.Fields[models.HTMLFieldTitle]; !ok || (title.Selector == "" && title.Attr == "" && title.Default == "") {
+		return fmt.Errorf("%w: config.fields.title is required", ErrInvalidScrapeSource)
+	}
+	if err := validateHTMLFields("config.fields", config.Fields); err != nil {
+		return err
+	}
+
+	if config.BaseURL != "" && !isHTTPURL(config.BaseURL) {
+		return fmt.Errorf("%w: config.base_url must be an absolute URL", ErrInvalidScrapeSource)
+	}
+
+	if config.NextPageSelector != "" {
+		if _, err := cascadia.ParseGroup(config.NextPageSelector); err != nil {
+			return fmt.Errorf("%w: config.next_page_selector: %v", ErrInvalidScrapeSource, err)
+		}
+	}
+	// Zero is the default when max_pages is omitted and follows the first page only
+	if config.MaxPages < 0 || config.MaxPages > maxHTMLPages {
+		return fmt.Errorf("%w: config.max_pages must be between 0 and %d, 0 following the first page only",
+			ErrInvalidScrapeSource, maxHTMLPages)
+	}
+
+	// A detail config without fields only reads the JobPosting data of the detail pages
+	if config.Detail != nil {
+		if _, ok := config.Detail.Fields[models.HTMLFieldURL]; ok {
+			return fmt.Errorf("%w: config.detail.fields.url is not supported", ErrInvalidScrapeSource)
+		}
+		if err := validateHTMLFields("config.detail.fields", config.Detail.Fields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateHTMLFields checks the field names and selectors of an HTML job board config
+func validateHTMLFields(path string, fields map[string]models.HTMLFieldSelector) error {
+	for name, field := range fields {
+		if !isHTMLField(name) {
+			return fmt.Errorf("%w: %s.%s is not a known field", ErrInvalidScrapeSource, path, name)
+		}
+		if field.Selector == "" {
+			continue
+		}
+		if _, err := cascadia.ParseGroup(field.Selector); err != nil {
+			return fmt.Errorf("%w: %s.%s.selector: %v", ErrInvalidScrapeSource, path, name, err)
+		}
+	}
+	return nil
+}
+
+func isHTMLField(name string) bool {
+	for _, field := range models.HTMLFields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isHTTPURL reports whether value is an absolute http or https URL
+func isHTTPURL(value string) bool {
+	parsed, err := url.Parse(value)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+func validateMaxPosts(maxPosts int) error {
+	if maxPosts < 1 || maxPosts > maxSourceMaxPosts {
+		return fmt.Errorf("%w: max_posts must be between 1 and %d", ErrInvalidScrapeSource, maxSourceMaxPosts)
+	}
+	return nil
+}
+
+func normalizeTags(tags []string) []string {
+	normalized := []string{}
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -177,6 +177,19 @@ func (s *UserService) UpdateUserProfile(userID string, req models.UpdateUserProf
 	return nil
 }
 
+// UpdateUserRole changes the role of a user, returning sql.ErrNoRows when the
+// user does not exist. The new role applies from the user's next login.
+func (s *UserService) UpdateUserRole(userID, role string) error {
+	result, err := s.db.Exec("UPDATE users SET role = $1, updated_at = $2 WHERE id = $3", role, time.Now(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to update user role: %w", err)
+	}
+	if updated, err := result.RowsAffected(); err == nil && updated == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
 func (s *UserService) LoginUser(req models.LoginRequest) (*models.LoginResponse, error) {
 	// Get user by email
 	user := &models.User{}
@@ -0,0 +1,8 @@
+-- Remove scrape run history tables
+DROP INDEX IF EXISTS idx_scrape_run_sources_source;
+DROP INDEX IF EXISTS idx_scrape_run_sources_run_id;
+DROP INDEX IF EXISTS idx_scrape_runs_status;
+DROP INDEX IF EXISTS idx_scrape_runs_started_at;
+
+DROP TABLE IF EXISTS scrape_run_sources;
+DROP TABLE IF EXISTS scrape_runs;
@@ -0,0 +1,33 @@
+-- Scrape run history so operators can see what each scrape did
+CREATE TABLE IF NOT EXISTS scrape_runs (
+    id TEXT PRIMARY KEY,
+    status TEXT NOT NULL DEFAULT 'running', -- running, succeeded, partial, failed
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP,
+    posts_fetched INTEGER DEFAULT 0,
+    jobs_extracted INTEGER DEFAULT 0,
+    jobs_saved INTEGER DEFAULT 0,
+    duplicates_skipped INTEGER DEFAULT 0,
+    error_count INTEGER DEFAULT 0
+);
+
+-- Per-source stats for each scrape run
+CREATE TABLE IF NOT EXISTS scrape_run_sources (
+    id TEXT PRIMARY KEY,
+    run_id TEXT NOT NULL REFERENCES scrape_runs(id) ON DELETE CASCADE,
+    source TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'running', -- running, succeeded, failed
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP,
+    posts_fetched INTEGER DEFAULT 0,
+    jobs_extracted INTEGER DEFAULT 0,
+    jobs_saved INTEGER DEFAULT 0,
+    duplicates_skipped INTEGER DEFAULT 0,
+    error_count INTEGER DEFAULT 0,
+    errors TEXT -- JSON array as string
+);
+
+CREATE INDEX IF NOT EXISTS idx_scrape_runs_started_at ON scrape_runs(started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_scrape_runs_status ON scrape_runs(status);
+CREATE INDEX IF NOT EXISTS idx_scrape_run_sources_run_id ON scrape_run_sources(run_id);
+CREATE INDEX IF NOT EXISTS idx_scrape_run_sources_source ON scrape_run_sources(source);
@@ -0,0 +1,10 @@
+-- Remove job fingerprints and source links
+DROP INDEX IF EXISTS idx_job_sources_url_fingerprint;
+DROP INDEX IF EXISTS idx_job_sources_job_id;
+DROP TABLE IF EXISTS job_sources;
+
+DROP INDEX IF EXISTS idx_jobs_content_fingerprint;
+DROP INDEX IF EXISTS idx_jobs_url_fingerprint;
+
+ALTER TABLE jobs DROP COLUMN content_fingerprint;
+ALTER TABLE jobs DROP COLUMN url_fingerprint;
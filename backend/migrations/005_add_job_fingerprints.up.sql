@@ -0,0 +1,20 @@
+-- Fingerprints used to deduplicate scraped jobs
+ALTER TABLE jobs ADD COLUMN url_fingerprint TEXT;
+ALTER TABLE jobs ADD COLUMN content_fingerprint TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_url_fingerprint ON jobs(url_fingerprint);
+CREATE INDEX IF NOT EXISTS idx_jobs_content_fingerprint ON jobs(content_fingerprint);
+
+-- Every place a job has been seen, linked to the canonical job
+CREATE TABLE IF NOT EXISTS job_sources (
+    id TEXT PRIMARY KEY,
+    job_id TEXT NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    source TEXT,
+    source_url TEXT,
+    url_fingerprint TEXT,
+    first_seen_at TIMESTAMP NOT NULL,
+    last_seen_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_sources_job_id ON job_sources(job_id);
+CREATE INDEX IF NOT EXISTS idx_job_sources_url_fingerprint ON job_sources(url_fingerprint);
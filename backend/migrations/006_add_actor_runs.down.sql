@@ -0,0 +1,3 @@
+-- Remove Apify actor run tracking
+DROP INDEX IF EXISTS idx_actor_runs_collected_at;
+DROP TABLE IF EXISTS actor_runs;
@@ -0,0 +1,12 @@
+-- Apify actor runs started by the scraper, so pending runs survive restarts
+CREATE TABLE IF NOT EXISTS actor_runs (
+    run_id TEXT PRIMARY KEY,
+    source TEXT NOT NULL,
+    actor_id TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'READY',
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP,
+    collected_at TIMESTAMP -- Set once the run's dataset has been processed
+);
+
+CREATE INDEX IF NOT EXISTS idx_actor_runs_collected_at ON actor_runs(collected_at);
@@ -0,0 +1,5 @@
+-- Remove scrape cursors and seen post tracking
+DROP INDEX IF EXISTS idx_seen_posts_seen_at;
+DROP INDEX IF EXISTS idx_seen_posts_source_channel;
+DROP TABLE IF EXISTS seen_posts;
+DROP TABLE IF EXISTS scrape_cursors;
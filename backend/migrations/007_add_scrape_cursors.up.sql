@@ -0,0 +1,20 @@
+-- High-water mark of every scraped channel, e.g. a Facebook group or Telegram channel
+CREATE TABLE IF NOT EXISTS scrape_cursors (
+    source TEXT NOT NULL,
+    channel TEXT NOT NULL,
+    last_post_at TIMESTAMP,
+    last_message_id INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (source, channel)
+);
+
+-- Post URLs already processed, so overlapping fetches skip them
+CREATE TABLE IF NOT EXISTS seen_posts (
+    url_fingerprint TEXT PRIMARY KEY,
+    source TEXT NOT NULL,
+    channel TEXT NOT NULL DEFAULT '',
+    seen_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_seen_posts_source_channel ON seen_posts(source, channel);
+CREATE INDEX IF NOT EXISTS idx_seen_posts_seen_at ON seen_posts(seen_at);
@@ -0,0 +1,3 @@
+-- Remove database managed scrape sources
+DROP INDEX IF EXISTS idx_scrape_sources_type_enabled;
+DROP TABLE IF EXISTS scrape_sources;
@@ -0,0 +1,38 @@
+-- Facebook groups and Telegram channels scraped through Apify
+CREATE TABLE IF NOT EXISTS scrape_sources (
+    id TEXT PRIMARY KEY,
+    type TEXT NOT NULL,        -- Scraper source name, e.g. facebook or telegram
+    identifier TEXT NOT NULL,  -- Facebook group URL or Telegram channel username
+    display_name TEXT,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    max_posts INTEGER NOT NULL DEFAULT 20,
+    tags TEXT,                 -- JSON array, e.g. ["mediterranean"]
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (type, identifier)
+);
+
+CREATE INDEX IF NOT EXISTS idx_scrape_sources_type_enabled ON scrape_sources(type, enabled);
+
+-- Seed with the groups and channels previously hardcoded in the yacht scraper
+INSERT INTO scrape_sources (id, type, identifier, display_name, tags) VALUES
+    ('4421e45c-0fd8-427b-95c5-7c223ce9de06', 'facebook', 'https://www.facebook.com/groups/239411889867327/', 'Yacht Crew Jobs', '[]'),
+    ('2e26053f-9bc8-454e-9c29-dcaec04e0797', 'facebook', 'https://www.facebook.com/groups/338532096967628/', 'Yacht Crew Jobs International', '["international"]'),
+    ('610661fc-60ed-498b-b2ea-082e581dcab9', 'facebook', 'https://www.facebook.com/groups/2258415597510901/', 'Yacht Crew Jobs Worldwide', '["international"]'),
+    ('2c78c94c-4dba-4af4-a93f-82a23ea68270', 'facebook', 'https://www.facebook.com/groups/1281653486137390/', 'Yacht Crew Jobs Mediterranean', '["mediterranean"]'),
+    ('7b136b22-4edb-40fa-aae0-863515703411', 'facebook', 'https://www.facebook.com/groups/crewhq/', 'Crew HQ', '[]'),
+    ('b7d1a004-4c6f-44d9-9fa1-214c8510357f', 'facebook', 'https://www.facebook.com/groups/252411372371043/', 'Yacht Crew Jobs Europe', '["europe"]'),
+    ('f1516958-7cbb-491c-9177-fd5b76aa9320', 'facebook', 'https://www.facebook.com/groups/1506610199573250/', 'Yacht Crew Jobs Caribbean', '["caribbean"]'),
+    ('6491c6d0-7f99-4723-8ac0-4ce30530ac7e', 'facebook', 'https://www.facebook.com/groups/147250563939706/', 'Seazone Yacht Crew & Jobs', '[]'),
+    ('6336ed83-8065-4a2c-9bf2-f64be7950c0c', 'facebook', 'https://www.facebook.com/groups/983255258859510/', 'Junior Yacht Crew', '["junior"]'),
+    ('22f68c99-1f6e-4d9d-8bf3-accc3be86a74', 'facebook', 'https://www.facebook.com/groups/396758057344877/', 'Yacht Stew Jobs', '["interior"]'),
+    ('f0206bcf-48b9-468a-9ff7-1c74a8325088', 'telegram', 'cvcrewcom', 'CV-CREW Maritime Jobs', '["maritime"]'),
+    ('e17a3dba-9bee-47d6-a404-766ba532ebd2', 'telegram', 'yachtjobs', 'Yacht Jobs', '[]'),
+    ('a8cb7930-27ce-4826-8d8d-c90935907b4f', 'telegram', 'superyachtjobs', 'Superyacht Jobs', '[]'),
+    ('025dd9d9-427b-4847-830f-69282644d678', 'telegram', 'megayachtjobs', 'Mega Yacht Jobs', '[]'),
+    ('6452c45e-6577-43ff-9fad-933977e623d4', 'telegram', 'motoryachtjobs', 'Motor Yacht Jobs', '[]'),
+    ('9ee817bc-055e-4e8a-9a23-1e3ce2abbdd8', 'telegram', 'yachtcrewnetwork', 'Yacht Crew Network', '[]'),
+    ('8e1f3d3f-655f-412c-bc1e-c6185c6f27fc', 'telegram', 'superyachtcrew', 'Superyacht Crew Network', '[]'),
+    ('ec09ca88-8d09-4d07-bf98-95f2057c547c', 'telegram', 'yachtingprofessionals', 'Yachting Professionals', '[]'),
+    ('a37799c6-4591-4453-8db4-67c169572f68', 'telegram', 'marinepedia', 'Marinepedia Jobs', '["maritime"]'),
+    ('ebde1993-5f4e-4056-bc23-598c59841df8', 'telegram', 'abroadjbs', 'Abroad Jobs', '["maritime"]');
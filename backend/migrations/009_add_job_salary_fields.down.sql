@@ -0,0 +1,8 @@
+-- Remove structured salary fields
+DROP INDEX IF EXISTS idx_jobs_salary_max;
+
+ALTER TABLE jobs DROP COLUMN salary_negotiable;
+ALTER TABLE jobs DROP COLUMN salary_period;
+ALTER TABLE jobs DROP COLUMN salary_currency;
+ALTER TABLE jobs DROP COLUMN salary_max;
+ALTER TABLE jobs DROP COLUMN salary_min;
@@ -0,0 +1,8 @@
+-- Structured salary parsed from the free-text salary
+ALTER TABLE jobs ADD COLUMN salary_min DOUBLE PRECISION;
+ALTER TABLE jobs ADD COLUMN salary_max DOUBLE PRECISION;
+ALTER TABLE jobs ADD COLUMN salary_currency TEXT;
+ALTER TABLE jobs ADD COLUMN salary_period TEXT;
+ALTER TABLE jobs ADD COLUMN salary_negotiable BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_salary_max ON jobs(salary_max);
@@ -0,0 +1,2 @@
+-- Remove exchange rates
+DROP TABLE IF EXISTS exchange_rates;
@@ -0,0 +1,17 @@
+-- Exchange rates used to compare salaries across currencies, as units of the
+-- currency per 1 EUR
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    currency TEXT PRIMARY KEY,
+    rate DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO exchange_rates (currency, rate) VALUES
+    ('EUR', 1.0),
+    ('USD', 1.08),
+    ('GBP', 0.85),
+    ('AUD', 1.65),
+    ('CAD', 1.47),
+    ('NZD', 1.79),
+    ('CHF', 0.96),
+    ('ZAR', 20.0);
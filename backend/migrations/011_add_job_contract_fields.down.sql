@@ -0,0 +1,9 @@
+-- Remove structured contract fields
+DROP INDEX IF EXISTS idx_jobs_season;
+DROP INDEX IF EXISTS idx_jobs_contract_kind;
+
+ALTER TABLE jobs DROP COLUMN season;
+ALTER TABLE jobs DROP COLUMN contract_months;
+ALTER TABLE jobs DROP COLUMN rotation_weeks_off;
+ALTER TABLE jobs DROP COLUMN rotation_weeks_on;
+ALTER TABLE jobs DROP COLUMN contract_kind;
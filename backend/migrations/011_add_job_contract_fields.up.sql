@@ -0,0 +1,9 @@
+-- Structured contract terms parsed from the free-text duration
+ALTER TABLE jobs ADD COLUMN contract_kind TEXT;
+ALTER TABLE jobs ADD COLUMN rotation_weeks_on INTEGER;
+ALTER TABLE jobs ADD COLUMN rotation_weeks_off INTEGER;
+ALTER TABLE jobs ADD COLUMN contract_months DOUBLE PRECISION;
+ALTER TABLE jobs ADD COLUMN season TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_contract_kind ON jobs(contract_kind);
+CREATE INDEX IF NOT EXISTS idx_jobs_season ON jobs(season);
@@ -0,0 +1,10 @@
+-- Remove normalized location fields
+DROP INDEX IF EXISTS idx_jobs_coordinates;
+DROP INDEX IF EXISTS idx_jobs_region;
+DROP INDEX IF EXISTS idx_jobs_country_code;
+
+ALTER TABLE jobs DROP COLUMN longitude;
+ALTER TABLE jobs DROP COLUMN latitude;
+ALTER TABLE jobs DROP COLUMN region;
+ALTER TABLE jobs DROP COLUMN country_code;
+ALTER TABLE jobs DROP COLUMN port;
@@ -0,0 +1,10 @@
+-- Normalized location resolved against the gazetteer
+ALTER TABLE jobs ADD COLUMN port TEXT;
+ALTER TABLE jobs ADD COLUMN country_code TEXT;
+ALTER TABLE jobs ADD COLUMN region TEXT;
+ALTER TABLE jobs ADD COLUMN latitude DOUBLE PRECISION;
+ALTER TABLE jobs ADD COLUMN longitude DOUBLE PRECISION;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_country_code ON jobs(country_code);
+CREATE INDEX IF NOT EXISTS idx_jobs_region ON jobs(region);
+CREATE INDEX IF NOT EXISTS idx_jobs_coordinates ON jobs(latitude, longitude);
@@ -0,0 +1,4 @@
+-- Remove per-site scraper configuration and the HTML boards using it
+DELETE FROM scrape_sources WHERE type = 'html';
+
+ALTER TABLE scrape_sources DROP COLUMN config;
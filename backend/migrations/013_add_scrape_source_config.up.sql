@@ -0,0 +1,12 @@
+-- Per-site scraper configuration, e.g. the CSS selectors of an HTML job board
+ALTER TABLE scrape_sources ADD COLUMN config TEXT; -- JSON object
+
+-- Seed with the placeholder boards previously hardcoded in the HTML scraper.
+-- They are disabled until pointed at a real board with matching selectors.
+INSERT INTO scrape_sources (id, type, identifier, display_name, enabled, tags, config) VALUES
+    ('0b5e3f7c-3f8e-4d1a-9a55-5b0f7d6c2a11', 'html', 'https://example-maritime-jobs.com', 'Maritime Jobs', FALSE, '["maritime"]',
+     '{"list_selector":".job-listing, .job-item, .job-card","fields":{"title":{"selector":".job-title, h2, h3"},"company":{"selector":".company, .employer"},"location":{"selector":".location, .job-location"},"description":{"selector":".description, .job-description"},"url":{"selector":"a","attr":"href"}}}'),
+    ('6d2c8a90-1e47-4b6f-8c3d-9f1a2b7e4c52', 'html', 'https://example-seaman-jobs.com', 'Seaman Jobs', FALSE, '["maritime"]',
+     '{"list_selector":".job-listing, .job-item, .job-card","fields":{"title":{"selector":".job-title, h2, h3"},"company":{"selector":".company, .employer"},"location":{"selector":".location, .job-location"},"description":{"selector":".description, .job-description"},"url":{"selector":"a","attr":"href"}}}'),
+    ('c4a7e1d2-8b3f-4e6a-a0d9-2e5f8c1b7a93', 'html', 'https://example-offshore-jobs.com', 'Offshore Jobs', FALSE, '["offshore"]',
+     '{"list_selector":".job-listing, .job-item, .job-card","fields":{"title":{"selector":".job-title, h2, h3"},"company":{"selector":".company, .employer"},"location":{"selector":".location, .job-location"},"description":{"selector":".description, .job-description"},"url":{"selector":"a","attr":"href"}}}');
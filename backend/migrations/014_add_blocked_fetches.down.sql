@@ -0,0 +1,3 @@
+-- Remove blocked fetch counts
+ALTER TABLE scrape_run_sources DROP COLUMN blocked_fetches;
+ALTER TABLE scrape_runs DROP COLUMN blocked_fetches;
@@ -0,0 +1,3 @@
+-- Fetches skipped because robots.txt disallows them
+ALTER TABLE scrape_runs ADD COLUMN blocked_fetches INTEGER DEFAULT 0;
+ALTER TABLE scrape_run_sources ADD COLUMN blocked_fetches INTEGER DEFAULT 0;
@@ -0,0 +1,2 @@
+-- Remove scraper source circuit breakers
+DROP TABLE IF EXISTS source_circuits;
@@ -0,0 +1,9 @@
+-- Circuit breaker of every scraper source, opened after consecutive failed runs
+CREATE TABLE IF NOT EXISTS source_circuits (
+    source TEXT PRIMARY KEY,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    open BOOLEAN NOT NULL DEFAULT FALSE,
+    opened_at TIMESTAMP,
+    last_error TEXT,
+    updated_at TIMESTAMP NOT NULL
+);
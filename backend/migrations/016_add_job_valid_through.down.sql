@@ -0,0 +1,2 @@
+-- Remove job application deadlines
+ALTER TABLE jobs DROP COLUMN valid_through;
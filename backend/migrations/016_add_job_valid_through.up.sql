@@ -0,0 +1,2 @@
+-- Application deadline of a job, e.g. the validThrough of a schema.org JobPosting
+ALTER TABLE jobs ADD COLUMN valid_through TIMESTAMP;
@@ -0,0 +1,3 @@
+-- Remove feed validators from scrape cursors
+ALTER TABLE scrape_cursors DROP COLUMN last_modified;
+ALTER TABLE scrape_cursors DROP COLUMN etag;
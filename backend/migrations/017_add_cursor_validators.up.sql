@@ -0,0 +1,3 @@
+-- HTTP validators of the last feed response, for conditional GETs
+ALTER TABLE scrape_cursors ADD COLUMN etag TEXT;
+ALTER TABLE scrape_cursors ADD COLUMN last_modified TEXT;
@@ -0,0 +1,3 @@
+-- Remove job languages
+DROP INDEX IF EXISTS idx_jobs_language;
+ALTER TABLE jobs DROP COLUMN language;
@@ -0,0 +1,3 @@
+-- Language a job was posted in, as an ISO 639-1 code such as "fr"
+ALTER TABLE jobs ADD COLUMN language VARCHAR(8);
+CREATE INDEX IF NOT EXISTS idx_jobs_language ON jobs(language);
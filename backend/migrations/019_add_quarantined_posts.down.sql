@@ -0,0 +1,6 @@
+-- Remove quarantined posts and their counts
+ALTER TABLE scrape_run_sources DROP COLUMN posts_quarantined;
+ALTER TABLE scrape_runs DROP COLUMN posts_quarantined;
+DROP INDEX IF EXISTS idx_quarantined_posts_status;
+DROP INDEX IF EXISTS idx_quarantined_posts_fingerprint;
+DROP TABLE IF EXISTS quarantined_posts;
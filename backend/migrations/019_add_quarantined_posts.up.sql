@@ -0,0 +1,24 @@
+-- Scraped posts held for admin review because they look like scams or spam
+CREATE TABLE IF NOT EXISTS quarantined_posts (
+    id TEXT PRIMARY KEY,
+    source TEXT NOT NULL,
+    fingerprint TEXT NOT NULL,
+    url TEXT,
+    group_name TEXT,
+    channel_name TEXT,
+    post_source TEXT,
+    text TEXT NOT NULL,
+    posted_at TIMESTAMP,
+    score REAL NOT NULL,
+    reasons TEXT,
+    status TEXT NOT NULL DEFAULT 'pending',
+    reviewed_by TEXT,
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_quarantined_posts_fingerprint ON quarantined_posts(fingerprint);
+CREATE INDEX IF NOT EXISTS idx_quarantined_posts_status ON quarantined_posts(status, created_at);
+
+ALTER TABLE scrape_runs ADD COLUMN posts_quarantined INTEGER DEFAULT 0;
+ALTER TABLE scrape_run_sources ADD COLUMN posts_quarantined INTEGER DEFAULT 0;
@@ -0,0 +1,8 @@
+-- Remove crew availability posts and their counts
+ALTER TABLE scrape_run_sources DROP COLUMN crew_posts_saved;
+ALTER TABLE scrape_runs DROP COLUMN crew_posts_saved;
+DROP INDEX IF EXISTS idx_crew_availability_posts_available_from;
+DROP INDEX IF EXISTS idx_crew_availability_posts_position;
+DROP INDEX IF EXISTS idx_crew_availability_posts_content_fingerprint;
+DROP INDEX IF EXISTS idx_crew_availability_posts_url_fingerprint;
+DROP TABLE IF EXISTS crew_availability_posts;
@@ -0,0 +1,29 @@
+-- Posts where crew advertise their availability, shown to employers and agencies
+CREATE TABLE IF NOT EXISTS crew_availability_posts (
+    id TEXT PRIMARY KEY,
+    source TEXT NOT NULL,
+    url TEXT,
+    url_fingerprint TEXT,
+    content_fingerprint TEXT,
+    group_name TEXT,
+    channel_name TEXT,
+    text TEXT NOT NULL,
+    position TEXT,
+    available_from TIMESTAMP,
+    availability TEXT,
+    certifications TEXT,
+    location TEXT,
+    country_code TEXT,
+    region TEXT,
+    language VARCHAR(8),
+    posted_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_crew_availability_posts_url_fingerprint ON crew_availability_posts(url_fingerprint);
+CREATE INDEX IF NOT EXISTS idx_crew_availability_posts_content_fingerprint ON crew_availability_posts(content_fingerprint);
+CREATE INDEX IF NOT EXISTS idx_crew_availability_posts_position ON crew_availability_posts(position);
+CREATE INDEX IF NOT EXISTS idx_crew_availability_posts_available_from ON crew_availability_posts(available_from);
+
+ALTER TABLE scrape_runs ADD COLUMN crew_posts_saved INTEGER DEFAULT 0;
+ALTER TABLE scrape_run_sources ADD COLUMN crew_posts_saved INTEGER DEFAULT 0;
@@ -0,0 +1,2 @@
+-- Remove mbox offsets from scrape cursors
+ALTER TABLE scrape_cursors DROP COLUMN byte_offset;
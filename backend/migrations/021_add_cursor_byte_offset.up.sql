@@ -0,0 +1,2 @@
+-- Bytes of an mbox file already read by the email source
+ALTER TABLE scrape_cursors ADD COLUMN byte_offset BIGINT NOT NULL DEFAULT 0;
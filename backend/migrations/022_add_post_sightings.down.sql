@@ -0,0 +1,3 @@
+-- Remove post sightings
+DROP INDEX IF EXISTS idx_post_sightings_seen_at;
+DROP TABLE IF EXISTS post_sightings;
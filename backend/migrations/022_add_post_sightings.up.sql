@@ -0,0 +1,9 @@
+-- Groups each post text was last seen in, to detect posts blasted across groups
+CREATE TABLE IF NOT EXISTS post_sightings (
+    fingerprint TEXT NOT NULL,
+    group_key TEXT NOT NULL,
+    seen_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (fingerprint, group_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_sightings_seen_at ON post_sightings(seen_at);